@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// AccountExport is the full copy of a user's data returned before deletion.
+type AccountExport struct {
+	ExportedAt  time.Time                 `json:"exported_at"`
+	Profile     UserProfileResponse       `json:"profile"`
+	Forms       []GetFormResponse         `json:"forms"`
+	Submissions []ExportSubmission        `json:"submissions"`
+	Drafts      []DraftSubmissionResponse `json:"drafts"`
+}
+
+type ExportSubmission struct {
+	ID          uint               `json:"id"`
+	FormID      uint               `json:"form_id"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty"`
+	Answers     []AnswerSubmission `json:"answers"`
+}
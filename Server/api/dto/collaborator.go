@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type InviteCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=results_viewer editor owner"`
+}
+
+type UpdateCollaboratorRequest struct {
+	Role string `json:"role" binding:"required,oneof=results_viewer editor owner"`
+}
+
+type TransferOwnershipRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// FormCollaboratorsResponse lists a form's team: its owner and the
+// collaborators they share it with.
+type FormCollaboratorsResponse struct {
+	FormID        uint                   `json:"form_id"`
+	Owner         CollaboratorResponse   `json:"owner"`
+	Collaborators []CollaboratorResponse `json:"collaborators"`
+}
+
+type CollaboratorResponse struct {
+	UserID      uint       `json:"user_id"`
+	Email       string     `json:"email"`
+	DisplayName string     `json:"display_name,omitempty"`
+	Role        string     `json:"role"`
+	AddedAt     *time.Time `json:"added_at,omitempty"`
+}
+
+// SharedFormResponse is a form someone else owns, with the user's role on it.
+type SharedFormResponse struct {
+	Role string          `json:"role"`
+	Form GetFormResponse `json:"form"`
+}
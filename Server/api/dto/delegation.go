@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+type DelegateVoteRequest struct {
+	DelegateEmail string `json:"delegate_email" binding:"required,email"`
+}
+
+// DelegationResponse shows the user's side of a form's delegations: who casts
+// their vote, and whose votes they cast.
+type DelegationResponse struct {
+	FormID   uint               `json:"form_id"`
+	Delegate *DelegateResponse  `json:"delegate"`
+	Proxies  []DelegateResponse `json:"proxies"`
+}
+
+type DelegateResponse struct {
+	UserID      uint      `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Voted       bool      `json:"voted"`
+	DelegatedAt time.Time `json:"delegated_at"`
+}
+
+// WeightAuditResponse lists every counted ballot of a form with the weight it
+// carried and the proxy who cast it, if any.
+type WeightAuditResponse struct {
+	FormID      uint                      `json:"form_id"`
+	TotalWeight int                       `json:"total_weight"`
+	Ballots     []WeightedBallotResponse  `json:"ballots"`
+	Delegations []DelegationAuditResponse `json:"delegations"`
+}
+
+type WeightedBallotResponse struct {
+	SubmissionID uint       `json:"submission_id"`
+	UserID       uint       `json:"user_id"`
+	Email        string     `json:"email"`
+	Weight       int        `json:"weight"`
+	CastByID     *uint      `json:"cast_by_id,omitempty"`
+	CastByEmail  string     `json:"cast_by_email,omitempty"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+}
+
+type DelegationAuditResponse struct {
+	DelegatorID    uint      `json:"delegator_id"`
+	DelegatorEmail string    `json:"delegator_email"`
+	DelegateID     uint      `json:"delegate_id"`
+	DelegateEmail  string    `json:"delegate_email"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -7,15 +7,28 @@ type UpdateFormRequest struct {
 	Description        *string                 `json:"description" binding:"omitempty"`
 	StartAt            *time.Time              `json:"startAt" binding:"omitempty"`
 	EndAt              *time.Time              `json:"endAt" binding:"omitempty"`
+	QuorumType         *string                 `json:"quorum_type" binding:"omitempty,oneof=none absolute percentage"`
+	Quorum             *int                    `json:"quorum" binding:"omitempty,min=1"`
+	NominationStartAt  *time.Time              `json:"nomination_start_at" binding:"omitempty"`
+	NominationEndAt    *time.Time              `json:"nomination_end_at" binding:"omitempty"`
 	Questions          []UpdateQuestionRequest `json:"questions" binding:"omitempty,dive"`
 	DeletedQuestionIds []uint                  `json:"deletedQuestionIds" binding:"omitempty"`
 }
 
 type UpdateQuestionRequest struct {
-	ID      *uint                 `json:"id" binding:"omitempty"`
-	Title   *string               `json:"title" binding:"omitempty"`
-	Type    *string               `json:"type" binding:"omitempty"`
-	Options []UpdateOptionRequest `json:"options" binding:"omitempty,dive"`
+	ID                  *uint                 `json:"id" binding:"omitempty"`
+	Title               *string               `json:"title" binding:"omitempty"`
+	Type                *string               `json:"type" binding:"omitempty"`
+	TallyMethod         *string               `json:"tally_method" binding:"omitempty,oneof=plurality approval score borda schulze stv"`
+	MaxScore            *int                  `json:"max_score" binding:"omitempty,min=1,max=100"`
+	Seats               *int                  `json:"seats" binding:"omitempty,min=1,max=100"`
+	PassThreshold       *string               `json:"pass_threshold" binding:"omitempty,oneof=none majority two_thirds three_quarters unanimous"`
+	CountAbstentions    *bool                 `json:"count_abstentions"`
+	TieBreak            *string               `json:"tie_break" binding:"omitempty,oneof=none owner earliest random"`
+	AcceptNominations   *bool                 `json:"accept_nominations"`
+	NominationSupport   *int                  `json:"nomination_support" binding:"omitempty,min=0,max=100"`
+	ModerateNominations *bool                 `json:"moderate_nominations"`
+	Options             []UpdateOptionRequest `json:"options" binding:"omitempty,dive"`
 }
 
 type UpdateOptionRequest struct {
@@ -24,30 +37,51 @@ type UpdateOptionRequest struct {
 }
 
 type GetFormResponse struct {
-	ID          uint                  `json:"id"`
-	Title       string                `json:"title"`
-	Description string                `json:"description"`
-	StartAt     time.Time             `json:"startAt"`
-	EndAt       time.Time             `json:"endAt"`
-	CreatedAt   time.Time             `json:"createdAt"`
-	UserID      uint                  `json:"user_id"`
-	Questions   []GetQuestionResponse `json:"questions"`
+	ID                  uint                  `json:"id"`
+	Title               string                `json:"title"`
+	Description         string                `json:"description"`
+	StartAt             time.Time             `json:"startAt"`
+	EndAt               time.Time             `json:"endAt"`
+	QuorumType          string                `json:"quorum_type,omitempty"`
+	Quorum              int                   `json:"quorum,omitempty"`
+	TieBreakSeedHash    string                `json:"tie_break_seed_hash,omitempty"`
+	RunoffOfID          *uint                 `json:"runoff_of_id,omitempty"`
+	RunoffOfQuestionID  *uint                 `json:"runoff_of_question_id,omitempty"`
+	NominationStartAt   *time.Time            `json:"nomination_start_at,omitempty"`
+	NominationEndAt     *time.Time            `json:"nomination_end_at,omitempty"`
+	ResultsVisibility   string                `json:"results_visibility,omitempty"`
+	ResultsEmbargoUntil *time.Time            `json:"results_embargo_until,omitempty"`
+	CreatedAt           time.Time             `json:"createdAt"`
+	UserID              uint                  `json:"user_id"`
+	Questions           []GetQuestionResponse `json:"questions"`
 }
 
 type GetPublicFormResponse struct {
-	ID          uint                  `json:"id"`
-	Title       string                `json:"title"`
-	Description string                `json:"description"`
-	StartAt     time.Time             `json:"startAt"`
-	EndAt       time.Time             `json:"endAt"`
-	Questions   []GetQuestionResponse `json:"questions"`
+	ID                uint                  `json:"id"`
+	Title             string                `json:"title"`
+	Description       string                `json:"description"`
+	StartAt           time.Time             `json:"startAt"`
+	EndAt             time.Time             `json:"endAt"`
+	TieBreakSeedHash  string                `json:"tie_break_seed_hash,omitempty"`
+	NominationStartAt *time.Time            `json:"nomination_start_at,omitempty"`
+	NominationEndAt   *time.Time            `json:"nomination_end_at,omitempty"`
+	Questions         []GetQuestionResponse `json:"questions"`
 }
 
 type GetQuestionResponse struct {
-	ID      uint                `json:"id"`
-	Title   string              `json:"title"`
-	Type    string              `json:"type"`
-	Options []GetOptionResponse `json:"options"`
+	ID                  uint                `json:"id"`
+	Title               string              `json:"title"`
+	Type                string              `json:"type"`
+	TallyMethod         string              `json:"tally_method,omitempty"`
+	MaxScore            int                 `json:"max_score,omitempty"`
+	Seats               int                 `json:"seats,omitempty"`
+	PassThreshold       string              `json:"pass_threshold,omitempty"`
+	CountAbstentions    bool                `json:"count_abstentions,omitempty"`
+	TieBreak            string              `json:"tie_break,omitempty"`
+	AcceptNominations   bool                `json:"accept_nominations,omitempty"`
+	NominationSupport   int                 `json:"nomination_support,omitempty"`
+	ModerateNominations bool                `json:"moderate_nominations,omitempty"`
+	Options             []GetOptionResponse `json:"options"`
 }
 
 type GetOptionResponse struct {
@@ -56,37 +90,69 @@ type GetOptionResponse struct {
 }
 
 type CreateFormRequest struct {
-	Title       string                  `json:"title" binding:"required,min=5,max=100"`
-	Description *string                 `json:"description"`
-	StartAt     *time.Time              `json:"startAt" binding:"omitempty"`
-	EndAt       *time.Time              `json:"endAt" binding:"omitempty"`
-	Questions   []CreateQuestionRequest `json:"questions" binding:"required,dive"`
+	Title               string                  `json:"title" binding:"required,min=5,max=100"`
+	Description         *string                 `json:"description"`
+	StartAt             *time.Time              `json:"startAt" binding:"omitempty"`
+	EndAt               *time.Time              `json:"endAt" binding:"omitempty"`
+	QuorumType          string                  `json:"quorum_type" binding:"omitempty,oneof=absolute percentage"`
+	Quorum              int                     `json:"quorum" binding:"omitempty,min=1"`
+	NominationStartAt   *time.Time              `json:"nomination_start_at" binding:"omitempty"`
+	NominationEndAt     *time.Time              `json:"nomination_end_at" binding:"omitempty"`
+	ResultsVisibility   string                  `json:"results_visibility" binding:"omitempty,oneof=owner voters closed link"`
+	ResultsEmbargoUntil *time.Time              `json:"results_embargo_until" binding:"omitempty"`
+	Questions           []CreateQuestionRequest `json:"questions" binding:"required,dive"`
 }
 
 type CreateQuestionRequest struct {
-	Title   string                `json:"title" binding:"required"`
-	Type    string                `json:"type" binding:"required,oneof=single_choice multiple_choice text"`
-	Options []CreateOptionRequest `json:"options,omitempty"`
+	Title               string                `json:"title" binding:"required"`
+	Type                string                `json:"type" binding:"required,oneof=single_choice multiple_choice text"`
+	TallyMethod         string                `json:"tally_method" binding:"omitempty,oneof=plurality approval score borda schulze stv"`
+	MaxScore            int                   `json:"max_score" binding:"omitempty,min=1,max=100"`
+	Seats               int                   `json:"seats" binding:"omitempty,min=1,max=100"`
+	PassThreshold       string                `json:"pass_threshold" binding:"omitempty,oneof=majority two_thirds three_quarters unanimous"`
+	CountAbstentions    bool                  `json:"count_abstentions"`
+	TieBreak            string                `json:"tie_break" binding:"omitempty,oneof=owner earliest random"`
+	AcceptNominations   bool                  `json:"accept_nominations"`
+	NominationSupport   int                   `json:"nomination_support" binding:"omitempty,min=0,max=100"`
+	ModerateNominations bool                  `json:"moderate_nominations"`
+	Options             []CreateOptionRequest `json:"options,omitempty"`
 }
 
 type CreateOptionRequest struct {
 	Title string `json:"title" binding:"required"`
 }
 
+// SubmitFormRequest is a ballot for the whole form. A proxy sets OnBehalfOf
+// to the user ID of the voter who delegated their vote to them.
 type SubmitFormRequest struct {
-	Answers []AnswerSubmission `json:"answers" binding:"required"`
+	Answers    []AnswerSubmission `json:"answers" binding:"required"`
+	OnBehalfOf *uint              `json:"on_behalf_of"`
 }
 
+// AnswerSubmission is a ballot for one question. Plurality and approval
+// questions take OptionIDs, ranked questions take Ranking (most preferred
+// first) and score questions take Scores. Abstain records an explicit
+// abstention on a choice question and takes no options.
 type AnswerSubmission struct {
-	QuestionID uint   `json:"question_id" binding:"required"`
-	OptionIDs  []uint `json:"option_ids,omitempty"`
-	Text       string `json:"text,omitempty"`
+	QuestionID uint          `json:"question_id" binding:"required"`
+	OptionIDs  []uint        `json:"option_ids,omitempty"`
+	Ranking    []uint        `json:"ranking,omitempty"`
+	Scores     []OptionScore `json:"scores,omitempty"`
+	Text       string        `json:"text,omitempty"`
+	Abstain    bool          `json:"abstain,omitempty"`
+}
+
+type OptionScore struct {
+	OptionID uint `json:"option_id"`
+	Score    int  `json:"score"`
 }
 
 type SubmitFormResponse struct {
 	ID          uint      `json:"id"`
 	FormID      uint      `json:"form_id"`
 	UserID      uint      `json:"user_id"`
+	Weight      int       `json:"weight"`
+	CastByID    *uint     `json:"cast_by_id,omitempty"`
 	CompletedAt time.Time `json:"completed_at"`
 }
 
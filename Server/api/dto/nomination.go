@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type NominateRequest struct {
+	QuestionID uint   `json:"question_id" binding:"required"`
+	Title      string `json:"title" binding:"required,max=100"`
+}
+
+type ModerateNominationRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+}
+
+// NominationResponse is a nomination with the support it has gathered.
+// Supported tells whether the requesting user seconded it, and OptionID is
+// the option it became once the nomination phase ended.
+type NominationResponse struct {
+	ID          uint      `json:"id"`
+	QuestionID  uint      `json:"question_id"`
+	Title       string    `json:"title"`
+	NominatedBy string    `json:"nominated_by"`
+	Status      string    `json:"status"`
+	Support     int       `json:"support"`
+	Supported   bool      `json:"supported"`
+	OptionID    *uint     `json:"option_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type FormNominationsResponse struct {
+	FormID            uint                 `json:"form_id"`
+	NominationStartAt *time.Time           `json:"nomination_start_at"`
+	NominationEndAt   *time.Time           `json:"nomination_end_at"`
+	Open              bool                 `json:"open"`
+	Converted         bool                 `json:"converted"`
+	Nominations       []NominationResponse `json:"nominations"`
+}
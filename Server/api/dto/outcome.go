@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// FormOutcomeResponse is the decision of a form. While voting is open it is
+// provisional and recomputed on every request; once the form closes it is
+// final and never changes.
+type FormOutcomeResponse struct {
+	FormID    uint                    `json:"form_id"`
+	Title     string                  `json:"title"`
+	Final     bool                    `json:"final"`
+	ClosedAt  *time.Time              `json:"closed_at,omitempty"`
+	DecidedAt *time.Time              `json:"decided_at,omitempty"`
+	Quorum    QuorumResponse          `json:"quorum"`
+	Motions   []MotionOutcomeResponse `json:"motions"`
+	// TieBreakSeedHash is the commitment to the seed of random tie-breaks;
+	// the seed itself is only revealed once voting has closed.
+	TieBreakSeed     string `json:"tie_break_seed,omitempty"`
+	TieBreakSeedHash string `json:"tie_break_seed_hash,omitempty"`
+}
+
+type QuorumResponse struct {
+	Type  string `json:"type,omitempty"`
+	Value int    `json:"value,omitempty"`
+	// Required is the number of participants needed.
+	Required int `json:"required"`
+	// EligibleVoters is the size of the voter roll, or 0 without one.
+	EligibleVoters int    `json:"eligible_voters"`
+	Participants   int    `json:"participants"`
+	Reached        bool   `json:"reached"`
+	Explanation    string `json:"explanation"`
+}
+
+// MotionOutcomeResponse says whether a question was decided. Questions with
+// a pass threshold pass when the leading option meets it; others pass when
+// they have a winner. Nothing passes without a quorum.
+type MotionOutcomeResponse struct {
+	QuestionID    uint   `json:"question_id"`
+	Title         string `json:"title"`
+	TallyMethod   string `json:"tally_method"`
+	PassThreshold string `json:"pass_threshold,omitempty"`
+	Status        string `json:"status"` // "passed", "failed" or "pending"
+	Winners       []uint `json:"winners"`
+	// Votes is the total of the leading option and Base the number of votes
+	// the threshold is measured against, both counted by voter weight.
+	Votes       int               `json:"votes"`
+	Base        int               `json:"base"`
+	Abstentions int               `json:"abstentions"`
+	Explanation string            `json:"explanation"`
+	TieBreak    *TieBreakResponse `json:"tie_break,omitempty"`
+}
+
+// TieBreakResponse records how a tie for first place was broken, with what
+// anyone needs to repeat it. Winner is 0 while the tie is unbroken.
+type TieBreakResponse struct {
+	Policy      string                 `json:"policy"`
+	Tied        []uint                 `json:"tied"`
+	Winner      uint                   `json:"winner,omitempty"`
+	Seed        string                 `json:"seed,omitempty"`
+	Draws       []TieBreakDrawResponse `json:"draws,omitempty"`
+	DecidedBy   uint                   `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time             `json:"decided_at,omitempty"`
+	Explanation string                 `json:"explanation"`
+}
+
+// TieBreakDrawResponse is the digest a random tie-break gave an option; the
+// smallest digest wins.
+type TieBreakDrawResponse struct {
+	OptionID uint   `json:"option_id"`
+	Digest   string `json:"digest"`
+}
+
+type DecideTieRequest struct {
+	OptionID uint `json:"option_id" binding:"required"`
+}
@@ -0,0 +1,151 @@
+package dto
+
+import "time"
+
+type FormResultsResponse struct {
+	FormID      uint                     `json:"form_id"`
+	Title       string                   `json:"title"`
+	Submissions int                      `json:"submissions"`
+	Questions   []QuestionResultResponse `json:"questions"`
+	// RunoffOf is the round this form is a runoff of, and Runoffs the
+	// runoffs started from this form.
+	RunoffOf *RoundLinkResponse  `json:"runoff_of,omitempty"`
+	Runoffs  []RoundLinkResponse `json:"runoffs,omitempty"`
+}
+
+// QuestionResultResponse is the count of one question. Weight and
+// AbstentionWeight are the votes its ballots and abstentions carry, and equal
+// Ballots and Abstentions when no voter is weighted.
+type QuestionResultResponse struct {
+	QuestionID       uint                  `json:"question_id"`
+	Title            string                `json:"title"`
+	TallyMethod      string                `json:"tally_method"`
+	Ballots          int                   `json:"ballots"`
+	Abstentions      int                   `json:"abstentions"`
+	Weight           int                   `json:"weight"`
+	AbstentionWeight int                   `json:"abstention_weight"`
+	Totals           []OptionTotalResponse `json:"totals"`
+	Winners          []uint                `json:"winners"`
+	Explanation      string                `json:"explanation"`
+	Pairwise         *PairwiseResponse     `json:"pairwise,omitempty"`
+	STV              *STVReportResponse    `json:"stv,omitempty"`
+}
+
+type OptionTotalResponse struct {
+	OptionID uint   `json:"option_id"`
+	Title    string `json:"title"`
+	Total    int    `json:"total"`
+}
+
+// PairwiseResponse is the head-to-head matrix of Condorcet methods. Row i,
+// column j of Preferences is the number of voters ranking Options[i] above
+// Options[j]; StrongestPaths holds the Schulze path strengths.
+type PairwiseResponse struct {
+	Options        []uint  `json:"options"`
+	Preferences    [][]int `json:"preferences"`
+	StrongestPaths [][]int `json:"strongest_paths"`
+}
+
+// STVReportResponse is the round-by-round count of an STV question. Vote
+// values are fractional once surpluses have been transferred.
+type STVReportResponse struct {
+	Seats        int                `json:"seats"`
+	ValidBallots int                `json:"valid_ballots"`
+	ValidVotes   int                `json:"valid_votes"`
+	Quota        float64            `json:"quota"`
+	Rounds       []STVRoundResponse `json:"rounds"`
+}
+
+type STVRoundResponse struct {
+	Round      int                   `json:"round"`
+	Votes      []OptionVotesResponse `json:"votes"`
+	Exhausted  float64               `json:"exhausted"`
+	Elected    []uint                `json:"elected"`
+	Eliminated []uint                `json:"eliminated"`
+	Transfers  []STVTransferResponse `json:"transfers"`
+	Note       string                `json:"note,omitempty"`
+}
+
+type OptionVotesResponse struct {
+	OptionID uint    `json:"option_id"`
+	Votes    float64 `json:"votes"`
+}
+
+type STVTransferResponse struct {
+	From   uint    `json:"from"`
+	Reason string  `json:"reason"`
+	Votes  float64 `json:"votes"`
+	Factor float64 `json:"factor"`
+}
+
+// BallotExportResponse lists the anonymous ballots of a form, one entry per
+// submission, so results can be recounted independently.
+type BallotExportResponse struct {
+	FormID    uint                     `json:"form_id"`
+	Title     string                   `json:"title"`
+	Questions []BallotQuestionResponse `json:"questions"`
+	Ballots   []ExportedBallot         `json:"ballots"`
+}
+
+type BallotQuestionResponse struct {
+	QuestionID  uint                `json:"question_id"`
+	Title       string              `json:"title"`
+	TallyMethod string              `json:"tally_method"`
+	MaxScore    int                 `json:"max_score,omitempty"`
+	Seats       int                 `json:"seats,omitempty"`
+	Options     []GetOptionResponse `json:"options"`
+}
+
+// ExportedBallot is one distinct ballot of an export. Count is how many
+// voters cast it and Weight their total weight.
+type ExportedBallot struct {
+	Count   int              `json:"count"`
+	Weight  int              `json:"weight"`
+	Answers []ExportedAnswer `json:"answers"`
+}
+
+type ExportedAnswer struct {
+	QuestionID uint          `json:"question_id"`
+	OptionIDs  []uint        `json:"option_ids,omitempty"`
+	Ranking    []uint        `json:"ranking,omitempty"`
+	Scores     []OptionScore `json:"scores,omitempty"`
+	Abstain    bool          `json:"abstain,omitempty"`
+}
+
+// LiveResultsResponse is one update of a form's live results. It always holds
+// the complete current count, so clients replace what they show. Sequence
+// grows with every update; EligibleVoters is the size of the voter roll, or 0
+// when the form has none.
+type LiveResultsResponse struct {
+	FormID         uint                     `json:"form_id"`
+	Sequence       uint64                   `json:"sequence"`
+	Submissions    int                      `json:"submissions"`
+	EligibleVoters int                      `json:"eligible_voters"`
+	Closed         bool                     `json:"closed"`
+	Questions      []QuestionResultResponse `json:"questions"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// StreamTokenResponse carries a token that opens a form's results stream when
+// sent in its token query parameter.
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SetResultsVisibilityRequest replaces who can see a form's results. Without
+// embargo_until the results are not embargoed.
+type SetResultsVisibilityRequest struct {
+	Visibility   string     `json:"visibility" binding:"required,oneof=owner voters closed link"`
+	EmbargoUntil *time.Time `json:"embargo_until"`
+}
+
+// ResultsVisibilityResponse is a form's results visibility policy. ShareToken
+// and SharePath are only set when the results are shared by link.
+type ResultsVisibilityResponse struct {
+	FormID       uint       `json:"form_id"`
+	Visibility   string     `json:"visibility"`
+	EmbargoUntil *time.Time `json:"embargo_until"`
+	ShareToken   string     `json:"share_token,omitempty"`
+	SharePath    string     `json:"share_path,omitempty"`
+}
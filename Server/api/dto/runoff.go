@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// CreateRunoffRequest starts a runoff for a question that failed. Options is
+// how many of the leading options go through, 2 by default.
+type CreateRunoffRequest struct {
+	QuestionID uint       `json:"question_id" binding:"required"`
+	Options    int        `json:"options" binding:"omitempty,min=2,max=100"`
+	Title      *string    `json:"title" binding:"omitempty,min=5,max=100"`
+	StartAt    *time.Time `json:"startAt"`
+	EndAt      time.Time  `json:"endAt" binding:"required"`
+}
+
+// RoundLinkResponse points at another round of the same vote.
+type RoundLinkResponse struct {
+	FormID     uint      `json:"form_id"`
+	QuestionID uint      `json:"question_id"`
+	Title      string    `json:"title"`
+	EndAt      time.Time `json:"endAt"`
+}
@@ -6,9 +6,10 @@ type CreateUserRequest struct {
 }
 
 type GetUserResponse struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID          uint   `json:"id"`
+	Email       string `json:"email"`
+	DisplayName string `json:"display_name"`
+	Role        string `json:"role"`
 }
 
 type LoginRequest struct {
@@ -24,12 +25,50 @@ type RefreshTokenResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
-type LogoutRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
-}
-
 type LoginResponse struct {
 	User         GetUserResponse `json:"user"`
 	AccessToken  string          `json:"access_token"`
-	RefreshToken string          `json:"refresh_token"`
+	RefreshToken string          `json:"refresh_token,omitempty"` // Omitted in cookie mode
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	RefreshToken    string `json:"refresh_token"` // Session to keep signed in, unless sent as a cookie
+}
+
+type UserProfileResponse struct {
+	ID           uint    `json:"id"`
+	Email        string  `json:"email"`
+	DisplayName  string  `json:"display_name"`
+	Timezone     string  `json:"timezone"`
+	Role         string  `json:"role"`
+	PendingEmail *string `json:"pending_email,omitempty"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	Timezone    *string `json:"timezone" binding:"omitempty"`
+}
+
+// Reauthentication confirms a sensitive account change. Accounts with a local
+// password send it; accounts created through single sign-on send the code
+// emailed by POST /users/me/reauthenticate instead.
+type Reauthentication struct {
+	Password          string `json:"password"`
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Reauthentication
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Reauthentication
+	Confirm bool `json:"confirm" binding:"required"`
 }
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// SetVoterRollRequest lists the voters of a form. Emails adds voters with a
+// weight of 1; Voters sets each voter's weight.
+type SetVoterRollRequest struct {
+	Emails []string               `json:"emails" binding:"max=10000,dive,email"`
+	Voters []WeightedVoterRequest `json:"voters" binding:"max=10000,dive"`
+}
+
+type WeightedVoterRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	Weight int    `json:"weight" binding:"omitempty,min=1,max=1000000"`
+}
+
+type VoterRollResponse struct {
+	FormID uint                    `json:"form_id"`
+	Voters []EligibleVoterResponse `json:"voters"`
+}
+
+type EligibleVoterResponse struct {
+	UserID      uint      `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Weight      int       `json:"weight"`
+	AddedAt     time.Time `json:"added_at"`
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+func (h *AccountHandler) GetProfile(c *gin.Context) {
+	user, err := h.accountService.GetProfile(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-profile", toUserProfileResponse(user))
+}
+
+func (h *AccountHandler) UpdateProfile(c *gin.Context) {
+	req := new(dto.UpdateProfileRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	user, err := h.accountService.UpdateProfile(c.Request.Context(), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "update-profile", toUserProfileResponse(user))
+}
+
+// RequestReauthentication emails a confirmation code to accounts created
+// through single sign-on, which have no password to confirm changes with.
+func (h *AccountHandler) RequestReauthentication(c *gin.Context) {
+	if err := h.accountService.RequestReauthentication(c.Request.Context(), c.GetUint("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "request-reauthentication", nil)
+}
+
+func (h *AccountHandler) RequestEmailChange(c *gin.Context) {
+	req := new(dto.ChangeEmailRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	if err := h.accountService.RequestEmailChange(c.Request.Context(), c.GetUint("user_id"), req.NewEmail, req.Reauthentication); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "request-email-change", gin.H{"pending_email": req.NewEmail})
+}
+
+func (h *AccountHandler) VerifyEmailChange(c *gin.Context) {
+	req := new(dto.VerifyEmailRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	user, err := h.accountService.VerifyEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "verify-email-change", toUserProfileResponse(user))
+}
+
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	export, err := h.accountService.ExportData(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="account-export.json"`)
+	schema.SendSuccess(c, "export-account", export)
+}
+
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	req := new(dto.DeleteAccountRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	if !req.Confirm {
+		schema.SendError(c, http.StatusBadRequest, "account deletion must be confirmed")
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(c.Request.Context(), c.GetUint("user_id"), req.Reauthentication); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "delete-account", nil)
+}
+
+func toUserProfileResponse(user *model.User) dto.UserProfileResponse {
+	return dto.UserProfileResponse{
+		ID:           user.ID,
+		Email:        user.Email,
+		DisplayName:  user.DisplayName,
+		Timezone:     user.Timezone,
+		Role:         string(user.Role),
+		PendingEmail: user.PendingEmail,
+	}
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+}
+
+func NewAdminHandler(adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	page, perPage := parsePagination(c)
+
+	users, total, err := h.adminService.SearchUsers(c.Request.Context(), c.Query("q"), page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := make([]dto.AdminUserResponse, len(users))
+	for i, user := range users {
+		resp[i] = toAdminUserResponse(user)
+	}
+
+	schema.SendSuccess(c, "admin-search-users", gin.H{
+		"data":     resp,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+func (h *AdminHandler) UpdateUserStatus(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	req := new(dto.UpdateUserStatusRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	user, err := h.adminService.SetUserDisabled(c.Request.Context(), auditActor(c), uint(userID), *req.Disabled)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "admin-update-user-status", toAdminUserResponse(user))
+}
+
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	req := new(dto.UpdateUserRoleRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	user, err := h.adminService.SetUserRole(c.Request.Context(), auditActor(c), uint(userID), model.UserRole(req.Role))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "admin-update-user-role", toAdminUserResponse(user))
+}
+
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.adminService.UnlockUser(c.Request.Context(), auditActor(c), uint(userID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "admin-unlock-user", gin.H{"user_id": userID})
+}
+
+func (h *AdminHandler) SearchForms(c *gin.Context) {
+	page, perPage := parsePagination(c)
+
+	forms, total, err := h.adminService.SearchForms(c.Request.Context(), c.Query("q"), page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := make([]dto.AdminFormResponse, len(forms))
+	for i, form := range forms {
+		resp[i] = dto.AdminFormResponse{
+			ID:          form.ID,
+			Title:       form.Title,
+			Description: form.Description,
+			StartAt:     form.StartAt,
+			EndAt:       form.EndAt,
+			CreatedAt:   form.CreatedAt,
+			UserID:      form.UserID,
+			OwnerEmail:  form.User.Email,
+		}
+	}
+
+	schema.SendSuccess(c, "admin-search-forms", gin.H{
+		"data":     resp,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+func (h *AdminHandler) GetForm(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	form, err := h.adminService.GetForm(c.Request.Context(), auditActor(c), uint(formID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := new(dto.GetFormResponse)
+	if err := copier.Copy(&resp, form); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "admin-get-form", resp)
+}
+
+func (h *AdminHandler) TakeDownForm(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.TakeDownFormRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	if err := h.adminService.TakeDownForm(c.Request.Context(), auditActor(c), uint(formID), req.Reason); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "admin-take-down-form", gin.H{"form_id": formID})
+}
+
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	page, perPage := parsePagination(c)
+
+	entries, total, err := h.adminService.GetAuditLogs(c.Request.Context(), c.Query("action"), page, perPage)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := make([]dto.AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		if err := copier.Copy(&resp[i], entry); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	schema.SendSuccess(c, "admin-audit-logs", gin.H{
+		"data":     resp,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+func auditActor(c *gin.Context) service.AuditActor {
+	return service.AuditActor{
+		UserID:    c.GetUint("user_id"),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+func toAdminUserResponse(user *model.User) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		Disabled:  user.Disabled,
+		CreatedAt: user.CreatedAt,
+	}
+}
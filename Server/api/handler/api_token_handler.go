@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type APITokenHandler struct {
+	apiTokenService service.APITokenService
+}
+
+func NewAPITokenHandler(apiTokenService service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{apiTokenService: apiTokenService}
+}
+
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	req := new(dto.CreateAPITokenRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	token, plain, err := h.apiTokenService.CreateToken(c.Request.Context(), userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "create-api-token", dto.CreateAPITokenResponse{
+		APITokenResponse: toAPITokenResponse(token),
+		Token:            plain,
+	})
+}
+
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	tokens, err := h.apiTokenService.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := make([]dto.APITokenResponse, len(tokens))
+	for i, token := range tokens {
+		resp[i] = toAPITokenResponse(token)
+	}
+
+	schema.SendSuccess(c, "list-api-tokens", resp)
+}
+
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid token ID")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := h.apiTokenService.RevokeToken(c.Request.Context(), userID, uint(tokenID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "revoke-api-token", gin.H{"id": tokenID})
+}
+
+func toAPITokenResponse(token *model.APIToken) dto.APITokenResponse {
+	return dto.APITokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     strings.Fields(token.Scopes),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+)
+
+// refreshCookiePath limits the refresh cookie to the auth endpoints.
+const refreshCookiePath = "/api/v1/auth"
+
+// oidcStateCookie carries a single sign-on login from the redirect to the
+// provider to its callback, and is only sent to the SSO endpoints.
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/v1/auth/oidc"
+)
+
+// authCookies writes and reads the refresh token and CSRF cookies used when
+// cookie mode is enabled.
+type authCookies struct {
+	cfg config.AuthCookieConfig
+}
+
+func (a authCookies) enabled() bool {
+	return a.cfg.Enabled
+}
+
+// set stores the refresh token in an HttpOnly cookie and issues a new CSRF
+// token the SPA can read and echo back in the X-CSRF-Token header.
+func (a authCookies) set(c *gin.Context, refreshToken string) error {
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	maxAge := int(auth.RefreshTokenExpiration / time.Second)
+	http.SetCookie(c.Writer, a.cookie(a.cfg.RefreshCookieName, refreshToken, refreshCookiePath, true, maxAge))
+	http.SetCookie(c.Writer, a.cookie(a.cfg.CSRFCookieName, csrfToken, "/", false, maxAge))
+	return nil
+}
+
+func (a authCookies) clear(c *gin.Context) {
+	http.SetCookie(c.Writer, a.cookie(a.cfg.RefreshCookieName, "", refreshCookiePath, true, -1))
+	http.SetCookie(c.Writer, a.cookie(a.cfg.CSRFCookieName, "", "/", false, -1))
+}
+
+// setOIDCState stores the login state of a single sign-on login. The provider
+// redirects back with a top-level navigation from its own site, so the cookie
+// is Lax whatever the configured SameSite mode.
+func (a authCookies) setOIDCState(c *gin.Context, loginState string) {
+	cookie := a.cookie(oidcStateCookie, loginState, oidcStateCookiePath, true, int(auth.OIDCStateExpiration/time.Second))
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(c.Writer, cookie)
+}
+
+// oidcState returns the login state cookie and clears it, so each login
+// state is used for one callback only.
+func (a authCookies) oidcState(c *gin.Context) string {
+	loginState, _ := c.Cookie(oidcStateCookie)
+	cookie := a.cookie(oidcStateCookie, "", oidcStateCookiePath, true, -1)
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(c.Writer, cookie)
+	return loginState
+}
+
+// refreshToken returns the refresh token from the cookie, if present.
+func (a authCookies) refreshToken(c *gin.Context) (string, bool) {
+	if !a.cfg.Enabled {
+		return "", false
+	}
+	token, err := c.Cookie(a.cfg.RefreshCookieName)
+	if err != nil || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func (a authCookies) cookie(name, value, path string, httpOnly bool, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   a.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   a.cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: parseSameSite(a.cfg.SameSite),
+	}
+}
+
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -1,22 +1,25 @@
 package handler
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/config"
 	"github.com/luneto10/voting-system/internal/schema"
 	"github.com/luneto10/voting-system/internal/service"
 )
 
 type AuthHandler struct {
 	authService service.AuthService
+	cookies     authCookies
 }
 
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService service.AuthService, cookieCfg config.AuthCookieConfig) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		cookies:     authCookies{cfg: cookieCfg},
+	}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -27,19 +30,19 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user := new(model.User)
 	if err := copier.Copy(user, req); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	created, err := h.authService.Register(user)
+	created, err := h.authService.Register(c.Request.Context(), user)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.GetUserResponse)
 	if err := copier.Copy(resp, created); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -52,15 +55,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, jwtToken, refreshToken, err := h.authService.Login(req.Email, req.Password)
+	user, jwtToken, refreshToken, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		schema.SendError(c, http.StatusUnauthorized, "Invalid credentials")
+		c.Error(err)
 		return
 	}
 
 	userResp := new(dto.GetUserResponse)
 	if err := copier.Copy(userResp, user); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -70,18 +73,27 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		RefreshToken: refreshToken,
 	}
 
+	// In cookie mode the refresh token never reaches JavaScript
+	if h.cookies.enabled() {
+		if err := h.cookies.set(c, refreshToken); err != nil {
+			c.Error(err)
+			return
+		}
+		resp.RefreshToken = ""
+	}
+
 	schema.SendSuccess(c, "login", resp)
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	req := new(dto.RefreshTokenRequest)
-	if ok := bindAndValidate(c, &req); !ok {
+	refreshToken, ok := h.refreshTokenFromRequest(c)
+	if !ok {
 		return
 	}
 
-	newJWT, err := h.authService.RefreshToken(req.RefreshToken)
+	newJWT, err := h.authService.RefreshToken(c.Request.Context(), refreshToken)
 	if err != nil {
-		schema.SendError(c, http.StatusUnauthorized, "Invalid refresh token")
+		c.Error(err)
 		return
 	}
 
@@ -93,15 +105,64 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-	req := new(dto.LogoutRequest)
-	if ok := bindAndValidate(c, &req); !ok {
+	refreshToken, ok := h.refreshTokenFromRequest(c)
+	if !ok {
 		return
 	}
 
-	if err := h.authService.Logout(req.RefreshToken); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, "Failed to logout")
+	if err := h.authService.Logout(c.Request.Context(), refreshToken); err != nil {
+		c.Error(err)
 		return
 	}
 
+	if h.cookies.enabled() {
+		h.cookies.clear(c)
+	}
+
 	schema.SendSuccess(c, "logout", nil)
 }
+
+// refreshTokenFromRequest prefers the refresh token cookie and falls back to
+// the JSON body, which keeps non-browser clients working in cookie mode.
+func (h *AuthHandler) refreshTokenFromRequest(c *gin.Context) (string, bool) {
+	if token, ok := h.cookies.refreshToken(c); ok {
+		return token, true
+	}
+
+	req := new(dto.RefreshTokenRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return "", false
+	}
+	return req.RefreshToken, true
+}
+
+// Unlock clears the failed login lockout of the authenticated user's account,
+// e.g. after an attacker locked it while the owner was signed in elsewhere.
+func (h *AuthHandler) Unlock(c *gin.Context) {
+	if err := h.authService.UnlockAccount(c.Request.Context(), c.GetUint("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "unlock", nil)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	req := new(dto.ChangePasswordRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	currentRefreshToken := req.RefreshToken
+	if token, ok := h.cookies.refreshToken(c); ok {
+		currentRefreshToken = token
+	}
+
+	err := h.authService.ChangePassword(c.Request.Context(), c.GetUint("user_id"), req.CurrentPassword, req.NewPassword, currentRefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "change-password", nil)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type CollaboratorHandler struct {
+	collaboratorService service.CollaboratorService
+}
+
+func NewCollaboratorHandler(collaboratorService service.CollaboratorService) *CollaboratorHandler {
+	return &CollaboratorHandler{collaboratorService: collaboratorService}
+}
+
+func (h *CollaboratorHandler) GetCollaborators(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	collaborators, err := h.collaboratorService.GetCollaborators(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-collaborators", collaborators)
+}
+
+func (h *CollaboratorHandler) InviteCollaborator(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.InviteCollaboratorRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorService.InviteCollaborator(c.Request.Context(), uint(formID), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "invite-collaborator", collaborators)
+}
+
+func (h *CollaboratorHandler) UpdateCollaborator(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+	collaboratorID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	req := new(dto.UpdateCollaboratorRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorService.UpdateCollaborator(c.Request.Context(), uint(formID), c.GetUint("user_id"), uint(collaboratorID), model.CollaboratorRole(req.Role))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "update-collaborator", collaborators)
+}
+
+// RemoveCollaborator takes a collaborator off the form. Collaborators can
+// also use it to leave a form.
+func (h *CollaboratorHandler) RemoveCollaborator(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+	collaboratorID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.collaboratorService.RemoveCollaborator(c.Request.Context(), uint(formID), c.GetUint("user_id"), uint(collaboratorID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "remove-collaborator", nil)
+}
+
+func (h *CollaboratorHandler) TransferOwnership(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.TransferOwnershipRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorService.TransferOwnership(c.Request.Context(), uint(formID), c.GetUint("user_id"), req.Email)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "transfer-ownership", collaborators)
+}
+
+// GetSharedForms lists the forms other users share with the current user.
+func (h *CollaboratorHandler) GetSharedForms(c *gin.Context) {
+	shared, err := h.collaboratorService.GetSharedForms(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := make([]dto.SharedFormResponse, len(shared))
+	for i, collaborator := range shared {
+		resp[i].Role = string(collaborator.Role)
+		if err := copier.Copy(&resp[i].Form, &collaborator.Form); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	schema.SendSuccess(c, "get-shared-forms", resp)
+}
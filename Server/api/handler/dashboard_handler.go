@@ -24,15 +24,15 @@ func NewDashboardHandler(dashboardService service.DashboardService) *DashboardHa
 func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
-	dashboardData, err := h.dashboardService.GetDashboardData(userID)
+	dashboardData, err := h.dashboardService.GetDashboardData(c.Request.Context(), userID)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	response := dto.DashboardResponse{}
 	if err := copier.Copy(&response, dashboardData); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -55,9 +55,9 @@ func (h *DashboardHandler) UpdateFormStatus(c *gin.Context) {
 		return
 	}
 
-	err = h.dashboardService.UpdateUserFormStatus(userID, uint(formID), status)
+	err = h.dashboardService.UpdateUserFormStatus(c.Request.Context(), userID, uint(formID), status)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -75,9 +75,9 @@ func (h *DashboardHandler) DeleteFormParticipation(c *gin.Context) {
 		return
 	}
 
-	err = h.dashboardService.DeleteFormParticipation(userID, uint(formID))
+	err = h.dashboardService.DeleteFormParticipation(c.Request.Context(), userID, uint(formID))
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -102,9 +102,9 @@ func (h *DashboardHandler) GetUserActivities(c *gin.Context) {
 		perPageNum = 10
 	}
 
-	activities, total, err := h.dashboardService.GetUserActivities(userID, status, pageNum, perPageNum)
+	activities, total, err := h.dashboardService.GetUserActivities(c.Request.Context(), userID, status, pageNum, perPageNum)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, "failed to get user activities")
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type DelegationHandler struct {
+	delegationService service.DelegationService
+}
+
+func NewDelegationHandler(delegationService service.DelegationService) *DelegationHandler {
+	return &DelegationHandler{delegationService: delegationService}
+}
+
+func (h *DelegationHandler) GetDelegation(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	delegation, err := h.delegationService.GetDelegation(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-delegation", delegation)
+}
+
+func (h *DelegationHandler) Delegate(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.DelegateVoteRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	delegation, err := h.delegationService.Delegate(c.Request.Context(), uint(formID), c.GetUint("user_id"), req.DelegateEmail)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "delegate-vote", delegation)
+}
+
+func (h *DelegationHandler) RevokeDelegation(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	if err := h.delegationService.RevokeDelegation(c.Request.Context(), uint(formID), c.GetUint("user_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "revoke-delegation", nil)
+}
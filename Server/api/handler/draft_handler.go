@@ -35,20 +35,20 @@ func (h *DraftHandler) SaveDraft(c *gin.Context) {
 	}
 
 	// Update form status to in_progress when saving draft
-	if err := h.dashboardService.UpdateUserFormStatus(userID, req.FormID, "in_progress"); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+	if err := h.dashboardService.UpdateUserFormStatus(c.Request.Context(), userID, req.FormID, "in_progress"); err != nil {
+		c.Error(err)
 		return
 	}
 
-	draft, err := h.draftService.SaveDraft(userID, req.FormID, req)
+	draft, err := h.draftService.SaveDraft(c.Request.Context(), userID, req.FormID, req)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	var answers []dto.AnswerSubmission
 	if err := json.Unmarshal(draft.Answers, &answers); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, "failed to unmarshal answers")
+		c.Error(err)
 		return
 	}
 
@@ -76,7 +76,7 @@ func (h *DraftHandler) GetDraft(c *gin.Context) {
 		return
 	}
 
-	draft, err := h.draftService.GetDraft(userID, uint(formID))
+	draft, err := h.draftService.GetDraft(c.Request.Context(), userID, uint(formID))
 	if err != nil {
 		schema.SendError(c, http.StatusNotFound, "draft not found")
 		return
@@ -95,14 +95,14 @@ func (h *DraftHandler) DeleteDraft(c *gin.Context) {
 		return
 	}
 
-	err = h.draftService.DeleteDraft(userID, uint(formID))
+	err = h.draftService.DeleteDraft(c.Request.Context(), userID, uint(formID))
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
-	if err := h.dashboardService.UpdateUserFormStatus(userID, uint(formID), "available"); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+	if err := h.dashboardService.UpdateUserFormStatus(c.Request.Context(), userID, uint(formID), "available"); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -45,22 +45,22 @@ func (h *FormHandler) CreateForm(c *gin.Context) {
 
 	form := new(model.Form)
 	if err := copier.Copy(form, &req); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	// Set the user ID from the authenticated user
 	form.UserID = c.GetUint("user_id")
 
-	created, err := h.formService.CreateForm(form)
+	created, err := h.formService.CreateForm(c.Request.Context(), form)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.GetFormResponse)
 	if err := copier.Copy(&resp, created); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -77,26 +77,21 @@ func (h *FormHandler) GetForm(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 
-	// Check if user is the owner of the form
-	isOwner, err := h.formAuthService.IsFormOwner(userID, uint(id))
-	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-	if !isOwner {
-		schema.SendError(c, http.StatusForbidden, "you can only access forms that you own")
+	// Only the form's owner and collaborators see the whole form
+	if err := h.formAuthService.CanViewForm(c.Request.Context(), userID, uint(id)); err != nil {
+		c.Error(err)
 		return
 	}
 
-	form, err := h.formService.GetForm(uint(id))
+	form, err := h.formService.GetForm(c.Request.Context(), uint(id))
 	if err != nil {
-		schema.SendError(c, http.StatusNotFound, err.Error())
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.GetFormResponse)
 	if err := copier.Copy(&resp, form); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -111,15 +106,15 @@ func (h *FormHandler) GetPublicForm(c *gin.Context) {
 		return
 	}
 
-	form, err := h.formService.GetForm(uint(id))
+	form, err := h.formService.GetForm(c.Request.Context(), uint(id))
 	if err != nil {
-		schema.SendError(c, http.StatusNotFound, err.Error())
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.GetPublicFormResponse)
 	if err := copier.Copy(&resp, form); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -140,22 +135,15 @@ func (h *FormHandler) UpdateForm(c *gin.Context) {
 	}
 
 	userID := c.GetUint("user_id")
-	updated, err := h.formService.UpdateForm(uint(id), userID, req)
+	updated, err := h.formService.UpdateForm(c.Request.Context(), uint(id), userID, req)
 	if err != nil {
-		switch err {
-		case service.ErrFormNotFound:
-			schema.SendError(c, http.StatusNotFound, err.Error())
-		case service.ErrNotFormOwner:
-			schema.SendError(c, http.StatusForbidden, err.Error())
-		default:
-			schema.SendError(c, http.StatusInternalServerError, err.Error())
-		}
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.GetFormResponse)
 	if err := copier.Copy(&resp, updated); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -171,15 +159,8 @@ func (h *FormHandler) DeleteForm(c *gin.Context) {
 	}
 
 	userID := c.GetUint("user_id")
-	if err := h.formService.DeleteForm(uint(id), userID); err != nil {
-		switch err {
-		case service.ErrFormNotFound:
-			schema.SendError(c, http.StatusNotFound, err.Error())
-		case service.ErrNotFormOwner:
-			schema.SendError(c, http.StatusForbidden, err.Error())
-		default:
-			schema.SendError(c, http.StatusInternalServerError, err.Error())
-		}
+	if err := h.formService.DeleteForm(c.Request.Context(), uint(id), userID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -188,16 +169,16 @@ func (h *FormHandler) DeleteForm(c *gin.Context) {
 
 func (h *FormHandler) GetUserForms(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	forms, err := h.formService.GetFormsByUserID(userID)
+	forms, err := h.formService.GetFormsByUserID(c.Request.Context(), userID)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
 	resp := make([]dto.GetFormResponse, len(forms))
 	for i, form := range forms {
 		if err := copier.Copy(&resp[i], form); err != nil {
-			schema.SendError(c, http.StatusInternalServerError, err.Error())
+			c.Error(err)
 			return
 		}
 	}
@@ -219,24 +200,20 @@ func (h *FormHandler) SubmitForm(c *gin.Context) {
 		return
 	}
 
-	submission, err := h.formSubmissionService.SubmitForm(uint(formID), userID, req.Answers)
+	var submission *model.Submission
+	if req.OnBehalfOf != nil {
+		submission, err = h.formSubmissionService.SubmitFormOnBehalf(c.Request.Context(), uint(formID), userID, *req.OnBehalfOf, req.Answers)
+	} else {
+		submission, err = h.formSubmissionService.SubmitForm(c.Request.Context(), uint(formID), userID, req.Answers)
+	}
 	if err != nil {
-		switch err {
-		case service.ErrFormNotFound:
-			schema.SendError(c, http.StatusNotFound, err.Error())
-		case service.ErrSubmissionAlreadyExists:
-			schema.SendError(c, http.StatusBadRequest, err.Error())
-		case service.ErrCannotSubmitOwnForm:
-			schema.SendError(c, http.StatusForbidden, err.Error())
-		default:
-			schema.SendError(c, http.StatusInternalServerError, err.Error())
-		}
+		c.Error(err)
 		return
 	}
 
 	resp := new(dto.SubmitFormResponse)
 	if err := copier.Copy(resp, submission); err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -257,9 +234,9 @@ func (h *FormHandler) UserSubmittedForm(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByEmail(email)
+	user, err := h.authService.GetUserByEmail(c.Request.Context(), email)
 	if err != nil && err != service.ErrUserNotFound {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -268,9 +245,9 @@ func (h *FormHandler) UserSubmittedForm(c *gin.Context) {
 		return
 	}
 
-	submitted, err := h.formSubmissionService.UserSubmittedForm(uint(formID), user.ID)
+	submitted, err := h.formSubmissionService.UserSubmittedForm(c.Request.Context(), uint(formID), user.ID)
 	if err != nil {
-		schema.SendError(c, http.StatusInternalServerError, err.Error())
+		c.Error(err)
 		return
 	}
 
@@ -286,23 +263,16 @@ func (h *FormHandler) GetFormVoters(c *gin.Context) {
 	}
 
 	userID := c.GetUint("user_id")
-	submissions, err := h.formSubmissionService.GetFormVoters(uint(formID), userID)
+	submissions, err := h.formSubmissionService.GetFormVoters(c.Request.Context(), uint(formID), userID)
 	if err != nil {
-		switch err {
-		case service.ErrFormNotFound:
-			schema.SendError(c, http.StatusNotFound, err.Error())
-		case service.ErrNotFormOwner:
-			schema.SendError(c, http.StatusForbidden, err.Error())
-		default:
-			schema.SendError(c, http.StatusInternalServerError, err.Error())
-		}
+		c.Error(err)
 		return
 	}
 
 	resp := make([]dto.FormVoterResponse, len(submissions))
 	for i, submission := range submissions {
 		// Get participation status for each user
-		participation, err := h.dashboardService.GetUserFormParticipation(submission.UserID, uint(formID))
+		participation, err := h.dashboardService.GetUserFormParticipation(c.Request.Context(), submission.UserID, uint(formID))
 		var status string
 		var lastModified *time.Time
 
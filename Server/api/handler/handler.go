@@ -1,21 +1,45 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/validation"
 )
 
+// bindAndValidate binds the JSON body into req. On failure the error is
+// attached to the context for ErrorMiddleware to render and false is returned.
 func bindAndValidate(c *gin.Context, req any) bool {
 	if err := c.ShouldBindJSON(req); err != nil {
-		if ve, ok := err.(validator.ValidationErrors); ok {
-			schema.SendValidationError(c, ve)
-		} else {
-			schema.SendError(c, http.StatusBadRequest, err.Error())
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Error(apperr.ErrBodyTooLarge.Wrap(err))
+			return false
 		}
+		c.Error(validation.FromBindingError(err))
 		return false
 	}
 	return true
 }
+
+// parsePagination reads the page and per_page query parameters, falling back
+// to sane defaults for missing or invalid values.
+func parsePagination(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	if err != nil || perPage < 1 {
+		perPage = 10
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	return page, perPage
+}
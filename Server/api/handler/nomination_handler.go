@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type NominationHandler struct {
+	nominationService service.NominationService
+}
+
+func NewNominationHandler(nominationService service.NominationService) *NominationHandler {
+	return &NominationHandler{nominationService: nominationService}
+}
+
+func (h *NominationHandler) GetNominations(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	nominations, err := h.nominationService.GetNominations(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-nominations", nominations)
+}
+
+func (h *NominationHandler) Nominate(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.NominateRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	nomination, err := h.nominationService.Nominate(c.Request.Context(), uint(formID), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "nominate", nomination)
+}
+
+func (h *NominationHandler) SupportNomination(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+	nominationID, err := strconv.ParseUint(c.Param("nomination_id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid nomination ID")
+		return
+	}
+
+	nomination, err := h.nominationService.SupportNomination(c.Request.Context(), uint(formID), uint(nominationID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "support-nomination", nomination)
+}
+
+// ModerateNomination lets the form owner approve or reject a nomination, or
+// put it back to pending.
+func (h *NominationHandler) ModerateNomination(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+	nominationID, err := strconv.ParseUint(c.Param("nomination_id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid nomination ID")
+		return
+	}
+
+	req := new(dto.ModerateNominationRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	nomination, err := h.nominationService.ModerateNomination(c.Request.Context(), uint(formID), uint(nominationID), c.GetUint("user_id"), model.NominationStatus(req.Status))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "moderate-nomination", nomination)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/config"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+	frontendURL string
+	cookies     authCookies
+}
+
+func NewOIDCHandler(
+	oidcService service.OIDCService,
+	frontendURL string,
+	cookieCfg config.AuthCookieConfig,
+) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		frontendURL: frontendURL,
+		cookies:     authCookies{cfg: cookieCfg},
+	}
+}
+
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.Error(service.ErrOIDCDisabled)
+		return
+	}
+
+	ctx := c.Request.Context()
+	authURL, loginState, err := h.oidcService.AuthorizationURL(ctx)
+	if err != nil {
+		// Provider errors can include its URLs and responses, keep them in the logs
+		applog.FromContext(ctx).ErrorContext(ctx, "failed to start single sign-on", "error", err)
+		c.Error(service.ErrOIDCUnavailable.Wrap(err))
+		return
+	}
+
+	h.cookies.setOIDCState(c, loginState)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.Error(service.ErrOIDCDisabled)
+		return
+	}
+
+	loginState := h.cookies.oidcState(c)
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.redirectWithError(c, providerErr)
+		return
+	}
+
+	_, jwtToken, refreshToken, err := h.oidcService.HandleCallback(
+		c.Request.Context(),
+		loginState,
+		c.Query("state"),
+		c.Query("code"),
+	)
+	if err != nil {
+		switch err {
+		case service.ErrEmailNotVerified:
+			h.redirectWithError(c, "email_not_verified")
+		case service.ErrInvalidOIDCState:
+			h.redirectWithError(c, "invalid_state")
+		case service.ErrAccountDisabled:
+			h.redirectWithError(c, "account_disabled")
+		default:
+			h.redirectWithError(c, "sso_failed")
+		}
+		return
+	}
+
+	// Tokens travel in the fragment so they never reach server logs
+	fragment := url.Values{}
+	fragment.Set("access_token", jwtToken)
+	if h.cookies.enabled() {
+		if err := h.cookies.set(c, refreshToken); err != nil {
+			h.redirectWithError(c, "sso_failed")
+			return
+		}
+	} else {
+		fragment.Set("refresh_token", refreshToken)
+	}
+	c.Redirect(http.StatusFound, h.frontendURL+"/auth/callback#"+fragment.Encode())
+}
+
+func (h *OIDCHandler) redirectWithError(c *gin.Context, reason string) {
+	c.Redirect(http.StatusFound, h.frontendURL+"/login?error="+url.QueryEscape(reason))
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+// streamHeartbeat is how often an idle results stream sends a comment so
+// proxies do not close the connection.
+const streamHeartbeat = 15 * time.Second
+
+type ResultsHandler struct {
+	resultsService     service.ResultsService
+	outcomeService     service.OutcomeService
+	liveResultsService service.LiveResultsService
+}
+
+func NewResultsHandler(
+	resultsService service.ResultsService,
+	outcomeService service.OutcomeService,
+	liveResultsService service.LiveResultsService,
+) *ResultsHandler {
+	return &ResultsHandler{
+		resultsService:     resultsService,
+		outcomeService:     outcomeService,
+		liveResultsService: liveResultsService,
+	}
+}
+
+func (h *ResultsHandler) GetFormResults(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	results, err := h.resultsService.GetFormResults(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-form-results", results)
+}
+
+// IssueStreamToken returns a short-lived token that opens the form's results
+// stream, for browsers whose EventSource cannot send an Authorization header.
+func (h *ResultsHandler) IssueStreamToken(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	token, err := h.liveResultsService.StreamToken(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "issue-stream-token", token)
+}
+
+// StreamResults sends the form's results as Server-Sent Events: a "results"
+// event with the current count right away and another one after ballots are
+// stored, until the client disconnects.
+func (h *ResultsHandler) StreamResults(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+	updates, err := h.liveResultsService.Subscribe(ctx, uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		applog.FromContext(ctx).WarnContext(ctx, "failed to clear write deadline of results stream", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			data, err := json.Marshal(update)
+			if err != nil {
+				return false
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: results\ndata: %s\n\n", update.Sequence, data)
+			return err == nil
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
+
+// ExportBallots returns the form's anonymous ballots as JSON, or with
+// ?format=blt&question_id=<id> one ranked question as a BLT file.
+func (h *ResultsHandler) ExportBallots(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		ballots, err := h.resultsService.ExportBallots(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		schema.SendSuccess(c, "export-ballots", ballots)
+	case "blt":
+		questionID, err := strconv.ParseUint(c.Query("question_id"), 10, 64)
+		if err != nil {
+			schema.SendError(c, http.StatusBadRequest, "question_id is required for BLT exports")
+			return
+		}
+
+		blt, err := h.resultsService.ExportBLT(c.Request.Context(), uint(formID), uint(questionID), c.GetUint("user_id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="form-%d-question-%d.blt"`, formID, questionID))
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", blt)
+	default:
+		schema.SendError(c, http.StatusBadRequest, "format must be json or blt")
+	}
+}
+
+// GetFormOutcome reports the quorum and whether each question passed. The
+// outcome is provisional until the form closes.
+func (h *ResultsHandler) GetFormOutcome(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	outcome, err := h.outcomeService.GetFormOutcome(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-form-outcome", outcome)
+}
+
+func (h *ResultsHandler) GetWeightAudit(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	audit, err := h.resultsService.GetWeightAudit(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-weight-audit", audit)
+}
+
+// DecideTie records the form owner's choice between the options tied on a
+// question that leaves ties to the owner.
+func (h *ResultsHandler) DecideTie(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+	questionID, err := strconv.ParseUint(c.Param("question_id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid question ID")
+		return
+	}
+
+	req := new(dto.DecideTieRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	outcome, err := h.outcomeService.DecideTie(c.Request.Context(), uint(formID), uint(questionID), c.GetUint("user_id"), req.OptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "decide-tie", outcome)
+}
+
+// GetSharedResults returns the results of the form behind a share link. It
+// needs no session; the signed token is the credential.
+func (h *ResultsHandler) GetSharedResults(c *gin.Context) {
+	results, err := h.resultsService.GetSharedResults(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-shared-results", results)
+}
+
+func (h *ResultsHandler) GetResultsVisibility(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	visibility, err := h.resultsService.GetResultsVisibility(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-results-visibility", visibility)
+}
+
+func (h *ResultsHandler) SetResultsVisibility(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.SetResultsVisibilityRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	visibility, err := h.resultsService.SetResultsVisibility(c.Request.Context(), uint(formID), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "set-results-visibility", visibility)
+}
+
+// RotateResultsLink issues a new share link for the form's results and
+// revokes the previous one.
+func (h *ResultsHandler) RotateResultsLink(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	visibility, err := h.resultsService.RotateResultsLink(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "rotate-results-link", visibility)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type RunoffHandler struct {
+	runoffService service.RunoffService
+}
+
+func NewRunoffHandler(runoffService service.RunoffService) *RunoffHandler {
+	return &RunoffHandler{runoffService: runoffService}
+}
+
+func (h *RunoffHandler) CreateRunoff(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.CreateRunoffRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	runoff, err := h.runoffService.CreateRunoff(c.Request.Context(), uint(formID), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := new(dto.GetFormResponse)
+	if err := copier.Copy(&resp, runoff); err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "create-runoff", resp)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
+)
+
+type VoterRollHandler struct {
+	voterRollService service.VoterRollService
+}
+
+func NewVoterRollHandler(voterRollService service.VoterRollService) *VoterRollHandler {
+	return &VoterRollHandler{voterRollService: voterRollService}
+}
+
+func (h *VoterRollHandler) GetVoterRoll(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	voters, err := h.voterRollService.GetVoterRoll(c.Request.Context(), uint(formID), c.GetUint("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "get-voter-roll", toVoterRollResponse(uint(formID), voters))
+}
+
+func (h *VoterRollHandler) SetVoterRoll(c *gin.Context) {
+	formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		schema.SendError(c, http.StatusBadRequest, "invalid form ID")
+		return
+	}
+
+	req := new(dto.SetVoterRollRequest)
+	if ok := bindAndValidate(c, &req); !ok {
+		return
+	}
+
+	voters, err := h.voterRollService.SetVoterRoll(c.Request.Context(), uint(formID), c.GetUint("user_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	schema.SendSuccess(c, "set-voter-roll", toVoterRollResponse(uint(formID), voters))
+}
+
+func toVoterRollResponse(formID uint, voters []*model.EligibleVoter) *dto.VoterRollResponse {
+	resp := &dto.VoterRollResponse{
+		FormID: formID,
+		Voters: make([]dto.EligibleVoterResponse, len(voters)),
+	}
+	for i, voter := range voters {
+		resp.Voters[i] = dto.EligibleVoterResponse{
+			UserID:      voter.UserID,
+			Email:       voter.User.Email,
+			DisplayName: voter.User.DisplayName,
+			Weight:      voter.Weight,
+			AddedAt:     voter.CreatedAt,
+		}
+	}
+	return resp
+}
@@ -1,59 +1,189 @@
 package middleware
 
 import (
-	"net/http"
+	"errors"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
 	"github.com/luneto10/voting-system/internal/helper/auth"
-	"github.com/luneto10/voting-system/internal/schema"
+	"github.com/luneto10/voting-system/internal/service"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+const (
+	AuthMethodJWT         = "jwt"
+	AuthMethodAPIToken    = "api_token"
+	AuthMethodStreamToken = "stream_token"
+)
+
+// AuthMiddleware accepts either a JWT issued at login or a personal access token.
+// The user behind a JWT is loaded on every request, so disabling an account or
+// changing its role takes effect before the token expires.
+func AuthMiddleware(apiTokenService service.APITokenService, authService service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			schema.SendError(c, http.StatusUnauthorized, "Authorization header is required")
-			c.AbortWithStatus(http.StatusUnauthorized)
+			c.Error(apperr.ErrUnauthorized.WithMessage("Authorization header is required"))
+			c.Abort()
 			return
 		}
 
 		// Check if the Authorization header has the correct format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			schema.SendError(c, http.StatusUnauthorized, "Invalid authorization header format")
-			c.AbortWithStatus(http.StatusUnauthorized)
+			c.Error(apperr.ErrUnauthorized.WithMessage("Invalid authorization header format"))
+			c.Abort()
 			return
 		}
 
 		tokenString := parts[1]
+		if auth.IsAPIToken(tokenString) {
+			apiToken, err := apiTokenService.Authenticate(c.Request.Context(), tokenString)
+			if err != nil {
+				c.Error(apperr.ErrUnauthorized.WithMessage("Invalid token"))
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", apiToken.UserID)
+			c.Set("role", string(apiToken.User.Role))
+			c.Set("scopes", strings.Fields(apiToken.Scopes))
+			c.Set("auth_method", AuthMethodAPIToken)
+			c.Next()
+			return
+		}
+
 		token, err := auth.ValidateToken(tokenString)
 		if err != nil {
-			schema.SendError(c, http.StatusUnauthorized, "Invalid token")
-			c.AbortWithStatus(http.StatusUnauthorized)
+			c.Error(apperr.ErrUnauthorized.WithMessage("Invalid token"))
+			c.Abort()
 			return
 		}
 
 		// Extract claims
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			schema.SendError(c, http.StatusUnauthorized, "Invalid token claims")
-			c.AbortWithStatus(http.StatusUnauthorized)
+			c.Error(apperr.ErrUnauthorized.WithMessage("Invalid token claims"))
+			c.Abort()
 			return
 		}
 
 		// Get user ID from sub claim
 		userID, ok := claims["sub"].(float64)
 		if !ok {
-			schema.SendError(c, http.StatusUnauthorized, "Invalid user ID in token")
-			c.AbortWithStatus(http.StatusUnauthorized)
+			c.Error(apperr.ErrUnauthorized.WithMessage("Invalid user ID in token"))
+			c.Abort()
+			return
+		}
+
+		user, err := authService.AuthenticateUser(c.Request.Context(), uint(userID))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				err = apperr.ErrUnauthorized.WithMessage("Invalid token")
+			}
+			c.Error(err)
+			c.Abort()
 			return
 		}
 
 		// Set both the complete claims and the user ID in context
 		c.Set("claims", claims)
-		c.Set("user_id", uint(userID))
+		c.Set("user_id", user.ID)
+		c.Set("role", string(user.Role))
+		c.Set("scopes", auth.AllScopes)
+		c.Set("auth_method", AuthMethodJWT)
 		c.Next()
 	}
 }
+
+// StreamAuthMiddleware authenticates a form's results stream with a token
+// from POST /forms/:id/results/stream-token in the token query parameter, as
+// a browser's EventSource cannot send an Authorization header. The token only
+// grants reading that form's results. Requests without one are authenticated
+// like any other.
+func StreamAuthMiddleware(apiTokenService service.APITokenService, authService service.AuthService) gin.HandlerFunc {
+	authenticate := AuthMiddleware(apiTokenService, authService)
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			authenticate(c)
+			return
+		}
+
+		formID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.Error(apperr.ErrBadRequest.WithMessage("invalid form ID"))
+			c.Abort()
+			return
+		}
+		userID, err := auth.ParseStreamToken(token, uint(formID), time.Now())
+		if err != nil {
+			c.Error(apperr.ErrUnauthorized.WithMessage("Invalid stream token"))
+			c.Abort()
+			return
+		}
+		user, err := authService.AuthenticateUser(c.Request.Context(), userID)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidToken) {
+				err = apperr.ErrUnauthorized.WithMessage("Invalid stream token")
+			}
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", user.ID)
+		c.Set("role", string(user.Role))
+		c.Set("scopes", []string{auth.ScopeResultsRead})
+		c.Set("auth_method", AuthMethodStreamToken)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose credentials were not granted the scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, granted := range c.GetStringSlice("scopes") {
+			if granted == scope {
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(apperr.ErrForbidden.WithMessage("token is missing the required scope: " + scope))
+		c.Abort()
+	}
+}
+
+// RequireSession only allows requests authenticated with a login session, so
+// personal access tokens cannot be used to manage account credentials.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != AuthMethodJWT {
+			c.Error(apperr.ErrForbidden.WithMessage("this endpoint requires a login session"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole only allows users holding one of the given roles.
+func RequireRole(roles ...model.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := model.UserRole(c.GetString("role"))
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(apperr.ErrForbidden.WithMessage("insufficient role"))
+		c.Abort()
+	}
+}
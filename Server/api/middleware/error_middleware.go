@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/db"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/schema"
+)
+
+// ErrorMiddleware renders the last error a handler attached with c.Error as an
+// RFC 7807 problem response, unless the handler already wrote a response.
+// Errors without a code of their own are logged and reported as a generic
+// 500 so internal details do not leak to clients.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		if errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			// The client went away, nobody is left to read a body
+			c.AbortWithStatus(apperr.ErrClientClosed.Status)
+			return
+		}
+
+		problem := toProblem(err)
+		ctx := c.Request.Context()
+		switch {
+		case errors.Is(problem, apperr.ErrInternal):
+			applog.FromContext(ctx).ErrorContext(ctx, "request failed", "error", err)
+		case problem.Status >= http.StatusInternalServerError:
+			applog.FromContext(ctx).WarnContext(ctx, "request failed", "error", err, "code", problem.Code)
+		}
+		schema.SendProblem(c, problem)
+	}
+}
+
+// toProblem finds the application error behind err. Timeouts get their own
+// codes so clients can tell them apart from failures and retry.
+func toProblem(err error) *apperr.Error {
+	if appErr, ok := apperr.As(err); ok {
+		return appErr
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperr.ErrRequestTimeout.Wrap(err)
+	case db.IsQueryTimeout(err):
+		return apperr.ErrQueryTimeout.Wrap(err)
+	case errors.As(err, &tooLarge):
+		return apperr.ErrBodyTooLarge.Wrap(err)
+	default:
+		return apperr.ErrInternal.Wrap(err)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware gives every request a deadline. Handlers pass the request
+// context down to the database, so queries still running when it expires are
+// cancelled and ErrorMiddleware answers with 504 Gateway Timeout. Client
+// disconnects cancel the same context. Routes listed in exempt, such as
+// event streams, run without a deadline.
+func TimeoutMiddleware(timeout time.Duration, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
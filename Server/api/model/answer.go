@@ -9,5 +9,22 @@ type Answer struct {
 	QuestionID   uint       `gorm:"not null;index"`
 	Question     Question   `gorm:"foreignKey:QuestionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	Text         *string    `gorm:"type:text"`
-	Options      []Option   `gorm:"many2many:answer_options;"`
+	// Abstain marks an explicit abstention: the voter took part without
+	// choosing any option.
+	Abstain bool     `gorm:"not null;default:false"`
+	Options []Option `gorm:"many2many:answer_options;"`
+	// Preferences keeps the order of ranked ballots and the scores of score
+	// ballots, which the options association cannot hold.
+	Preferences []AnswerPreference `gorm:"foreignKey:AnswerID;constraint:OnDelete:CASCADE"`
+}
+
+// AnswerPreference is one option's place on a ranked ballot (Rank 1 being the
+// first preference) or its score on a score ballot.
+type AnswerPreference struct {
+	ID       uint   `gorm:"primaryKey"`
+	AnswerID uint   `gorm:"not null;index"`
+	OptionID uint   `gorm:"not null"`
+	Option   Option `gorm:"foreignKey:OptionID"`
+	Rank     int    `gorm:"not null;default:0"`
+	Score    int    `gorm:"not null;default:0"`
 }
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIToken is a personal access token a user creates for scripts and automation.
+// Only a hash of the token is stored; the plain value is shown once on creation.
+type APIToken struct {
+	gorm.Model
+	UserID     uint       `gorm:"not null;index"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name       string     `gorm:"not null"`
+	Prefix     string     `gorm:"not null"`             // Leading characters of the token, used to identify it in listings
+	TokenHash  string     `gorm:"not null;uniqueIndex"` // SHA-256 of the token
+	Scopes     string     `gorm:"not null"`             // Space separated list of granted scopes
+	ExpiresAt  *time.Time // Nil means the token never expires
+	LastUsedAt *time.Time
+	Revoked    bool `gorm:"default:false"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog records an administrative action for later review.
+type AuditLog struct {
+	gorm.Model
+	ActorID    uint            `gorm:"not null;index" json:"actor_id"`
+	Actor      User            `gorm:"foreignKey:ActorID" json:"-"`
+	Action     string          `gorm:"not null;index" json:"action"`
+	TargetType string          `gorm:"not null" json:"target_type"`
+	TargetID   uint            `gorm:"not null" json:"target_id"`
+	Details    json.RawMessage `gorm:"type:json" json:"details"`
+	IPAddress  string          `json:"ip_address"`
+}
+
+const (
+	AuditActionUserDisabled   = "user.disabled"
+	AuditActionUserEnabled    = "user.enabled"
+	AuditActionUserRoleChange = "user.role_changed"
+	AuditActionUserUnlocked   = "user.unlocked"
+	AuditActionFormViewed     = "form.viewed"
+	AuditActionFormTakenDown  = "form.taken_down"
+)
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Delegation lets a delegate cast the delegator's vote on a form. Delegations
+// do not chain: a delegate cannot delegate in turn, and a voter holding
+// proxies cannot hand their own vote on. A delegator who votes themselves
+// replaces any ballot their delegate cast for them.
+type Delegation struct {
+	ID          uint `gorm:"primaryKey"`
+	FormID      uint `gorm:"not null;uniqueIndex:idx_delegations_form_delegator"`
+	Form        Form `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	DelegatorID uint `gorm:"not null;uniqueIndex:idx_delegations_form_delegator"`
+	Delegator   User `gorm:"foreignKey:DelegatorID;constraint:OnDelete:CASCADE"`
+	DelegateID  uint `gorm:"not null;index"`
+	Delegate    User `gorm:"foreignKey:DelegateID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time
+}
@@ -6,13 +6,79 @@ import (
 	"gorm.io/gorm"
 )
 
+// QuorumType says how the quorum of a form is expressed. Forms without one
+// have no quorum.
+type QuorumType string
+
+const (
+	QuorumAbsolute   QuorumType = "absolute"   // a number of participants
+	QuorumPercentage QuorumType = "percentage" // a percentage of the voter roll
+)
+
+// ResultsVisibility says who besides the owner can see the results of a form.
+// Forms without one show them to the owner only.
+type ResultsVisibility string
+
+const (
+	ResultsOwner  ResultsVisibility = "owner"  // the owner only
+	ResultsVoters ResultsVisibility = "voters" // voters once they have voted
+	ResultsClosed ResultsVisibility = "closed" // everyone once voting closes
+	ResultsLink   ResultsVisibility = "link"   // anyone with the share link
+)
+
 type Form struct {
 	gorm.Model
 	Title       string     `json:"title" gorm:"not null" validate:"required,min=5,max=100"`
 	Description string     `json:"description"`
 	StartAt     time.Time  `json:"start_at" gorm:"default:null"`
 	EndAt       time.Time  `json:"end_at" gorm:"default:null"`
+	QuorumType  QuorumType `json:"quorum_type" gorm:"not null;default:''"`
+	Quorum      int        `json:"quorum" gorm:"not null;default:0"`
 	UserID      uint       `json:"user_id" gorm:"not null;index"`
 	User        User       `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Questions   []Question `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	// TieBreakSeed decides random tie-breaks. It stays secret until voting
+	// closes, while TieBreakSeedHash, its SHA-256 digest, is public from the
+	// moment a question first uses random tie-breaks.
+	TieBreakSeed     string `json:"-" gorm:"not null;default:''"`
+	TieBreakSeedHash string `json:"tie_break_seed_hash" gorm:"not null;default:''"`
+	// RunoffOfID links a runoff to the earlier form whose question
+	// RunoffOfQuestionID it decides between the leading options.
+	RunoffOfID         *uint `json:"runoff_of_id" gorm:"index"`
+	RunoffOf           *Form `json:"-" gorm:"foreignKey:RunoffOfID;constraint:OnDelete:SET NULL"`
+	RunoffOfQuestionID *uint `json:"runoff_of_question_id"`
+	// NominationStartAt and NominationEndAt bound the nomination phase that
+	// comes before voting on forms whose questions take nominations. The
+	// nominations are turned into options once, at NominationsConvertedAt.
+	NominationStartAt      *time.Time `json:"nomination_start_at"`
+	NominationEndAt        *time.Time `json:"nomination_end_at"`
+	NominationsConvertedAt *time.Time `json:"nominations_converted_at"`
+	// Nobody but the owner sees the results before ResultsEmbargoUntil,
+	// whatever ResultsVisibility allows. Bumping ResultsLinkVersion revokes
+	// the share links handed out so far.
+	ResultsVisibility   ResultsVisibility `json:"results_visibility" gorm:"not null;default:''"`
+	ResultsEmbargoUntil *time.Time        `json:"results_embargo_until"`
+	ResultsLinkVersion  int               `json:"-" gorm:"not null;default:0"`
+}
+
+// HasNominationPhase reports whether the form collects nominations before
+// voting.
+func (f *Form) HasNominationPhase() bool {
+	return f.NominationStartAt != nil && f.NominationEndAt != nil
+}
+
+// NominationsOpen reports whether nominations can be made and supported.
+func (f *Form) NominationsOpen(now time.Time) bool {
+	return f.HasNominationPhase() && !now.Before(*f.NominationStartAt) && now.Before(*f.NominationEndAt)
+}
+
+// NominationsDue reports whether the nomination phase has ended but its
+// nominations have not been turned into options yet.
+func (f *Form) NominationsDue(now time.Time) bool {
+	return f.HasNominationPhase() && f.NominationsConvertedAt == nil && !now.Before(*f.NominationEndAt)
+}
+
+// Closed reports whether voting on the form has ended.
+func (f *Form) Closed(now time.Time) bool {
+	return !f.EndAt.IsZero() && !now.Before(f.EndAt)
 }
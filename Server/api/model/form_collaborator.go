@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// CollaboratorRole is what a collaborator may do on a form. Each role
+// includes the ones before it.
+type CollaboratorRole string
+
+const (
+	CollaboratorResultsViewer CollaboratorRole = "results_viewer" // see the form, its results and voters
+	CollaboratorEditor        CollaboratorRole = "editor"         // also change the form and run the vote
+	CollaboratorOwner         CollaboratorRole = "owner"          // also delete the form and manage its team
+)
+
+var collaboratorRoleRanks = map[CollaboratorRole]int{
+	CollaboratorResultsViewer: 1,
+	CollaboratorEditor:        2,
+	CollaboratorOwner:         3,
+}
+
+// Includes reports whether the role grants everything the other role does.
+// The empty role, for users who are not on the form's team, includes none.
+func (r CollaboratorRole) Includes(other CollaboratorRole) bool {
+	rank := collaboratorRoleRanks[r]
+	return rank > 0 && rank >= collaboratorRoleRanks[other]
+}
+
+// FormCollaborator gives a user a role on a form owned by someone else. The
+// form's own UserID always holds the owner role and has no collaborator row.
+type FormCollaborator struct {
+	ID          uint             `gorm:"primaryKey"`
+	FormID      uint             `gorm:"not null;uniqueIndex:idx_form_collaborators_form_user"`
+	Form        Form             `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	UserID      uint             `gorm:"not null;uniqueIndex:idx_form_collaborators_form_user;index"`
+	User        User             `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Role        CollaboratorRole `gorm:"not null"`
+	InvitedByID *uint            `gorm:"index"`
+	InvitedBy   *User            `gorm:"foreignKey:InvitedByID;constraint:OnDelete:SET NULL"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// FormOutcome is the binding decision of a form. It is computed when voting
+// closes, against the voter roll and participants of that moment, and never
+// recomputed, so later changes cannot alter a decision. An outcome with a tie
+// left to the owner is stored provisional until the tie is broken.
+type FormOutcome struct {
+	ID            uint      `gorm:"primaryKey"`
+	FormID        uint      `gorm:"not null;uniqueIndex"`
+	Form          Form      `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	ClosedAt      time.Time `gorm:"not null"`
+	Final         bool      `gorm:"not null"`
+	QuorumReached bool      `gorm:"not null"`
+	// EligibleVoters, Participants and QuorumRequired are the quorum as
+	// counted when voting closed.
+	EligibleVoters int `gorm:"not null;default:0"`
+	Participants   int `gorm:"not null;default:0"`
+	QuorumRequired int `gorm:"not null;default:0"`
+	// Report is the full outcome as returned by the API, in JSON.
+	Report    string `gorm:"type:jsonb;not null"`
+	DecidedAt *time.Time
+	CreatedAt time.Time
+}
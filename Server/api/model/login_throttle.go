@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginThrottle tracks recent failed logins for an account ("email:<address>")
+// or a client ("ip:<address>").
+type LoginThrottle struct {
+	gorm.Model
+	Key           string     `gorm:"not null;uniqueIndex"`
+	Failures      int        `gorm:"not null;default:0"`
+	LastFailureAt time.Time  `gorm:"not null"`
+	LockedUntil   *time.Time // Set once the failure limit is reached
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// NominationStatus is where a nomination stands in the owner's moderation.
+type NominationStatus string
+
+const (
+	NominationPending  NominationStatus = "pending"
+	NominationApproved NominationStatus = "approved"
+	NominationRejected NominationStatus = "rejected"
+)
+
+// Nomination proposes an option for a question during the form's nomination
+// phase. OptionID is set once the nomination has become an option.
+type Nomination struct {
+	ID            uint                `gorm:"primaryKey"`
+	FormID        uint                `gorm:"not null;index"`
+	Form          Form                `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	QuestionID    uint                `gorm:"not null;index"`
+	Question      Question            `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	Title         string              `gorm:"not null"`
+	NominatedByID uint                `gorm:"not null;index"`
+	NominatedBy   User                `gorm:"foreignKey:NominatedByID;constraint:OnDelete:CASCADE"`
+	Status        NominationStatus    `gorm:"not null;default:'pending'"`
+	OptionID      *uint               `gorm:"index"`
+	Option        *Option             `gorm:"foreignKey:OptionID;constraint:OnDelete:SET NULL"`
+	Supporters    []NominationSupport `gorm:"foreignKey:NominationID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// NominationSupport records a voter seconding someone else's nomination.
+type NominationSupport struct {
+	ID           uint `gorm:"primaryKey"`
+	NominationID uint `gorm:"not null;uniqueIndex:idx_nomination_supports_nomination_user"`
+	UserID       uint `gorm:"not null;uniqueIndex:idx_nomination_supports_nomination_user"`
+	User         User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time
+}
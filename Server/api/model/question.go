@@ -10,11 +10,62 @@ const (
 	QuestionTypeText           QuestionType = "text"
 )
 
+// TallyMethod decides how a choice question is counted and what its ballots
+// look like. Text questions have none.
+type TallyMethod string
+
+const (
+	TallyMethodPlurality TallyMethod = "plurality"
+	TallyMethodApproval  TallyMethod = "approval"
+	TallyMethodScore     TallyMethod = "score"
+	TallyMethodBorda     TallyMethod = "borda"
+	TallyMethodSchulze   TallyMethod = "schulze"
+	TallyMethodSTV       TallyMethod = "stv"
+)
+
+// PassThreshold is the share of the votes the leading option needs for a
+// motion to pass. Questions without one are decided by the winner alone.
+type PassThreshold string
+
+const (
+	ThresholdMajority      PassThreshold = "majority"       // more than half
+	ThresholdTwoThirds     PassThreshold = "two_thirds"     // at least two thirds
+	ThresholdThreeQuarters PassThreshold = "three_quarters" // at least three quarters
+	ThresholdUnanimous     PassThreshold = "unanimous"      // every vote
+)
+
+// TieBreakPolicy picks the winner when options tie for first place. Questions
+// without one report the tie.
+type TieBreakPolicy string
+
+const (
+	TieBreakOwner    TieBreakPolicy = "owner"    // the form owner chooses
+	TieBreakEarliest TieBreakPolicy = "earliest" // the option that reached its total first
+	TieBreakRandom   TieBreakPolicy = "random"   // a draw with the form's committed seed
+)
+
 type Question struct {
 	gorm.Model
-	Title   string       `gorm:"not null"`
-	Type    QuestionType `gorm:"not null"`
-	FormID  uint         `gorm:"not null;index"`
-	Form    Form         `gorm:"foreignKey:FormID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
-	Options []*Option    `gorm:"many2many:question_options;"`
+	Title       string       `gorm:"not null"`
+	Type        QuestionType `gorm:"not null"`
+	TallyMethod TallyMethod  `gorm:"not null;default:''"`
+	// MaxScore is the highest score a voter can give an option under score voting.
+	MaxScore int `gorm:"not null;default:0"`
+	// Seats is the number of options elected by an STV question.
+	Seats         int           `gorm:"not null;default:0"`
+	PassThreshold PassThreshold `gorm:"not null;default:''"`
+	// CountAbstentions counts abstentions in the votes the threshold is
+	// measured against, so abstaining has the effect of voting against.
+	CountAbstentions bool           `gorm:"not null;default:false"`
+	TieBreak         TieBreakPolicy `gorm:"not null;default:''"`
+	FormID           uint           `gorm:"not null;index"`
+	Form             Form           `gorm:"foreignKey:FormID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Options          []*Option      `gorm:"many2many:question_options;"`
+	// AcceptNominations lets eligible voters propose options during the
+	// form's nomination phase. A nomination becomes an option when at least
+	// NominationSupport other voters second it and, with ModerateNominations,
+	// the form owner approved it.
+	AcceptNominations   bool `gorm:"not null;default:false"`
+	NominationSupport   int  `gorm:"not null;default:0"`
+	ModerateNominations bool `gorm:"not null;default:false"`
 }
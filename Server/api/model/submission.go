@@ -8,10 +8,13 @@ import (
 
 type Submission struct {
 	gorm.Model
-	UserID      uint       `gorm:"not null;index"`
+	UserID      uint       `gorm:"not null;index;uniqueIndex:idx_submissions_user_form,where:deleted_at IS NULL"`
 	User        User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
-	FormID      uint       `gorm:"not null;index"`
+	FormID      uint       `gorm:"not null;index;uniqueIndex:idx_submissions_user_form,where:deleted_at IS NULL"`
 	Form        Form       `gorm:"foreignKey:FormID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Weight      int        `gorm:"not null;default:1"` // The voter's weight on the roll when the ballot was cast
+	CastByID    *uint      `gorm:"index"`              // Set when a proxy cast the ballot on the voter's behalf
+	CastBy      *User      `gorm:"foreignKey:CastByID;constraint:OnDelete:SET NULL"`
 	CompletedAt *time.Time `gorm:"autoUpdateTime"`
 	Answers     []Answer   `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
 }
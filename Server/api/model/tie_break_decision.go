@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// TieBreakDecision is the option a form owner chose to break a tie on a
+// question with the owner tie-break policy. A question is decided once.
+type TieBreakDecision struct {
+	ID          uint     `gorm:"primaryKey"`
+	FormID      uint     `gorm:"not null;index"`
+	Form        Form     `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	QuestionID  uint     `gorm:"not null;uniqueIndex"`
+	Question    Question `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	OptionID    uint     `gorm:"not null"`
+	DecidedByID uint     `gorm:"not null"`
+	DecidedBy   User     `gorm:"foreignKey:DecidedByID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time
+}
@@ -1,6 +1,10 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type UserRole string
 
@@ -11,9 +15,18 @@ const (
 
 type User struct {
 	gorm.Model
-	Email       string       `gorm:"not null;unique;index"`
-	Password    string       `gorm:"not null"`
-	Role        UserRole     `gorm:"not null;default:user"`
+	Email       string   `gorm:"not null;unique;index"`
+	Password    string   `gorm:"not null"`
+	Role        UserRole `gorm:"not null;default:user"`
+	Disabled    bool     `gorm:"not null;default:false"`
+	DisplayName string
+	Timezone    string `gorm:"not null;default:UTC"` // IANA time zone name
+
+	// Pending email change, applied once the new address is verified
+	PendingEmail               *string
+	EmailVerificationHash      string `gorm:"index"`
+	EmailVerificationExpiresAt *time.Time
+
 	Submissions []Submission `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Forms       []Form       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
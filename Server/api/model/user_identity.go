@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// UserIdentity links a User to an account at an external identity provider.
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	User     User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Provider string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"` // Issuer URL of the provider
+	Subject  string `gorm:"not null;uniqueIndex:idx_identity_provider_subject"` // The provider's "sub" claim
+	Email    string `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// EligibleVoter puts a user on a form's voter roll. Forms with a roll only
+// accept submissions from the users on it, and percentage quorums are
+// measured against its size. Weight is how many votes the user's ballot
+// counts for, such as their shares or the size of the team they represent.
+type EligibleVoter struct {
+	ID        uint `gorm:"primaryKey"`
+	FormID    uint `gorm:"not null;uniqueIndex:idx_eligible_voters_form_user"`
+	Form      Form `gorm:"foreignKey:FormID;constraint:OnDelete:CASCADE"`
+	UserID    uint `gorm:"not null;uniqueIndex:idx_eligible_voters_form_user;index"`
+	User      User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Weight    int  `gorm:"not null;default:1"`
+	CreatedAt time.Time
+}
@@ -2,26 +2,49 @@ package router
 
 import (
 	"github.com/luneto10/voting-system/api/handler"
+	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/notify"
+	"github.com/luneto10/voting-system/internal/pubsub"
 	"github.com/luneto10/voting-system/internal/repository"
 	"github.com/luneto10/voting-system/internal/service"
+	"github.com/luneto10/voting-system/internal/validation"
 	"gorm.io/gorm"
 )
 
 // Handler contains all API handlers
 type Handler struct {
-	FormHandler      *handler.FormHandler
-	AuthHandler      *handler.AuthHandler
-	DashboardHandler *handler.DashboardHandler
-	DraftHandler     *handler.DraftHandler
+	FormHandler         *handler.FormHandler
+	AuthHandler         *handler.AuthHandler
+	DashboardHandler    *handler.DashboardHandler
+	DraftHandler        *handler.DraftHandler
+	OIDCHandler         *handler.OIDCHandler
+	APITokenHandler     *handler.APITokenHandler
+	AdminHandler        *handler.AdminHandler
+	AccountHandler      *handler.AccountHandler
+	ResultsHandler      *handler.ResultsHandler
+	VoterRollHandler    *handler.VoterRollHandler
+	DelegationHandler   *handler.DelegationHandler
+	RunoffHandler       *handler.RunoffHandler
+	NominationHandler   *handler.NominationHandler
+	CollaboratorHandler *handler.CollaboratorHandler
 }
 
 // Repositories contains all repository instances
 type Repositories struct {
-	FormRepository         repository.FormRepository
-	UserRepository         repository.UserRepository
-	RefreshTokenRepository repository.RefreshTokenRepository
-	DashboardRepository    repository.DashboardRepository
-	DraftRepository        repository.DraftRepository
+	FormRepository          repository.FormRepository
+	UserRepository          repository.UserRepository
+	RefreshTokenRepository  repository.RefreshTokenRepository
+	DashboardRepository     repository.DashboardRepository
+	DraftRepository         repository.DraftRepository
+	UserIdentityRepository  repository.UserIdentityRepository
+	APITokenRepository      repository.APITokenRepository
+	AuditLogRepository      repository.AuditLogRepository
+	LoginThrottleRepository repository.LoginThrottleRepository
+	VoterRollRepository     repository.VoterRollRepository
+	OutcomeRepository       repository.OutcomeRepository
+	DelegationRepository    repository.DelegationRepository
+	NominationRepository    repository.NominationRepository
+	CollaboratorRepository  repository.CollaboratorRepository
 }
 
 type Services struct {
@@ -31,16 +54,29 @@ type Services struct {
 	AuthService              service.AuthService
 	DashboardService         service.DashboardService
 	DraftService             service.DraftService
+	OIDCService              service.OIDCService
+	APITokenService          service.APITokenService
+	AdminService             service.AdminService
+	LoginProtectionService   service.LoginProtectionService
+	AccountService           service.AccountService
+	ResultsService           service.ResultsService
+	OutcomeService           service.OutcomeService
+	VoterRollService         service.VoterRollService
+	DelegationService        service.DelegationService
+	RunoffService            service.RunoffService
+	NominationService        service.NominationService
+	LiveResultsService       service.LiveResultsService
+	CollaboratorService      service.CollaboratorService
 }
 
-func initDependencies(db *gorm.DB) *Handler {
+func initDependencies(db *gorm.DB, cfg *config.Config, broker pubsub.Broker) (*Handler, *Services) {
 	repos := initRepositories(db)
 
-	services := initServices(repos)
+	services := initServices(repos, cfg, broker)
 
-	handlers := initHandlers(services)
+	handlers := initHandlers(services, cfg)
 
-	return handlers
+	return handlers, services
 }
 
 func initRepositories(db *gorm.DB) *Repositories {
@@ -49,21 +85,48 @@ func initRepositories(db *gorm.DB) *Repositories {
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
 	dashboardRepo := repository.NewDashboardRepository(db)
 	draftRepo := repository.NewDraftRepository(db)
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(db)
+	voterRollRepo := repository.NewVoterRollRepository(db)
+	outcomeRepo := repository.NewOutcomeRepository(db)
+	delegationRepo := repository.NewDelegationRepository(db)
+	nominationRepo := repository.NewNominationRepository(db)
+	collaboratorRepo := repository.NewCollaboratorRepository(db)
 
 	return &Repositories{
-		FormRepository:         formRepo,
-		UserRepository:         userRepo,
-		RefreshTokenRepository: refreshTokenRepo,
-		DashboardRepository:    dashboardRepo,
-		DraftRepository:        draftRepo,
+		FormRepository:          formRepo,
+		UserRepository:          userRepo,
+		RefreshTokenRepository:  refreshTokenRepo,
+		DashboardRepository:     dashboardRepo,
+		DraftRepository:         draftRepo,
+		UserIdentityRepository:  userIdentityRepo,
+		APITokenRepository:      apiTokenRepo,
+		AuditLogRepository:      auditLogRepo,
+		LoginThrottleRepository: loginThrottleRepo,
+		VoterRollRepository:     voterRollRepo,
+		OutcomeRepository:       outcomeRepo,
+		DelegationRepository:    delegationRepo,
+		NominationRepository:    nominationRepo,
+		CollaboratorRepository:  collaboratorRepo,
 	}
 }
 
 // initServices initializes all services with their required repositories
-func initServices(repos *Repositories) *Services {
-	formAuthService := service.NewFormAuthorizationService(repos.FormRepository)
+func initServices(repos *Repositories, cfg *config.Config, broker pubsub.Broker) *Services {
+	formAuthService := service.NewFormAuthorizationService(
+		repos.FormRepository,
+		repos.VoterRollRepository,
+		repos.DelegationRepository,
+		repos.CollaboratorRepository,
+	)
 
-	formService := service.NewFormService(repos.FormRepository, formAuthService)
+	formService := service.NewFormService(
+		repos.FormRepository,
+		repos.NominationRepository,
+		formAuthService,
+	)
 
 	dashboardService := service.NewDashboardService(
 		repos.DashboardRepository,
@@ -73,14 +136,95 @@ func initServices(repos *Repositories) *Services {
 
 	formSubmissionService := service.NewFormSubmissionService(
 		repos.FormRepository,
+		repos.VoterRollRepository,
 		formService,
 		formAuthService,
 		dashboardService,
+		broker,
 	)
 
+	resultsService := service.NewResultsService(
+		repos.FormRepository,
+		repos.DelegationRepository,
+		formService,
+		formAuthService,
+	)
+
+	liveResultsService := service.NewLiveResultsService(
+		repos.FormRepository,
+		repos.VoterRollRepository,
+		formService,
+		formAuthService,
+		broker,
+	)
+
+	outcomeService := service.NewOutcomeService(
+		repos.FormRepository,
+		repos.OutcomeRepository,
+		repos.VoterRollRepository,
+		formService,
+		formAuthService,
+	)
+
+	voterRollService := service.NewVoterRollService(
+		repos.VoterRollRepository,
+		repos.UserRepository,
+		formService,
+		formAuthService,
+	)
+
+	delegationService := service.NewDelegationService(
+		repos.DelegationRepository,
+		repos.FormRepository,
+		repos.VoterRollRepository,
+		repos.UserRepository,
+		formService,
+		formAuthService,
+	)
+
+	runoffService := service.NewRunoffService(
+		repos.FormRepository,
+		repos.VoterRollRepository,
+		repos.CollaboratorRepository,
+		formService,
+		resultsService,
+		outcomeService,
+		formAuthService,
+		notify.NewLogNotifier(),
+		cfg.FrontendURL,
+	)
+
+	nominationService := service.NewNominationService(
+		repos.NominationRepository,
+		formService,
+		formAuthService,
+	)
+
+	collaboratorService := service.NewCollaboratorService(
+		repos.CollaboratorRepository,
+		repos.FormRepository,
+		repos.UserRepository,
+		formService,
+		formAuthService,
+		notify.NewLogNotifier(),
+		cfg.FrontendURL,
+	)
+
+	loginProtectionService := service.NewLoginProtectionService(
+		cfg.Login,
+		repos.LoginThrottleRepository,
+	)
+
+	passwordPolicy, err := validation.NewPasswordPolicy(cfg.Password)
+	if err != nil {
+		panic(err)
+	}
+
 	authService := service.NewAuthService(
 		repos.UserRepository,
 		repos.RefreshTokenRepository,
+		loginProtectionService,
+		passwordPolicy,
 	)
 
 	draftService := service.NewDraftService(
@@ -88,6 +232,31 @@ func initServices(repos *Repositories) *Services {
 		repos.FormRepository,
 	)
 
+	oidcService := service.NewOIDCService(
+		cfg.OIDC,
+		repos.UserRepository,
+		repos.UserIdentityRepository,
+		authService,
+	)
+
+	apiTokenService := service.NewAPITokenService(repos.APITokenRepository)
+
+	accountService := service.NewAccountService(
+		repos.UserRepository,
+		repos.FormRepository,
+		repos.DraftRepository,
+		notify.NewLogNotifier(),
+		cfg.FrontendURL,
+	)
+
+	adminService := service.NewAdminService(
+		repos.UserRepository,
+		repos.RefreshTokenRepository,
+		repos.FormRepository,
+		repos.AuditLogRepository,
+		loginProtectionService,
+	)
+
 	return &Services{
 		FormService:              formService,
 		FormSubmissionService:    formSubmissionService,
@@ -95,11 +264,24 @@ func initServices(repos *Repositories) *Services {
 		AuthService:              authService,
 		DashboardService:         dashboardService,
 		DraftService:             draftService,
+		OIDCService:              oidcService,
+		APITokenService:          apiTokenService,
+		AdminService:             adminService,
+		LoginProtectionService:   loginProtectionService,
+		AccountService:           accountService,
+		ResultsService:           resultsService,
+		OutcomeService:           outcomeService,
+		VoterRollService:         voterRollService,
+		DelegationService:        delegationService,
+		RunoffService:            runoffService,
+		NominationService:        nominationService,
+		LiveResultsService:       liveResultsService,
+		CollaboratorService:      collaboratorService,
 	}
 }
 
 // initHandlers initializes all handlers with their required services
-func initHandlers(services *Services) *Handler {
+func initHandlers(services *Services, cfg *config.Config) *Handler {
 	formHandler := handler.NewFormHandler(
 		services.FormService,
 		services.FormSubmissionService,
@@ -107,14 +289,38 @@ func initHandlers(services *Services) *Handler {
 		services.AuthService,
 		services.DashboardService,
 	)
-	authHandler := handler.NewAuthHandler(services.AuthService)
+	authHandler := handler.NewAuthHandler(services.AuthService, cfg.AuthCookie)
 	dashboardHandler := handler.NewDashboardHandler(services.DashboardService)
 	draftHandler := handler.NewDraftHandler(services.DraftService, services.DashboardService)
+	oidcHandler := handler.NewOIDCHandler(services.OIDCService, cfg.FrontendURL, cfg.AuthCookie)
+	apiTokenHandler := handler.NewAPITokenHandler(services.APITokenService)
+	adminHandler := handler.NewAdminHandler(services.AdminService)
+	accountHandler := handler.NewAccountHandler(services.AccountService)
+	resultsHandler := handler.NewResultsHandler(
+		services.ResultsService,
+		services.OutcomeService,
+		services.LiveResultsService,
+	)
+	voterRollHandler := handler.NewVoterRollHandler(services.VoterRollService)
+	delegationHandler := handler.NewDelegationHandler(services.DelegationService)
+	runoffHandler := handler.NewRunoffHandler(services.RunoffService)
+	nominationHandler := handler.NewNominationHandler(services.NominationService)
+	collaboratorHandler := handler.NewCollaboratorHandler(services.CollaboratorService)
 
 	return &Handler{
-		FormHandler:      formHandler,
-		AuthHandler:      authHandler,
-		DashboardHandler: dashboardHandler,
-		DraftHandler:     draftHandler,
+		FormHandler:         formHandler,
+		AuthHandler:         authHandler,
+		DashboardHandler:    dashboardHandler,
+		DraftHandler:        draftHandler,
+		OIDCHandler:         oidcHandler,
+		APITokenHandler:     apiTokenHandler,
+		AdminHandler:        adminHandler,
+		AccountHandler:      accountHandler,
+		ResultsHandler:      resultsHandler,
+		VoterRollHandler:    voterRollHandler,
+		DelegationHandler:   delegationHandler,
+		RunoffHandler:       runoffHandler,
+		NominationHandler:   nominationHandler,
+		CollaboratorHandler: collaboratorHandler,
 	}
 }
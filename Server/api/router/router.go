@@ -1,34 +1,88 @@
 package router
 
 import (
+	"log/slog"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/luneto10/voting-system/api/handler"
+	"github.com/luneto10/voting-system/api/middleware"
 	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/health"
+	"github.com/luneto10/voting-system/internal/metrics"
+	"github.com/luneto10/voting-system/internal/pubsub"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"gorm.io/gorm"
 )
 
-func Initialize(db *gorm.DB) {
-	router := gin.Default()
+// resultsStreamPath is the route of the live results stream, which stays open
+// for as long as the client watches.
+const resultsStreamPath = "/api/v1/forms/:id/results/stream"
+
+// Initialize builds the HTTP handler with all middleware and routes. Serving
+// it is left to the caller so it can control the server lifecycle, including
+// closing the broker that feeds live results and running background jobs on
+// the returned services.
+func Initialize(db *gorm.DB, cfg *config.Config, logger *slog.Logger, checker *health.Checker, broker pubsub.Broker) (*gin.Engine, *Services) {
+	router := gin.New()
+	// Client IPs decide login throttling, so forwarding headers are only
+	// believed from configured proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Warn("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		// Probes would drown out real traffic
+		switch c.FullPath() {
+		case "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	router.Use(middleware.RequestLoggerMiddleware(logger))
+	router.Use(gin.Recovery())
+
+	if cfg.Metrics.Enabled {
+		router.Use(middleware.MetricsMiddleware())
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		panic(err)
+		if sqlDB, err := db.DB(); err == nil {
+			if err := metrics.RegisterDBStats(sqlDB); err != nil {
+				logger.Warn("Failed to register database metrics", "error", err)
+			}
+		}
 	}
 
+	// Probes are registered before the remaining middleware so they are not
+	// subject to CORS, body limits or request timeouts
+	healthHandler := handler.NewHealthHandler(checker)
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+
 	router.Use(cors.New(cors.Config{
 		AllowOrigins: []string{cfg.FrontendURL},
 		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders: []string{
 			"Origin", "Content-Type", "Accept", "Authorization", "Cookie",
-			"X-Requested-With", "X-CSRF-Token",
+			"X-Requested-With", "X-CSRF-Token", "X-Request-ID",
 		},
+		ExposeHeaders:    []string{"X-Request-ID", "Retry-After"},
 		AllowCredentials: true,
 	}))
 
-	handlers := initDependencies(db)
+	// Errors are rendered inside the logging and metrics middleware so both
+	// see the final status code
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.BodyLimitMiddleware(cfg.Server.MaxBodyBytes))
+	router.Use(middleware.TimeoutMiddleware(cfg.Server.RequestTimeout, resultsStreamPath))
+
+	handlers, services := initDependencies(db, cfg, broker)
+
+	if cfg.AuthCookie.Enabled {
+		router.Use(middleware.CSRFMiddleware(cfg.AuthCookie))
+	}
 
-	initializeRoutes(router, handlers)
+	initializeRoutes(router, handlers, services)
 
-	router.Run("0.0.0.0:8080")
+	return router, services
 }
@@ -3,47 +3,137 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/luneto10/voting-system/api/middleware"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/helper/auth"
 )
 
-func initializeRoutes(router *gin.Engine, handlers *Handler) {
+func initializeRoutes(router *gin.Engine, handlers *Handler, services *Services) {
 	basePath := "/api/v1"
 
+	authenticated := middleware.AuthMiddleware(services.APITokenService, services.AuthService)
+
 	v1 := router.Group(basePath)
 	{
-		form := v1.Group("/forms")
+		form := v1.Group("/forms", authenticated)
 		{
-			form.GET("/:id", middleware.AuthMiddleware(), handlers.FormHandler.GetForm)
-			form.GET("/:id/public", middleware.AuthMiddleware(), handlers.FormHandler.GetPublicForm)
-			form.POST("", middleware.AuthMiddleware(), handlers.FormHandler.CreateForm)
-			form.PUT("/:id", middleware.AuthMiddleware(), handlers.FormHandler.UpdateForm)
-			form.DELETE("/:id", middleware.AuthMiddleware(), handlers.FormHandler.DeleteForm)
-			form.GET("/user", middleware.AuthMiddleware(), handlers.FormHandler.GetUserForms)
-			form.POST("/:id/submit", middleware.AuthMiddleware(), handlers.FormHandler.SubmitForm)
-			form.GET("/:id/hasvoted", middleware.AuthMiddleware(), handlers.FormHandler.UserSubmittedForm)
-			form.GET("/:id/voters", middleware.AuthMiddleware(), handlers.FormHandler.GetFormVoters)
+			formsRead := form.Group("", middleware.RequireScope(auth.ScopeFormsRead))
+			{
+				formsRead.GET("/:id", handlers.FormHandler.GetForm)
+				formsRead.GET("/:id/public", handlers.FormHandler.GetPublicForm)
+				formsRead.GET("/user", handlers.FormHandler.GetUserForms)
+				formsRead.GET("/shared", handlers.CollaboratorHandler.GetSharedForms)
+				formsRead.GET("/:id/hasvoted", handlers.FormHandler.UserSubmittedForm)
+				formsRead.GET("/:id/roll", handlers.VoterRollHandler.GetVoterRoll)
+				formsRead.GET("/:id/delegation", handlers.DelegationHandler.GetDelegation)
+				formsRead.GET("/:id/nominations", handlers.NominationHandler.GetNominations)
+				formsRead.GET("/:id/results/visibility", handlers.ResultsHandler.GetResultsVisibility)
+				formsRead.GET("/:id/collaborators", handlers.CollaboratorHandler.GetCollaborators)
+			}
+
+			formsWrite := form.Group("", middleware.RequireScope(auth.ScopeFormsWrite))
+			{
+				formsWrite.POST("", handlers.FormHandler.CreateForm)
+				formsWrite.PUT("/:id", handlers.FormHandler.UpdateForm)
+				formsWrite.DELETE("/:id", handlers.FormHandler.DeleteForm)
+				formsWrite.PUT("/:id/roll", handlers.VoterRollHandler.SetVoterRoll)
+				formsWrite.PUT("/:id/questions/:question_id/tie-break", handlers.ResultsHandler.DecideTie)
+				formsWrite.POST("/:id/runoff", handlers.RunoffHandler.CreateRunoff)
+				formsWrite.PUT("/:id/nominations/:nomination_id", handlers.NominationHandler.ModerateNomination)
+				formsWrite.PUT("/:id/results/visibility", handlers.ResultsHandler.SetResultsVisibility)
+				formsWrite.POST("/:id/results/share-link", handlers.ResultsHandler.RotateResultsLink)
+				formsWrite.POST("/:id/collaborators", handlers.CollaboratorHandler.InviteCollaborator)
+				formsWrite.PUT("/:id/collaborators/:user_id", handlers.CollaboratorHandler.UpdateCollaborator)
+				formsWrite.DELETE("/:id/collaborators/:user_id", handlers.CollaboratorHandler.RemoveCollaborator)
+				formsWrite.POST("/:id/transfer", handlers.CollaboratorHandler.TransferOwnership)
+			}
+
+			submissionsWrite := form.Group("", middleware.RequireScope(auth.ScopeSubmissionsWrite))
+			{
+				submissionsWrite.POST("/:id/submit", handlers.FormHandler.SubmitForm)
+				submissionsWrite.PUT("/:id/delegation", handlers.DelegationHandler.Delegate)
+				submissionsWrite.DELETE("/:id/delegation", handlers.DelegationHandler.RevokeDelegation)
+				submissionsWrite.POST("/:id/nominations", handlers.NominationHandler.Nominate)
+				submissionsWrite.POST("/:id/nominations/:nomination_id/support", handlers.NominationHandler.SupportNomination)
+			}
+
+			resultsRead := form.Group("", middleware.RequireScope(auth.ScopeResultsRead))
+			{
+				resultsRead.GET("/:id/voters", handlers.FormHandler.GetFormVoters)
+				resultsRead.GET("/:id/results", handlers.ResultsHandler.GetFormResults)
+				resultsRead.POST("/:id/results/stream-token", handlers.ResultsHandler.IssueStreamToken)
+				resultsRead.GET("/:id/ballots", handlers.ResultsHandler.ExportBallots)
+				resultsRead.GET("/:id/outcome", handlers.ResultsHandler.GetFormOutcome)
+				resultsRead.GET("/:id/weights", handlers.ResultsHandler.GetWeightAudit)
+			}
 		}
 
+		// Browsers open the stream with a token in the URL instead of a header
+		v1.GET("/forms/:id/results/stream",
+			middleware.StreamAuthMiddleware(services.APITokenService, services.AuthService),
+			middleware.RequireScope(auth.ScopeResultsRead),
+			handlers.ResultsHandler.StreamResults)
+
+		v1.GET("/shared/results/:token", handlers.ResultsHandler.GetSharedResults)
+
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/register", handlers.AuthHandler.Register)
 			auth.POST("/login", handlers.AuthHandler.Login)
 			auth.POST("/refresh", handlers.AuthHandler.RefreshToken)
 			auth.POST("/logout", handlers.AuthHandler.Logout)
+			auth.POST("/unlock", authenticated, middleware.RequireSession(), handlers.AuthHandler.Unlock)
+			auth.PUT("/password", authenticated, middleware.RequireSession(), handlers.AuthHandler.ChangePassword)
+			auth.GET("/oidc/login", handlers.OIDCHandler.Login)
+			auth.GET("/oidc/callback", handlers.OIDCHandler.Callback)
+		}
+
+		users := v1.Group("/users")
+		{
+			users.POST("/email/verify", handlers.AccountHandler.VerifyEmailChange)
+
+			me := users.Group("/me", authenticated, middleware.RequireSession())
+			{
+				me.GET("", handlers.AccountHandler.GetProfile)
+				me.PATCH("", handlers.AccountHandler.UpdateProfile)
+				me.POST("/reauthenticate", handlers.AccountHandler.RequestReauthentication)
+				me.POST("/email", handlers.AccountHandler.RequestEmailChange)
+				me.GET("/export", handlers.AccountHandler.ExportData)
+				me.DELETE("", handlers.AccountHandler.DeleteAccount)
+			}
+		}
+
+		tokens := v1.Group("/tokens", authenticated, middleware.RequireSession())
+		{
+			tokens.GET("", handlers.APITokenHandler.ListTokens)
+			tokens.POST("", handlers.APITokenHandler.CreateToken)
+			tokens.DELETE("/:id", handlers.APITokenHandler.RevokeToken)
+		}
+
+		dashboard := v1.Group("/dashboard", authenticated, middleware.RequireSession())
+		{
+			dashboard.GET("", handlers.DashboardHandler.GetDashboard)
+			dashboard.PUT("/forms/:formId/status/:status", handlers.DashboardHandler.UpdateFormStatus)
+			dashboard.DELETE("/forms/:formId/status", handlers.DashboardHandler.DeleteFormParticipation)
+			dashboard.GET("/activities", handlers.DashboardHandler.GetUserActivities)
 		}
 
-		dashboard := v1.Group("/dashboard")
+		drafts := v1.Group("/drafts", authenticated, middleware.RequireSession())
 		{
-			dashboard.GET("", middleware.AuthMiddleware(), handlers.DashboardHandler.GetDashboard)
-			dashboard.PUT("/forms/:formId/status/:status", middleware.AuthMiddleware(), handlers.DashboardHandler.UpdateFormStatus)
-			dashboard.DELETE("/forms/:formId/status", middleware.AuthMiddleware(), handlers.DashboardHandler.DeleteFormParticipation)
-			dashboard.GET("/activities", middleware.AuthMiddleware(), handlers.DashboardHandler.GetUserActivities)
+			drafts.POST("", handlers.DraftHandler.SaveDraft)
+			drafts.GET("/:formId", handlers.DraftHandler.GetDraft)
+			drafts.DELETE("/:formId", handlers.DraftHandler.DeleteDraft)
 		}
 
-		drafts := v1.Group("/drafts")
+		admin := v1.Group("/admin", authenticated, middleware.RequireSession(), middleware.RequireRole(model.UserRoleAdmin))
 		{
-			drafts.POST("", middleware.AuthMiddleware(), handlers.DraftHandler.SaveDraft)
-			drafts.GET("/:formId", middleware.AuthMiddleware(), handlers.DraftHandler.GetDraft)
-			drafts.DELETE("/:formId", middleware.AuthMiddleware(), handlers.DraftHandler.DeleteDraft)
+			admin.GET("/users", handlers.AdminHandler.SearchUsers)
+			admin.PATCH("/users/:id/status", handlers.AdminHandler.UpdateUserStatus)
+			admin.PATCH("/users/:id/role", handlers.AdminHandler.UpdateUserRole)
+			admin.POST("/users/:id/unlock", handlers.AdminHandler.UnlockUser)
+			admin.GET("/forms", handlers.AdminHandler.SearchForms)
+			admin.GET("/forms/:id", handlers.AdminHandler.GetForm)
+			admin.DELETE("/forms/:id", handlers.AdminHandler.TakeDownForm)
+			admin.GET("/audit-logs", handlers.AdminHandler.GetAuditLogs)
 		}
 	}
 }
@@ -2,16 +2,87 @@ package config
 
 import (
 	"fmt"
+	"time"
 )
 
 // Config holds all configuration values for the application.
 type Config struct {
+	Server      ServerConfig
 	DB          DBConfig
 	Log         LogConfig
 	JWT         JWTConfig
+	OIDC        OIDCConfig
+	Login       LoginProtectionConfig
+	Password    PasswordPolicyConfig
+	AuthCookie  AuthCookieConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
+	Outcome     OutcomeConfig
 	FrontendURL string
 }
 
+// OutcomeConfig controls how often the outcomes of forms whose voting closed
+// are stored. Zero stops the background job, leaving each outcome to be
+// stored the first time it is requested.
+type OutcomeConfig struct {
+	FreezeInterval time.Duration
+}
+
+// TracingConfig controls OpenTelemetry tracing. Spans are exported over OTLP
+// HTTP to Endpoint (host:port of a collector). SampleRatio is the fraction of
+// new traces that are recorded; incoming sampled traces are always followed.
+type TracingConfig struct {
+	Enabled     bool
+	ServiceName string
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
+// MetricsConfig controls the Prometheus endpoint. It is served on its own
+// listener at Address, apart from the public API.
+type MetricsConfig struct {
+	Enabled bool
+	Address string
+	Path    string
+}
+
+// ServerConfig holds the HTTP server settings. TLS is served when both
+// TLSCertFile and TLSKeyFile are set.
+type ServerConfig struct {
+	Address           string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+	TLSCertFile       string
+	TLSKeyFile        string
+
+	// ShutdownTimeout is how long in-flight requests may take to finish once
+	// a termination signal is received.
+	ShutdownTimeout time.Duration
+
+	// ShutdownDelay keeps serving with readiness failing for this long before
+	// shutting down, giving load balancers time to stop routing traffic here.
+	ShutdownDelay time.Duration
+
+	// RequestTimeout bounds how long a single API request may spend in the
+	// handler chain, including all of its database queries. Zero disables it.
+	RequestTimeout time.Duration
+
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is believed when finding a client's IP.
+	// Empty trusts none, so the connection's address is used.
+	TrustedProxies []string
+}
+
+// TLSEnabled reports whether the server should serve HTTPS.
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
 // DBConfig holds database configuration values.
 type DBConfig struct {
 	Host     string
@@ -19,6 +90,13 @@ type DBConfig struct {
 	User     string
 	Password string
 	DBName   string
+
+	// QueryTimeout is enforced by Postgres as statement_timeout on every
+	// connection. Zero disables it.
+	QueryTimeout time.Duration
+
+	// SlowQueryThreshold logs queries taking longer than this as warnings.
+	SlowQueryThreshold time.Duration
 }
 
 // LogConfig holds logging configuration values.
@@ -27,10 +105,58 @@ type LogConfig struct {
 	Format string
 }
 
+// JWTConfig holds the secret that login sessions, share links and other
+// signed tokens are signed with.
 type JWTConfig struct {
 	SecretKey string
 }
 
+// OIDCConfig holds the OpenID Connect single sign-on configuration.
+type OIDCConfig struct {
+	Enabled      bool
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// LoginProtectionConfig controls throttling of failed login attempts. Each
+// failure delays the next attempt exponentially from BaseDelay up to MaxDelay,
+// and reaching the failure limit locks the account or IP for LockoutDuration.
+type LoginProtectionConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	FailureWindow      time.Duration
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	LockoutDuration    time.Duration
+}
+
+// PasswordPolicyConfig describes the rules new passwords must satisfy.
+// BreachedHashesFile optionally points to a list of SHA-1 hashes of known
+// breached passwords, one per line (the "HASH:count" format is accepted).
+type PasswordPolicyConfig struct {
+	MinLength          int
+	RequireUpper       bool
+	RequireLower       bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	BreachedHashesFile string
+}
+
+// AuthCookieConfig enables keeping the refresh token in an HttpOnly cookie
+// instead of the JSON body. Cookie authenticated requests must then carry a
+// CSRF token matching the CSRF cookie in the X-CSRF-Token header.
+type AuthCookieConfig struct {
+	Enabled           bool
+	RefreshCookieName string
+	CSRFCookieName    string
+	Domain            string
+	Secure            bool
+	SameSite          string // strict, lax or none
+}
+
 // LoadConfig loads configuration from environment variables.
 func LoadConfig() (*Config, error) {
 	if err := LoadEnv(); err != nil {
@@ -38,17 +164,85 @@ func LoadConfig() (*Config, error) {
 	}
 
 	cfg := &Config{
+		Server: ServerConfig{
+			Address:           getEnv("SERVER_ADDRESS", "0.0.0.0:8080"),
+			ReadTimeout:       getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       getEnvDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+			MaxHeaderBytes:    getEnvInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+			MaxBodyBytes:      int64(getEnvInt("SERVER_MAX_BODY_BYTES", 4<<20)),
+			TLSCertFile:       getEnv("SERVER_TLS_CERT_FILE", ""),
+			TLSKeyFile:        getEnv("SERVER_TLS_KEY_FILE", ""),
+			ShutdownTimeout:   getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 20*time.Second),
+			ShutdownDelay:     getEnvDuration("SERVER_SHUTDOWN_DELAY", 0),
+			RequestTimeout:    getEnvDuration("REQUEST_TIMEOUT", 15*time.Second),
+			TrustedProxies:    getEnvList("SERVER_TRUSTED_PROXIES", nil),
+		},
 		DB: DBConfig{
 			Host:     getEnv("POSTGRES_HOST", "localhost"),
 			Port:     getEnv("POSTGRES_PORT", "5432"),
 			User:     getEnv("POSTGRES_USER", "postgres"),
 			Password: getEnv("POSTGRES_PASSWORD", "postgres"),
 			DBName:   getEnv("POSTGRES_DB", "go_orm_db"),
+
+			QueryTimeout:       getEnvDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+			SlowQueryThreshold: getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
 		},
 		Log: LogConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "text"),
 		},
+		OIDC: OIDCConfig{
+			Enabled:      getEnvBool("OIDC_ENABLED", false),
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:       getEnvList("OIDC_SCOPES", []string{"openid", "email", "profile"}),
+		},
+		Login: LoginProtectionConfig{
+			MaxAccountFailures: getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getEnvInt("LOGIN_MAX_IP_FAILURES", 20),
+			FailureWindow:      getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			BaseDelay:          getEnvDuration("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:           getEnvDuration("LOGIN_MAX_DELAY", 30*time.Second),
+			LockoutDuration:    getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:          getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			RequireUpper:       getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:       getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+			RequireDigit:       getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:      getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			BreachedHashesFile: getEnv("PASSWORD_BREACHED_HASHES_FILE", ""),
+		},
+		AuthCookie: AuthCookieConfig{
+			Enabled:           getEnvBool("AUTH_COOKIE_ENABLED", false),
+			RefreshCookieName: getEnv("AUTH_COOKIE_REFRESH_NAME", "refresh_token"),
+			CSRFCookieName:    getEnv("AUTH_COOKIE_CSRF_NAME", "csrf_token"),
+			Domain:            getEnv("AUTH_COOKIE_DOMAIN", ""),
+			Secure:            getEnvBool("AUTH_COOKIE_SECURE", true),
+			SameSite:          getEnv("AUTH_COOKIE_SAMESITE", "strict"),
+		},
+		JWT: JWTConfig{
+			SecretKey: getEnv("JWT_SECRET_KEY", ""),
+		},
+		Metrics: MetricsConfig{
+			Enabled: getEnvBool("METRICS_ENABLED", true),
+			Address: getEnv("METRICS_ADDRESS", "127.0.0.1:9090"),
+			Path:    getEnv("METRICS_PATH", "/metrics"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     getEnvBool("TRACING_ENABLED", false),
+			ServiceName: getEnv("TRACING_SERVICE_NAME", "voting-system"),
+			Endpoint:    getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBool("TRACING_OTLP_INSECURE", true),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+		Outcome: OutcomeConfig{
+			FreezeInterval: getEnvDuration("OUTCOME_FREEZE_INTERVAL", time.Minute),
+		},
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
 	}
 
@@ -0,0 +1,100 @@
+// Package apperr defines the error type returned by services. Each error has a
+// stable machine-readable code clients can rely on, the HTTP status it maps
+// to, a human readable message and optional per-field details.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// FieldError describes a problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Fields  []FieldError
+
+	// RetryAfter, when set, tells the client how long to wait before retrying.
+	RetryAfter time.Duration
+
+	cause error
+}
+
+// New defines an error. Services declare them once as package level values and
+// derive request specific copies with the With* methods.
+func New(status int, code, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors by code, so copies made with the With* methods still
+// match the error they were derived from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with a more specific message.
+func (e *Error) WithMessage(message string) *Error {
+	copied := *e
+	copied.Message = message
+	return &copied
+}
+
+// WithFields returns a copy of e carrying field level details.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &copied
+}
+
+// WithRetryAfter returns a copy of e asking the client to retry after d.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	copied := *e
+	copied.RetryAfter = d
+	return &copied
+}
+
+// Wrap returns a copy of e that records cause for errors.Is and errors.As.
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Generic errors used when no more specific one applies.
+var (
+	ErrBadRequest     = New(http.StatusBadRequest, "bad_request", "bad request")
+	ErrUnauthorized   = New(http.StatusUnauthorized, "unauthorized", "authentication required")
+	ErrForbidden      = New(http.StatusForbidden, "forbidden", "forbidden")
+	ErrNotFound       = New(http.StatusNotFound, "not_found", "not found")
+	ErrValidation     = New(http.StatusUnprocessableEntity, "validation_failed", "request validation failed")
+	ErrBodyTooLarge   = New(http.StatusRequestEntityTooLarge, "body_too_large", "request body is too large")
+	ErrInternal       = New(http.StatusInternalServerError, "internal_error", "internal server error")
+	ErrRequestTimeout = New(http.StatusGatewayTimeout, "request_timeout", "request timed out")
+	ErrQueryTimeout   = New(http.StatusServiceUnavailable, "query_timeout", "database query timed out, try again later")
+	ErrClientClosed   = New(499, "client_closed_request", "client closed the request")
+)
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryCanceledCode is the SQLSTATE Postgres reports when a statement is
+// cancelled, which is how statement_timeout surfaces.
+const queryCanceledCode = "57014"
+
+// uniqueViolationCode is the SQLSTATE of a write rejected by a unique index.
+const uniqueViolationCode = "23505"
+
+// IsQueryTimeout reports whether err was caused by the database cancelling a
+// statement that ran longer than the configured query timeout.
+func IsQueryTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == queryCanceledCode
+}
+
+// IsUniqueViolation reports whether err was caused by a write that a unique
+// index rejected, such as a row inserted concurrently with the same key.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
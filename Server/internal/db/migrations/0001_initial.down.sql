@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS "answer_options";
+DROP TABLE IF EXISTS "question_options";
+DROP TABLE IF EXISTS "login_throttles";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "api_tokens";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "user_form_participations";
+DROP TABLE IF EXISTS "draft_submissions";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "answers";
+DROP TABLE IF EXISTS "submissions";
+DROP TABLE IF EXISTS "options";
+DROP TABLE IF EXISTS "questions";
+DROP TABLE IF EXISTS "forms";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,59 @@
+-- Initial schema, matching the models previously created by AutoMigrate.
+
+CREATE TABLE "users" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"email" text NOT NULL,"password" text NOT NULL,"role" text NOT NULL DEFAULT 'user',"disabled" boolean NOT NULL DEFAULT false,"display_name" text,"timezone" text NOT NULL DEFAULT 'UTC',"pending_email" text,"email_verification_hash" text,"email_verification_expires_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "uni_users_email" UNIQUE ("email"));
+CREATE INDEX "idx_users_email_verification_hash" ON "users" ("email_verification_hash");
+CREATE INDEX "idx_users_email" ON "users" ("email");
+CREATE INDEX "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE "forms" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"title" text NOT NULL,"description" text,"start_at" timestamptz DEFAULT null,"end_at" timestamptz DEFAULT null,"user_id" bigint NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "fk_users_forms" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_forms_user_id" ON "forms" ("user_id");
+CREATE INDEX "idx_forms_deleted_at" ON "forms" ("deleted_at");
+
+CREATE TABLE "questions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"title" text NOT NULL,"type" text NOT NULL,"form_id" bigint NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "fk_forms_questions" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_questions_form_id" ON "questions" ("form_id");
+CREATE INDEX "idx_questions_deleted_at" ON "questions" ("deleted_at");
+
+CREATE TABLE "options" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"title" text NOT NULL,PRIMARY KEY ("id"));
+CREATE INDEX "idx_options_deleted_at" ON "options" ("deleted_at");
+
+CREATE TABLE "submissions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"user_id" bigint NOT NULL,"form_id" bigint NOT NULL,"completed_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_submissions_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_users_submissions" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_submissions_form_id" ON "submissions" ("form_id");
+CREATE INDEX "idx_submissions_user_id" ON "submissions" ("user_id");
+CREATE INDEX "idx_submissions_deleted_at" ON "submissions" ("deleted_at");
+
+CREATE TABLE "answers" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"submission_id" bigint NOT NULL,"question_id" bigint NOT NULL,"text" text,PRIMARY KEY ("id"),CONSTRAINT "fk_answers_question" FOREIGN KEY ("question_id") REFERENCES "questions"("id") ON DELETE SET NULL ON UPDATE CASCADE,CONSTRAINT "fk_submissions_answers" FOREIGN KEY ("submission_id") REFERENCES "submissions"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_answers_question_id" ON "answers" ("question_id");
+CREATE INDEX "idx_answers_submission_id" ON "answers" ("submission_id");
+CREATE INDEX "idx_answers_deleted_at" ON "answers" ("deleted_at");
+
+CREATE TABLE "refresh_tokens" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"token" text NOT NULL,"user_id" bigint NOT NULL,"expires_at" timestamptz NOT NULL,"revoked" boolean DEFAULT false,PRIMARY KEY ("id"),CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),CONSTRAINT "uni_refresh_tokens_token" UNIQUE ("token"));
+CREATE INDEX "idx_refresh_tokens_deleted_at" ON "refresh_tokens" ("deleted_at");
+
+CREATE TABLE "draft_submissions" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"form_id" bigint NOT NULL,"user_id" bigint NOT NULL,"answers" json,"progress_percentage" decimal DEFAULT 0,PRIMARY KEY ("id"),CONSTRAINT "fk_draft_submissions_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id"),CONSTRAINT "fk_draft_submissions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+CREATE INDEX "idx_draft_submissions_deleted_at" ON "draft_submissions" ("deleted_at");
+
+CREATE TABLE "user_form_participations" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"form_id" bigint NOT NULL,"user_id" bigint NOT NULL,"status" text DEFAULT 'available',"started_at" timestamptz,"completed_at" timestamptz,"last_modified" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_user_form_participations_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id"),CONSTRAINT "fk_user_form_participations_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+CREATE INDEX "idx_user_form_participations_deleted_at" ON "user_form_participations" ("deleted_at");
+
+CREATE TABLE "user_identities" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"user_id" bigint NOT NULL,"provider" text NOT NULL,"subject" text NOT NULL,"email" text NOT NULL,PRIMARY KEY ("id"),CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX "idx_identity_provider_subject" ON "user_identities" ("provider","subject");
+CREATE INDEX "idx_user_identities_user_id" ON "user_identities" ("user_id");
+CREATE INDEX "idx_user_identities_deleted_at" ON "user_identities" ("deleted_at");
+
+CREATE TABLE "api_tokens" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"user_id" bigint NOT NULL,"name" text NOT NULL,"prefix" text NOT NULL,"token_hash" text NOT NULL,"scopes" text NOT NULL,"expires_at" timestamptz,"last_used_at" timestamptz,"revoked" boolean DEFAULT false,PRIMARY KEY ("id"),CONSTRAINT "fk_api_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX "idx_api_tokens_token_hash" ON "api_tokens" ("token_hash");
+CREATE INDEX "idx_api_tokens_user_id" ON "api_tokens" ("user_id");
+CREATE INDEX "idx_api_tokens_deleted_at" ON "api_tokens" ("deleted_at");
+
+CREATE TABLE "audit_logs" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"actor_id" bigint NOT NULL,"action" text NOT NULL,"target_type" text NOT NULL,"target_id" bigint NOT NULL,"details" json,"ip_address" text,PRIMARY KEY ("id"),CONSTRAINT "fk_audit_logs_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("id"));
+CREATE INDEX "idx_audit_logs_action" ON "audit_logs" ("action");
+CREATE INDEX "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+CREATE INDEX "idx_audit_logs_deleted_at" ON "audit_logs" ("deleted_at");
+
+CREATE TABLE "login_throttles" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"key" text NOT NULL,"failures" bigint NOT NULL DEFAULT 0,"last_failure_at" timestamptz NOT NULL,"locked_until" timestamptz,PRIMARY KEY ("id"));
+CREATE UNIQUE INDEX "idx_login_throttles_key" ON "login_throttles" ("key");
+CREATE INDEX "idx_login_throttles_deleted_at" ON "login_throttles" ("deleted_at");
+
+CREATE TABLE "question_options" ("question_id" bigint,"option_id" bigint,PRIMARY KEY ("question_id","option_id"),CONSTRAINT "fk_question_options_question" FOREIGN KEY ("question_id") REFERENCES "questions"("id"),CONSTRAINT "fk_question_options_option" FOREIGN KEY ("option_id") REFERENCES "options"("id"));
+
+CREATE TABLE "answer_options" ("answer_id" bigint,"option_id" bigint,PRIMARY KEY ("answer_id","option_id"),CONSTRAINT "fk_answer_options_answer" FOREIGN KEY ("answer_id") REFERENCES "answers"("id"),CONSTRAINT "fk_answer_options_option" FOREIGN KEY ("option_id") REFERENCES "options"("id"));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "answer_preferences";
+
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "max_score";
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "tally_method";
@@ -0,0 +1,11 @@
+-- Per-question tally methods, with ranks and scores of ranked and score ballots.
+
+ALTER TABLE "questions" ADD COLUMN "tally_method" text NOT NULL DEFAULT '';
+ALTER TABLE "questions" ADD COLUMN "max_score" bigint NOT NULL DEFAULT 0;
+
+-- Existing choice questions keep counting the way they always have
+UPDATE "questions" SET "tally_method" = 'plurality' WHERE "type" = 'single_choice';
+UPDATE "questions" SET "tally_method" = 'approval' WHERE "type" = 'multiple_choice';
+
+CREATE TABLE "answer_preferences" ("id" bigserial,"answer_id" bigint NOT NULL,"option_id" bigint NOT NULL,"rank" bigint NOT NULL DEFAULT 0,"score" bigint NOT NULL DEFAULT 0,PRIMARY KEY ("id"),CONSTRAINT "fk_answer_preferences_option" FOREIGN KEY ("option_id") REFERENCES "options"("id"),CONSTRAINT "fk_answers_preferences" FOREIGN KEY ("answer_id") REFERENCES "answers"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_answer_preferences_answer_id" ON "answer_preferences" ("answer_id");
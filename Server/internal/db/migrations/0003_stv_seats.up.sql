@@ -0,0 +1,3 @@
+-- Number of seats filled by multi-winner STV questions.
+
+ALTER TABLE "questions" ADD COLUMN "seats" bigint NOT NULL DEFAULT 0;
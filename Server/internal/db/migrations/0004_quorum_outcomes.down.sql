@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "form_outcomes";
+DROP TABLE IF EXISTS "eligible_voters";
+
+ALTER TABLE "answers" DROP COLUMN IF EXISTS "abstain";
+
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "count_abstentions";
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "pass_threshold";
+
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "quorum";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "quorum_type";
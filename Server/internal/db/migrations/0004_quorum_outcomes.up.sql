@@ -0,0 +1,16 @@
+-- Quorums, pass thresholds, abstentions, voter rolls and frozen form outcomes.
+
+ALTER TABLE "forms" ADD COLUMN "quorum_type" text NOT NULL DEFAULT '';
+ALTER TABLE "forms" ADD COLUMN "quorum" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "questions" ADD COLUMN "pass_threshold" text NOT NULL DEFAULT '';
+ALTER TABLE "questions" ADD COLUMN "count_abstentions" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "answers" ADD COLUMN "abstain" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "eligible_voters" ("id" bigserial,"form_id" bigint NOT NULL,"user_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_eligible_voters_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_eligible_voters_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_eligible_voters_user_id" ON "eligible_voters" ("user_id");
+CREATE UNIQUE INDEX "idx_eligible_voters_form_user" ON "eligible_voters" ("form_id","user_id");
+
+CREATE TABLE "form_outcomes" ("id" bigserial,"form_id" bigint NOT NULL,"closed_at" timestamptz NOT NULL,"quorum_reached" boolean NOT NULL,"report" jsonb NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_form_outcomes_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX "idx_form_outcomes_form_id" ON "form_outcomes" ("form_id");
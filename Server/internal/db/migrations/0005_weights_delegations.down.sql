@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "delegations";
+
+DROP INDEX IF EXISTS "idx_submissions_cast_by_id";
+ALTER TABLE "submissions" DROP CONSTRAINT IF EXISTS "fk_submissions_cast_by";
+ALTER TABLE "submissions" DROP COLUMN IF EXISTS "cast_by_id";
+ALTER TABLE "submissions" DROP COLUMN IF EXISTS "weight";
+
+ALTER TABLE "eligible_voters" DROP COLUMN IF EXISTS "weight";
@@ -0,0 +1,12 @@
+-- Voter weights and proxy delegation.
+
+ALTER TABLE "eligible_voters" ADD COLUMN "weight" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "submissions" ADD COLUMN "weight" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "submissions" ADD COLUMN "cast_by_id" bigint;
+ALTER TABLE "submissions" ADD CONSTRAINT "fk_submissions_cast_by" FOREIGN KEY ("cast_by_id") REFERENCES "users"("id") ON DELETE SET NULL;
+CREATE INDEX "idx_submissions_cast_by_id" ON "submissions" ("cast_by_id");
+
+CREATE TABLE "delegations" ("id" bigserial,"form_id" bigint NOT NULL,"delegator_id" bigint NOT NULL,"delegate_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_delegations_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE,CONSTRAINT "fk_delegations_delegator" FOREIGN KEY ("delegator_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_delegations_delegate" FOREIGN KEY ("delegate_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE INDEX "idx_delegations_delegate_id" ON "delegations" ("delegate_id");
+CREATE UNIQUE INDEX "idx_delegations_form_delegator" ON "delegations" ("form_id","delegator_id");
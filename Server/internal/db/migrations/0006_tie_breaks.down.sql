@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "tie_break_decisions";
+
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "tie_break";
+
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "tie_break_seed_hash";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "tie_break_seed";
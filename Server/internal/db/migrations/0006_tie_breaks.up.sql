@@ -0,0 +1,10 @@
+-- Tie-break policies, committed random seeds and owner tie-break decisions.
+
+ALTER TABLE "forms" ADD COLUMN "tie_break_seed" text NOT NULL DEFAULT '';
+ALTER TABLE "forms" ADD COLUMN "tie_break_seed_hash" text NOT NULL DEFAULT '';
+
+ALTER TABLE "questions" ADD COLUMN "tie_break" text NOT NULL DEFAULT '';
+
+CREATE TABLE "tie_break_decisions" ("id" bigserial,"form_id" bigint NOT NULL,"question_id" bigint NOT NULL,"option_id" bigint NOT NULL,"decided_by_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_tie_break_decisions_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE,CONSTRAINT "fk_tie_break_decisions_question" FOREIGN KEY ("question_id") REFERENCES "questions"("id") ON DELETE CASCADE,CONSTRAINT "fk_tie_break_decisions_decided_by" FOREIGN KEY ("decided_by_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX "idx_tie_break_decisions_question_id" ON "tie_break_decisions" ("question_id");
+CREATE INDEX "idx_tie_break_decisions_form_id" ON "tie_break_decisions" ("form_id");
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_forms_runoff_of_id";
+ALTER TABLE "forms" DROP CONSTRAINT IF EXISTS "fk_forms_runoff_of";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "runoff_of_question_id";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "runoff_of_id";
@@ -0,0 +1,6 @@
+-- Runoff forms linked to the form they follow.
+
+ALTER TABLE "forms" ADD COLUMN "runoff_of_id" bigint;
+ALTER TABLE "forms" ADD COLUMN "runoff_of_question_id" bigint;
+ALTER TABLE "forms" ADD CONSTRAINT "fk_forms_runoff_of" FOREIGN KEY ("runoff_of_id") REFERENCES "forms"("id") ON DELETE SET NULL;
+CREATE INDEX "idx_forms_runoff_of_id" ON "forms" ("runoff_of_id");
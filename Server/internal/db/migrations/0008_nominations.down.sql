@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "nomination_supports";
+DROP TABLE IF EXISTS "nominations";
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "moderate_nominations";
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "nomination_support";
+ALTER TABLE "questions" DROP COLUMN IF EXISTS "accept_nominations";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "nominations_converted_at";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "nomination_end_at";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "nomination_start_at";
@@ -0,0 +1,18 @@
+-- Nomination phases, nominations and their supporters.
+
+ALTER TABLE "forms" ADD COLUMN "nomination_start_at" timestamptz;
+ALTER TABLE "forms" ADD COLUMN "nomination_end_at" timestamptz;
+ALTER TABLE "forms" ADD COLUMN "nominations_converted_at" timestamptz;
+
+ALTER TABLE "questions" ADD COLUMN "accept_nominations" boolean NOT NULL DEFAULT false;
+ALTER TABLE "questions" ADD COLUMN "nomination_support" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "questions" ADD COLUMN "moderate_nominations" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "nominations" ("id" bigserial,"form_id" bigint NOT NULL,"question_id" bigint NOT NULL,"title" text NOT NULL,"nominated_by_id" bigint NOT NULL,"status" text NOT NULL DEFAULT 'pending',"option_id" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_nominations_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE,CONSTRAINT "fk_nominations_question" FOREIGN KEY ("question_id") REFERENCES "questions"("id") ON DELETE CASCADE,CONSTRAINT "fk_nominations_nominated_by" FOREIGN KEY ("nominated_by_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_nominations_option" FOREIGN KEY ("option_id") REFERENCES "options"("id") ON DELETE SET NULL);
+CREATE INDEX "idx_nominations_form_id" ON "nominations" ("form_id");
+CREATE INDEX "idx_nominations_question_id" ON "nominations" ("question_id");
+CREATE INDEX "idx_nominations_nominated_by_id" ON "nominations" ("nominated_by_id");
+CREATE INDEX "idx_nominations_option_id" ON "nominations" ("option_id");
+
+CREATE TABLE "nomination_supports" ("id" bigserial,"nomination_id" bigint NOT NULL,"user_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_nominations_supporters" FOREIGN KEY ("nomination_id") REFERENCES "nominations"("id") ON DELETE CASCADE,CONSTRAINT "fk_nomination_supports_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE);
+CREATE UNIQUE INDEX "idx_nomination_supports_nomination_user" ON "nomination_supports" ("nomination_id","user_id");
@@ -0,0 +1,3 @@
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "results_link_version";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "results_embargo_until";
+ALTER TABLE "forms" DROP COLUMN IF EXISTS "results_visibility";
@@ -0,0 +1,5 @@
+-- Results visibility policies, embargoes and share link versions.
+
+ALTER TABLE "forms" ADD COLUMN "results_visibility" text NOT NULL DEFAULT '';
+ALTER TABLE "forms" ADD COLUMN "results_embargo_until" timestamptz;
+ALTER TABLE "forms" ADD COLUMN "results_link_version" bigint NOT NULL DEFAULT 0;
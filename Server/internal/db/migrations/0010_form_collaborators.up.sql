@@ -0,0 +1,6 @@
+-- Collaborators sharing the work on a form with its owner.
+
+CREATE TABLE "form_collaborators" ("id" bigserial,"form_id" bigint NOT NULL,"user_id" bigint NOT NULL,"role" text NOT NULL,"invited_by_id" bigint,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_form_collaborators_form" FOREIGN KEY ("form_id") REFERENCES "forms"("id") ON DELETE CASCADE,CONSTRAINT "fk_form_collaborators_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,CONSTRAINT "fk_form_collaborators_invited_by" FOREIGN KEY ("invited_by_id") REFERENCES "users"("id") ON DELETE SET NULL);
+CREATE INDEX "idx_form_collaborators_invited_by_id" ON "form_collaborators" ("invited_by_id");
+CREATE INDEX "idx_form_collaborators_user_id" ON "form_collaborators" ("user_id");
+CREATE UNIQUE INDEX "idx_form_collaborators_form_user" ON "form_collaborators" ("form_id","user_id");
@@ -0,0 +1,7 @@
+DELETE FROM "form_outcomes" WHERE NOT "final";
+
+ALTER TABLE "form_outcomes" DROP COLUMN IF EXISTS "decided_at";
+ALTER TABLE "form_outcomes" DROP COLUMN IF EXISTS "quorum_required";
+ALTER TABLE "form_outcomes" DROP COLUMN IF EXISTS "participants";
+ALTER TABLE "form_outcomes" DROP COLUMN IF EXISTS "eligible_voters";
+ALTER TABLE "form_outcomes" DROP COLUMN IF EXISTS "final";
@@ -0,0 +1,15 @@
+-- Outcomes are stored when voting closes, together with the quorum they were
+-- measured against. Outcomes waiting for a tie-break are stored provisional.
+
+ALTER TABLE "form_outcomes" ADD COLUMN "final" boolean NOT NULL DEFAULT true;
+ALTER TABLE "form_outcomes" ADD COLUMN "eligible_voters" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "form_outcomes" ADD COLUMN "participants" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "form_outcomes" ADD COLUMN "quorum_required" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "form_outcomes" ADD COLUMN "decided_at" timestamptz;
+
+-- Outcomes stored so far are final and carry their quorum in the report
+UPDATE "form_outcomes" SET
+	"eligible_voters" = COALESCE(("report"->'quorum'->>'eligible_voters')::bigint, 0),
+	"participants" = COALESCE(("report"->'quorum'->>'participants')::bigint, 0),
+	"quorum_required" = COALESCE(("report"->'quorum'->>'required')::bigint, 0),
+	"decided_at" = "created_at";
@@ -0,0 +1,15 @@
+-- A voter has at most one counted ballot per form. Ballots replaced by a new
+-- vote are soft deleted, so only live rows must be unique.
+
+-- Concurrent submissions may have stored a second ballot before the index
+-- existed; keep the first one cast
+UPDATE "submissions" SET "deleted_at" = NOW()
+WHERE "deleted_at" IS NULL AND EXISTS (
+	SELECT 1 FROM "submissions" AS "earlier"
+	WHERE "earlier"."user_id" = "submissions"."user_id"
+		AND "earlier"."form_id" = "submissions"."form_id"
+		AND "earlier"."deleted_at" IS NULL
+		AND "earlier"."id" < "submissions"."id"
+);
+
+CREATE UNIQUE INDEX "idx_submissions_user_form" ON "submissions" ("user_id","form_id") WHERE "deleted_at" IS NULL;
@@ -3,10 +3,11 @@ package db
 import (
 	"fmt"
 
-	"github.com/luneto10/voting-system/api/model"
 	"github.com/luneto10/voting-system/config"
+	applog "github.com/luneto10/voting-system/internal/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
 type Database interface {
@@ -19,27 +20,34 @@ type PostgresDB struct {
 	db *gorm.DB
 }
 
+// NewPostgresDB wraps an open connection so it can be closed on shutdown.
+func NewPostgresDB(db *gorm.DB) *PostgresDB {
+	return &PostgresDB{db: db}
+}
+
 // NewDB initializes a new database connection using the provided DBConfig.
 func InitializePostgres(cfg config.DBConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName)
+	if cfg.QueryTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.QueryTimeout.Milliseconds())
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: applog.NewGormLogger(cfg.SlowQueryThreshold),
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing postgres: %v", err)
 	}
 
-	db.AutoMigrate(
-		&model.Form{},
-		&model.Question{},
-		&model.Option{},
-		&model.Answer{},
-		&model.User{},
-		&model.Submission{},
-		&model.RefreshToken{},
-		&model.DraftSubmission{},
-		&model.UserFormParticipation{},
-	)
+	// Trace every query with its SQL text; bound values are left out so
+	// passwords and tokens never reach the tracing backend
+	if err := db.Use(gormtracing.NewPlugin(
+		gormtracing.WithoutQueryVariables(),
+		gormtracing.WithoutMetrics(),
+	)); err != nil {
+		return nil, fmt.Errorf("error initializing query tracing: %v", err)
+	}
 
 	return db, nil
 }
@@ -0,0 +1,110 @@
+// Package health reports whether the server is alive and ready for traffic.
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/luneto10/voting-system/internal/db"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+
+	checkTimeout = 2 * time.Second
+)
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	Pending   *int    `json:"pending,omitempty"`
+}
+
+// Report is the response body of the readiness endpoint.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+type Checker struct {
+	db           *gorm.DB
+	migrator     *db.Migrator
+	shuttingDown atomic.Bool
+}
+
+func NewChecker(database *gorm.DB, migrator *db.Migrator) *Checker {
+	return &Checker{db: database, migrator: migrator}
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop sending new
+// requests while in-flight ones drain.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs every readiness check and reports whether all of them passed.
+func (c *Checker) Ready(ctx context.Context) (bool, Report) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: map[string]CheckResult{
+			"database":   c.checkDatabase(ctx),
+			"migrations": c.checkMigrations(ctx),
+			"shutdown":   c.checkShutdown(),
+		},
+	}
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report.Status == StatusOK, report
+}
+
+func (c *Checker) checkDatabase(ctx context.Context) CheckResult {
+	start := time.Now()
+
+	sqlDB, err := c.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (c *Checker) checkMigrations(ctx context.Context) CheckResult {
+	pending, err := c.migrator.WithContext(ctx).Pending()
+	if err != nil {
+		return CheckResult{Status: StatusUnavailable, Error: err.Error()}
+	}
+
+	count := len(pending)
+	result := CheckResult{Status: StatusOK, Pending: &count}
+	if count > 0 {
+		result.Status = StatusUnavailable
+	}
+	return result
+}
+
+func (c *Checker) checkShutdown() CheckResult {
+	if c.shuttingDown.Load() {
+		return CheckResult{Status: StatusUnavailable, Error: "server is shutting down"}
+	}
+	return CheckResult{Status: StatusOK}
+}
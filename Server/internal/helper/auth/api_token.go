@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix marks personal access tokens so they can be told apart from JWTs.
+const APITokenPrefix = "vsp_"
+
+// Scopes that can be granted to personal access tokens. Sessions started with
+// a password or single sign-on implicitly carry all of them.
+const (
+	ScopeFormsRead        = "forms:read"
+	ScopeFormsWrite       = "forms:write"
+	ScopeResultsRead      = "results:read"
+	ScopeSubmissionsWrite = "submissions:write"
+)
+
+var AllScopes = []string{
+	ScopeFormsRead,
+	ScopeFormsWrite,
+	ScopeResultsRead,
+	ScopeSubmissionsWrite,
+}
+
+// GenerateAPIToken returns a new random token together with its hash.
+func GenerateAPIToken() (token string, hash string, err error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", "", err
+	}
+
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(tokenBytes)
+	return token, HashAPIToken(token), nil
+}
+
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
-	"os"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,26 +11,61 @@ import (
 	"github.com/luneto10/voting-system/internal/helper"
 )
 
+var TokenExpired = 15 * time.Minute
+
+// Signing keys, set by SetSecretKey. Every other kind of token is signed with
+// its own key derived from the secret, so a signature made for one purpose is
+// never accepted for another.
 var (
-	SecretKey    = os.Getenv("JWT_SECRET_KEY")
-	TokenExpired = 15 * time.Minute
+	jwtKey         []byte
+	resultsLinkKey []byte
+	oidcStateKey   []byte
+	reauthKey      []byte
+	streamTokenKey []byte
 )
 
+// SetSecretKey sets the keys tokens are signed with from the configured
+// secret. It must be called before any token is signed or checked.
+func SetSecretKey(secret string) error {
+	if secret == "" {
+		return errors.New("the JWT secret key is empty")
+	}
+	jwtKey = []byte(secret)
+
+	var err error
+	if resultsLinkKey, err = deriveKey(secret, "results-link"); err != nil {
+		return err
+	}
+	if oidcStateKey, err = deriveKey(secret, "oidc-state"); err != nil {
+		return err
+	}
+	if reauthKey, err = deriveKey(secret, "reauth"); err != nil {
+		return err
+	}
+	streamTokenKey, err = deriveKey(secret, "results-stream")
+	return err
+}
+
+func deriveKey(secret, purpose string) ([]byte, error) {
+	return hkdf.Key(sha256.New, []byte(secret), nil, "voting-system "+purpose, sha256.Size)
+}
+
 func GenerateJWT(user *model.User) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256,
 		jwt.MapClaims{
 			"iss":   "http://localhost:8080",
 			"sub":   user.ID,
 			"email": user.Email,
+			"role":  user.Role,
 			"exp":   time.Now().Add(TokenExpired).Unix(),
 		})
 
-	return token.SignedString([]byte(SecretKey))
+	return token.SignedString(jwtKey)
 }
 
 func ValidateToken(tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		return []byte(SecretKey), nil
+		return jwtKey, nil
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/internal/helper"
+)
+
+// OIDCStateExpiration is how long a single sign-on login may take from the
+// redirect to the provider to its callback.
+const OIDCStateExpiration = 10 * time.Minute
+
+// SignOIDCState returns the cookie value that carries a single sign-on login
+// between the redirect to the provider and the callback. The payload is
+// signed, not encrypted.
+func SignOIDCState(payload []byte) string {
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + oidcStateSignature(encoded)
+}
+
+// ParseOIDCState checks the signature of a login state cookie and returns its
+// payload.
+func ParseOIDCState(value string) ([]byte, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, helper.ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(oidcStateSignature(encoded))) {
+		return nil, helper.ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, helper.ErrInvalidToken
+	}
+	return payload, nil
+}
+
+func oidcStateSignature(encoded string) string {
+	mac := hmac.New(sha256.New, oidcStateKey)
+	mac.Write([]byte("oidc-state:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/internal/helper"
+)
+
+// ReauthTokenExpiration is how long a confirmation code sent to an account
+// without a password stays valid.
+const ReauthTokenExpiration = 15 * time.Minute
+
+// SignReauthToken returns a confirmation code proving that the holder can
+// read the account's mailbox. The email is part of the signature, so codes
+// stop working once the address changes.
+func SignReauthToken(userID uint, email string, expiresAt time.Time) string {
+	expiry := expiresAt.Unix()
+	return fmt.Sprintf("%d.%s", expiry, reauthSignature(userID, email, expiry))
+}
+
+// VerifyReauthToken checks a confirmation code against the account it should
+// have been signed for.
+func VerifyReauthToken(token string, userID uint, email string, now time.Time) error {
+	expiryPart, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return helper.ErrInvalidToken
+	}
+	expiry, err := strconv.ParseInt(expiryPart, 10, 64)
+	if err != nil || now.Unix() > expiry {
+		return helper.ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(reauthSignature(userID, email, expiry))) {
+		return helper.ErrInvalidToken
+	}
+	return nil
+}
+
+func reauthSignature(userID uint, email string, expiry int64) string {
+	mac := hmac.New(sha256.New, reauthKey)
+	fmt.Fprintf(mac, "reauth:%d:%s:%d", userID, email, expiry)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/luneto10/voting-system/internal/helper"
+)
+
+// SignResultsLink returns the token of a share link to a form's results. The
+// version is part of the signature, so raising the form's link version
+// revokes every token signed before.
+func SignResultsLink(formID uint, version int) string {
+	return fmt.Sprintf("%d.%d.%s", formID, version, resultsLinkSignature(formID, version))
+}
+
+// ParseResultsLink checks the signature of a share link token and returns the
+// form and link version it was signed for.
+func ParseResultsLink(token string) (uint, int, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, helper.ErrInvalidToken
+	}
+	formID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, helper.ErrInvalidToken
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, helper.ErrInvalidToken
+	}
+
+	expected := resultsLinkSignature(uint(formID), version)
+	if !hmac.Equal([]byte(parts[2]), []byte(expected)) {
+		return 0, 0, helper.ErrInvalidToken
+	}
+	return uint(formID), version, nil
+}
+
+func resultsLinkSignature(formID uint, version int) string {
+	mac := hmac.New(sha256.New, resultsLinkKey)
+	fmt.Fprintf(mac, "results-link:%d:%d", formID, version)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/internal/helper"
+)
+
+// StreamTokenExpiration is how long a results stream token can be used to
+// open the stream. The stream stays open past it.
+const StreamTokenExpiration = time.Minute
+
+// SignStreamToken returns a token that lets the user open the results stream
+// of one form, for clients such as a browser's EventSource that cannot send an
+// Authorization header.
+func SignStreamToken(formID uint, userID uint, expiresAt time.Time) string {
+	expiry := expiresAt.Unix()
+	return fmt.Sprintf("%d.%d.%s", userID, expiry, streamTokenSignature(formID, userID, expiry))
+}
+
+// ParseStreamToken checks a results stream token against the form it is used
+// for and returns the user it was signed for.
+func ParseStreamToken(token string, formID uint, now time.Time) (uint, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, helper.ErrInvalidToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, helper.ErrInvalidToken
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expiry {
+		return 0, helper.ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(streamTokenSignature(formID, uint(userID), expiry))) {
+		return 0, helper.ErrInvalidToken
+	}
+	return uint(userID), nil
+}
+
+func streamTokenSignature(formID uint, userID uint, expiry int64) string {
+	mac := hmac.New(sha256.New, streamTokenKey)
+	fmt.Fprintf(mac, "results-stream:%d:%d:%d", formID, userID, expiry)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
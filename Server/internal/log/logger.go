@@ -1,72 +1,61 @@
 package log
 
 import (
+	"context"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/luneto10/voting-system/config"
 )
 
-// Logger wraps the standard log.Logger with additional methods.
-type Logger struct {
-	debug   *log.Logger
-	info    *log.Logger
-	warning *log.Logger
-	err     *log.Logger
-	writer  io.Writer
-}
-
-// NewLogger initializes a new logger using the provided LogConfig.
-func NewLogger(cfg config.LogConfig) *Logger {
-	writer := io.Writer(os.Stdout)
-	logger := log.New(writer, "", log.Ldate|log.Ltime)
-
-	return &Logger{
-		debug:   log.New(writer, "DEBUG: ", logger.Flags()),
-		info:    log.New(writer, "INFO: ", logger.Flags()),
-		warning: log.New(writer, "WARNING: ", logger.Flags()),
-		err:     log.New(writer, "ERROR: ", logger.Flags()),
-		writer:  writer,
-	}
-}
-
-// Debug logs a debug message.
-func (l *Logger) Debug(v ...any) {
-	l.debug.Println(v...)
-}
+type contextKey struct{}
 
-// Info logs an info message.
-func (l *Logger) Info(v ...any) {
-	l.info.Println(v...)
+// NewLogger builds a structured logger from LogConfig. Format is "json" or
+// "text" and Level one of debug, info, warn or error; unknown values fall
+// back to text and info.
+func NewLogger(cfg config.LogConfig) *slog.Logger {
+	return newLogger(os.Stdout, cfg)
 }
 
-// Warning logs a warning message.
-func (l *Logger) Warning(v ...any) {
-	l.warning.Println(v...)
-}
+func newLogger(writer io.Writer, cfg config.LogConfig) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(cfg.Level)}
 
-// Error logs an error message.
-func (l *Logger) Error(v ...any) {
-	l.err.Println(v...)
-}
-
-// Debugf logs a formatted debug message.
-func (l *Logger) Debugf(format string, v ...any) {
-	l.debug.Printf(format, v...)
-}
-
-// Infof logs a formatted info message.
-func (l *Logger) Infof(format string, v ...any) {
-	l.info.Printf(format, v...)
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "json") {
+		handler = slog.NewJSONHandler(writer, opts)
+	} else {
+		handler = slog.NewTextHandler(writer, opts)
+	}
+	return slog.New(handler)
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
-// Warningf logs a formatted warning message.
-func (l *Logger) Warningf(format string, v ...any) {
-	l.warning.Printf(format, v...)
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
 }
 
-// Errorf logs a formatted error message.
-func (l *Logger) Errorf(format string, v ...any) {
-	l.err.Printf(format, v...)
+// FromContext returns the request-scoped logger stored in ctx, or the default
+// logger outside of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
 }
@@ -0,0 +1,86 @@
+// Package metrics defines the Prometheus collectors exposed on /metrics.
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "voting"
+
+// Submission rejection reasons.
+const (
+	ReasonAlreadySubmitted = "already_submitted"
+	ReasonOwnForm          = "own_form"
+	ReasonValidationError  = "validation_error"
+	ReasonFormNotFound     = "form_not_found"
+	ReasonFormClosed       = "form_closed"
+	ReasonNotEligible      = "not_eligible"
+	ReasonOther            = "other"
+)
+
+// Login failure reasons.
+const (
+	ReasonInvalidCredentials = "invalid_credentials"
+	ReasonThrottled          = "throttled"
+	ReasonAccountDisabled    = "account_disabled"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	FormsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "forms_created_total",
+		Help:      "Forms created.",
+	})
+
+	SubmissionsAccepted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_accepted_total",
+		Help:      "Form submissions accepted.",
+	})
+
+	SubmissionsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "submissions_rejected_total",
+		Help:      "Form submissions rejected, by reason.",
+	}, []string{"reason"})
+
+	DraftsSaved = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drafts_saved_total",
+		Help:      "Draft submissions saved.",
+	})
+
+	LoginsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_failed_total",
+		Help:      "Failed password logins, by reason.",
+	}, []string{"reason"})
+
+	LiveResultsViewers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "live_results_viewers",
+		Help:      "Clients connected to live results streams.",
+	})
+)
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, namespace))
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+
+	applog "github.com/luneto10/voting-system/internal/log"
+)
+
+// Message is a notification addressed to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users, e.g. by email.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to the application log instead of delivering
+// them. It is meant for development until a mail transport is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	applog.FromContext(ctx).InfoContext(ctx, "notification",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package pubsub delivers messages between the parts of the application that
+// change data and the ones that watch it.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned when publishing to or subscribing on a closed broker.
+var ErrClosed = errors.New("pubsub: broker closed")
+
+// Broker publishes messages on topics to every current subscriber of the
+// topic. Delivery is best effort: messages published while nobody listens are
+// dropped, and subscribers that fall behind may miss messages, so they should
+// carry a signal to reload rather than state.
+type Broker interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe receives the messages published on topic until ctx ends or
+	// the broker closes, at which point the channel is closed.
+	Subscribe(ctx context.Context, topic string) (<-chan []byte, error)
+	Close() error
+}
+
+// MemoryBroker is a Broker within a single process. Deployments running more
+// than one instance need a shared broker instead, or viewers connected to one
+// instance miss what happens on the others.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]map[chan []byte]struct{}
+	closed bool
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]map[chan []byte]struct{})}
+}
+
+// Publish never blocks: a subscriber that has not taken the previous message
+// yet does not get this one.
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	for ch := range b.topics[topic] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic string) (<-chan []byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	ch := make(chan []byte, 1)
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[chan []byte]struct{})
+	}
+	b.topics[topic][ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(topic, ch)
+	}()
+	return ch, nil
+}
+
+func (b *MemoryBroker) unsubscribe(topic string, ch chan []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.topics[topic][ch]; !ok {
+		return
+	}
+	delete(b.topics[topic], ch)
+	if len(b.topics[topic]) == 0 {
+		delete(b.topics, topic)
+	}
+	close(ch)
+}
+
+// Close ends every subscription. It is called on shutdown so open streams
+// finish instead of holding up the server.
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for topic, subscribers := range b.topics {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(b.topics, topic)
+	}
+	return nil
+}
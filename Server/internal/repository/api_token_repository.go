@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type APITokenRepository interface {
+	CreateAPIToken(ctx context.Context, token *model.APIToken) error
+	GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error)
+	GetAPITokensByUserID(ctx context.Context, userID uint) ([]*model.APIToken, error)
+	RevokeAPIToken(ctx context.Context, id uint, userID uint) (bool, error)
+	UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type APITokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &APITokenRepositoryImpl{db: db}
+}
+
+func (r *APITokenRepositoryImpl) CreateAPIToken(ctx context.Context, token *model.APIToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *APITokenRepositoryImpl) GetAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("token_hash = ?", hash).
+		First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *APITokenRepositoryImpl) GetAPITokensByUserID(ctx context.Context, userID uint) ([]*model.APIToken, error) {
+	var tokens []*model.APIToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *APITokenRepositoryImpl) RevokeAPIToken(ctx context.Context, id uint, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked = ?", id, userID, false).
+		Update("revoked", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *APITokenRepositoryImpl) UpdateLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, entry *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, action string, page, perPage int) ([]*model.AuditLog, int64, error)
+}
+
+type AuditLogRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &AuditLogRepositoryImpl{db: db}
+}
+
+func (r *AuditLogRepositoryImpl) CreateAuditLog(ctx context.Context, entry *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *AuditLogRepositoryImpl) GetAuditLogs(ctx context.Context, action string, page, perPage int) ([]*model.AuditLog, int64, error) {
+	var entries []*model.AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{})
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&entries).Error
+
+	return entries, total, err
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type CollaboratorRepository interface {
+	GetCollaborators(ctx context.Context, formID uint) ([]*model.FormCollaborator, error)
+	GetCollaborator(ctx context.Context, formID uint, userID uint) (*model.FormCollaborator, error)
+	GetSharedForms(ctx context.Context, userID uint) ([]*model.FormCollaborator, error)
+	AddCollaborator(ctx context.Context, collaborator *model.FormCollaborator) error
+	UpdateCollaboratorRole(ctx context.Context, formID uint, userID uint, role model.CollaboratorRole) error
+	RemoveCollaborator(ctx context.Context, formID uint, userID uint) error
+	CopyCollaborators(ctx context.Context, fromFormID uint, toFormID uint) error
+	TransferOwnership(ctx context.Context, formID uint, fromUserID uint, toUserID uint) error
+}
+
+type CollaboratorRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCollaboratorRepository(db *gorm.DB) CollaboratorRepository {
+	return &CollaboratorRepositoryImpl{db: db}
+}
+
+func (r *CollaboratorRepositoryImpl) GetCollaborators(ctx context.Context, formID uint) ([]*model.FormCollaborator, error) {
+	var collaborators []*model.FormCollaborator
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("form_id = ?", formID).
+		Order("id").
+		Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	return collaborators, nil
+}
+
+func (r *CollaboratorRepositoryImpl) GetCollaborator(ctx context.Context, formID uint, userID uint) (*model.FormCollaborator, error) {
+	var collaborator model.FormCollaborator
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		First(&collaborator).Error; err != nil {
+		return nil, err
+	}
+	return &collaborator, nil
+}
+
+// GetSharedForms returns the user's collaborations on forms that have not
+// been deleted, with the forms they give access to.
+func (r *CollaboratorRepositoryImpl) GetSharedForms(ctx context.Context, userID uint) ([]*model.FormCollaborator, error) {
+	var collaborators []*model.FormCollaborator
+	if err := r.db.WithContext(ctx).
+		Preload("Form.Questions.Options").
+		Joins("JOIN forms ON forms.id = form_collaborators.form_id AND forms.deleted_at IS NULL").
+		Where("form_collaborators.user_id = ?", userID).
+		Order("form_collaborators.id").
+		Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	return collaborators, nil
+}
+
+func (r *CollaboratorRepositoryImpl) AddCollaborator(ctx context.Context, collaborator *model.FormCollaborator) error {
+	return r.db.WithContext(ctx).Create(collaborator).Error
+}
+
+func (r *CollaboratorRepositoryImpl) UpdateCollaboratorRole(ctx context.Context, formID uint, userID uint, role model.CollaboratorRole) error {
+	result := r.db.WithContext(ctx).Model(&model.FormCollaborator{}).
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *CollaboratorRepositoryImpl) RemoveCollaborator(ctx context.Context, formID uint, userID uint) error {
+	result := r.db.WithContext(ctx).
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		Delete(&model.FormCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CopyCollaborators gives the collaborators of one form the same roles on
+// another, such as a runoff of it.
+func (r *CollaboratorRepositoryImpl) CopyCollaborators(ctx context.Context, fromFormID uint, toFormID uint) error {
+	return r.db.WithContext(ctx).Exec(`INSERT INTO form_collaborators (form_id, user_id, role, invited_by_id, created_at, updated_at)
+		SELECT ?, user_id, role, invited_by_id, NOW(), NOW() FROM form_collaborators WHERE form_id = ?
+		ON CONFLICT (form_id, user_id) DO NOTHING`, toFormID, fromFormID).Error
+}
+
+// TransferOwnership makes another user the owner of the form. The previous
+// owner stays on the form as a co-owner, and the new owner's collaborator
+// role, if they had one, is dropped. It fails with gorm.ErrRecordNotFound
+// when fromUserID no longer owns the form.
+func (r *CollaboratorRepositoryImpl) TransferOwnership(ctx context.Context, formID uint, fromUserID uint, toUserID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Form{}).
+			Where("id = ? AND user_id = ?", formID, fromUserID).
+			Update("user_id", toUserID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("form_id = ? AND user_id = ?", formID, toUserID).
+			Delete(&model.FormCollaborator{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.FormCollaborator{
+			FormID: formID,
+			UserID: fromUserID,
+			Role:   model.CollaboratorOwner,
+		}).Error
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -9,15 +10,15 @@ import (
 )
 
 type DashboardRepository interface {
-	GetUserFormParticipation(userID uint, formID uint) (*model.UserFormParticipation, error)
-	UpdateUserFormParticipation(participation *model.UserFormParticipation) error
-	CreateUserFormParticipation(participation *model.UserFormParticipation) error
-	GetUserFormsWithParticipation(userID uint) ([]*model.Form, error)
-	GetUserFormStatistics(userID uint) (available, inProgress, completed, recentActivity int, err error)
-	GetUserRecentActivity(userID uint, limit int) ([]*model.UserFormParticipation, error)
-	DeleteFormParticipation(userID uint, formID uint) error
-	GetUserActivities(userID uint, status string, page, perPage int) ([]*model.UserFormParticipation, int64, error)
-	WithTransaction(fn func(tx *gorm.DB) error) error
+	GetUserFormParticipation(ctx context.Context, userID uint, formID uint) (*model.UserFormParticipation, error)
+	UpdateUserFormParticipation(ctx context.Context, participation *model.UserFormParticipation) error
+	CreateUserFormParticipation(ctx context.Context, participation *model.UserFormParticipation) error
+	GetUserFormsWithParticipation(ctx context.Context, userID uint) ([]*model.Form, error)
+	GetUserFormStatistics(ctx context.Context, userID uint) (available, inProgress, completed, recentActivity int, err error)
+	GetUserRecentActivity(ctx context.Context, userID uint, limit int) ([]*model.UserFormParticipation, error)
+	DeleteFormParticipation(ctx context.Context, userID uint, formID uint) error
+	GetUserActivities(ctx context.Context, userID uint, status string, page, perPage int) ([]*model.UserFormParticipation, int64, error)
+	WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
 	GetUserFormParticipationTx(tx *gorm.DB, userID, formID uint) (*model.UserFormParticipation, error)
 }
 
@@ -29,8 +30,8 @@ func NewDashboardRepository(db *gorm.DB) DashboardRepository {
 	return &DashboardRepositoryImpl{db: db}
 }
 
-func (r *DashboardRepositoryImpl) WithTransaction(fn func(tx *gorm.DB) error) error {
-	return r.db.Transaction(fn)
+func (r *DashboardRepositoryImpl) WithTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
 }
 
 func (r *DashboardRepositoryImpl) GetUserFormParticipationTx(tx *gorm.DB, userID, formID uint) (*model.UserFormParticipation, error) {
@@ -42,9 +43,9 @@ func (r *DashboardRepositoryImpl) GetUserFormParticipationTx(tx *gorm.DB, userID
 	return &participation, err
 }
 
-func (r *DashboardRepositoryImpl) GetUserFormParticipation(userID uint, formID uint) (*model.UserFormParticipation, error) {
+func (r *DashboardRepositoryImpl) GetUserFormParticipation(ctx context.Context, userID uint, formID uint) (*model.UserFormParticipation, error) {
 	var participation model.UserFormParticipation
-	result := r.db.
+	result := r.db.WithContext(ctx).
 		Where("user_id = ? AND form_id = ?", userID, formID).
 		First(&participation)
 	if result.Error != nil {
@@ -56,25 +57,25 @@ func (r *DashboardRepositoryImpl) GetUserFormParticipation(userID uint, formID u
 	return &participation, nil
 }
 
-func (r *DashboardRepositoryImpl) UpdateUserFormParticipation(participation *model.UserFormParticipation) error {
-	if err := r.db.Save(participation).Error; err != nil {
+func (r *DashboardRepositoryImpl) UpdateUserFormParticipation(ctx context.Context, participation *model.UserFormParticipation) error {
+	if err := r.db.WithContext(ctx).Save(participation).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *DashboardRepositoryImpl) CreateUserFormParticipation(participation *model.UserFormParticipation) error {
-	if err := r.db.Create(participation).Error; err != nil {
+func (r *DashboardRepositoryImpl) CreateUserFormParticipation(ctx context.Context, participation *model.UserFormParticipation) error {
+	if err := r.db.WithContext(ctx).Create(participation).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *DashboardRepositoryImpl) GetUserFormsWithParticipation(userID uint) ([]*model.Form, error) {
+func (r *DashboardRepositoryImpl) GetUserFormsWithParticipation(ctx context.Context, userID uint) ([]*model.Form, error) {
 	var forms []*model.Form
 
 	// Get all forms with their participation status for the user
-	err := r.db.
+	err := r.db.WithContext(ctx).
 		Preload("Questions").
 		Joins("LEFT JOIN user_form_participations ON forms.id = user_form_participations.form_id AND user_form_participations.user_id = ?", userID).
 		Where("forms.start_at <= ? AND forms.end_at >= ? OR user_form_participations.user_id = ?", time.Now(), time.Now(), userID).
@@ -83,12 +84,12 @@ func (r *DashboardRepositoryImpl) GetUserFormsWithParticipation(userID uint) ([]
 	return forms, err
 }
 
-func (r *DashboardRepositoryImpl) GetUserFormStatistics(userID uint) (available, inProgress, completed, recentActivity int, err error) {
+func (r *DashboardRepositoryImpl) GetUserFormStatistics(ctx context.Context, userID uint) (available, inProgress, completed, recentActivity int, err error) {
 	now := time.Now()
 
 	var availableCount, inProgressCount, completedCount, recentActivityCount int64
 
-	err = r.db.Model(&model.Form{}).
+	err = r.db.WithContext(ctx).Model(&model.Form{}).
 		Joins("LEFT JOIN user_form_participations ON forms.id = user_form_participations.form_id AND user_form_participations.user_id = ?", userID).
 		Joins("LEFT JOIN submissions ON forms.id = submissions.form_id AND submissions.user_id = ?", userID).
 		Where("forms.start_at <= ? AND forms.end_at >= ?", now, now).
@@ -99,7 +100,7 @@ func (r *DashboardRepositoryImpl) GetUserFormStatistics(userID uint) (available,
 		return 0, 0, 0, 0, err
 	}
 
-	err = r.db.Model(&model.UserFormParticipation{}).
+	err = r.db.WithContext(ctx).Model(&model.UserFormParticipation{}).
 		Where("user_id = ? AND status = 'in_progress'", userID).
 		Count(&inProgressCount).Error
 	if err != nil {
@@ -107,7 +108,7 @@ func (r *DashboardRepositoryImpl) GetUserFormStatistics(userID uint) (available,
 	}
 
 	// Completed forms
-	err = r.db.Model(&model.UserFormParticipation{}).
+	err = r.db.WithContext(ctx).Model(&model.UserFormParticipation{}).
 		Where("user_id = ? AND status = 'completed'", userID).
 		Count(&completedCount).Error
 	if err != nil {
@@ -116,16 +117,16 @@ func (r *DashboardRepositoryImpl) GetUserFormStatistics(userID uint) (available,
 
 	// Recent activity (last 30 days)
 	thirtyDaysAgo := now.AddDate(0, 0, -30)
-	err = r.db.Model(&model.UserFormParticipation{}).
+	err = r.db.WithContext(ctx).Model(&model.UserFormParticipation{}).
 		Where("user_id = ? AND last_modified >= ?", userID, thirtyDaysAgo).
 		Count(&recentActivityCount).Error
 
 	return int(availableCount), int(inProgressCount), int(completedCount), int(recentActivityCount), err
 }
 
-func (r *DashboardRepositoryImpl) GetUserRecentActivity(userID uint, limit int) ([]*model.UserFormParticipation, error) {
+func (r *DashboardRepositoryImpl) GetUserRecentActivity(ctx context.Context, userID uint, limit int) ([]*model.UserFormParticipation, error) {
 	var activities []*model.UserFormParticipation
-	err := r.db.
+	err := r.db.WithContext(ctx).
 		Preload("Form", func(db *gorm.DB) *gorm.DB {
 			return db.Unscoped()
 		}).
@@ -137,15 +138,15 @@ func (r *DashboardRepositoryImpl) GetUserRecentActivity(userID uint, limit int)
 	return activities, err
 }
 
-func (r *DashboardRepositoryImpl) DeleteFormParticipation(userID uint, formID uint) error {
-	return r.db.Where("user_id = ? AND form_id = ?", userID, formID).Delete(&model.UserFormParticipation{}).Error
+func (r *DashboardRepositoryImpl) DeleteFormParticipation(ctx context.Context, userID uint, formID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND form_id = ?", userID, formID).Delete(&model.UserFormParticipation{}).Error
 }
 
-func (r *DashboardRepositoryImpl) GetUserActivities(userID uint, status string, page, perPage int) ([]*model.UserFormParticipation, int64, error) {
+func (r *DashboardRepositoryImpl) GetUserActivities(ctx context.Context, userID uint, status string, page, perPage int) ([]*model.UserFormParticipation, int64, error) {
 	var activities []*model.UserFormParticipation
 	var total int64
 
-	query := r.db.Model(&model.UserFormParticipation{}).
+	query := r.db.WithContext(ctx).Model(&model.UserFormParticipation{}).
 		Preload("Form", func(db *gorm.DB) *gorm.DB {
 			return db.Unscoped()
 		}).
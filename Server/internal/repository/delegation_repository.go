@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDelegateHasDelegated is returned by SaveDelegation when the delegate
+	// has delegated their own vote on the form.
+	ErrDelegateHasDelegated = errors.New("delegate has delegated their own vote")
+	// ErrDelegatorHoldsProxies is returned by SaveDelegation when others have
+	// delegated their votes on the form to the delegator.
+	ErrDelegatorHoldsProxies = errors.New("delegator holds the votes of others")
+)
+
+type DelegationRepository interface {
+	GetDelegation(ctx context.Context, formID uint, delegatorID uint) (*model.Delegation, error)
+	GetDelegationsTo(ctx context.Context, formID uint, delegateID uint) ([]*model.Delegation, error)
+	GetFormDelegations(ctx context.Context, formID uint) ([]*model.Delegation, error)
+	SaveDelegation(ctx context.Context, delegation *model.Delegation) error
+	DeleteDelegation(ctx context.Context, formID uint, delegatorID uint) error
+}
+
+type DelegationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &DelegationRepositoryImpl{db: db}
+}
+
+func (r *DelegationRepositoryImpl) GetDelegation(ctx context.Context, formID uint, delegatorID uint) (*model.Delegation, error) {
+	var delegation model.Delegation
+	if err := r.db.WithContext(ctx).
+		Preload("Delegator").
+		Preload("Delegate").
+		Where("form_id = ? AND delegator_id = ?", formID, delegatorID).
+		First(&delegation).Error; err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (r *DelegationRepositoryImpl) GetDelegationsTo(ctx context.Context, formID uint, delegateID uint) ([]*model.Delegation, error) {
+	var delegations []*model.Delegation
+	if err := r.db.WithContext(ctx).
+		Preload("Delegator").
+		Preload("Delegate").
+		Where("form_id = ? AND delegate_id = ?", formID, delegateID).
+		Order("id").
+		Find(&delegations).Error; err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+func (r *DelegationRepositoryImpl) GetFormDelegations(ctx context.Context, formID uint) ([]*model.Delegation, error) {
+	var delegations []*model.Delegation
+	if err := r.db.WithContext(ctx).
+		Preload("Delegator").
+		Preload("Delegate").
+		Where("form_id = ?", formID).
+		Order("id").
+		Find(&delegations).Error; err != nil {
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// SaveDelegation creates the delegation, or points the delegator's existing
+// delegation on the form at the new delegate. Delegations do not chain, which
+// is checked while the form's row is locked so concurrent delegations on the
+// form cannot both pass the check and form a chain or a cycle.
+func (r *DelegationRepositoryImpl) SaveDelegation(ctx context.Context, delegation *model.Delegation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// NO KEY UPDATE leaves ballots referencing the form unblocked
+		var form model.Form
+		if err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+			Select("id").
+			First(&form, delegation.FormID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.Delegation{}).
+			Where("form_id = ? AND delegator_id = ?", delegation.FormID, delegation.DelegateID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDelegateHasDelegated
+		}
+		if err := tx.Model(&model.Delegation{}).
+			Where("form_id = ? AND delegate_id = ?", delegation.FormID, delegation.DelegatorID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDelegatorHoldsProxies
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "form_id"}, {Name: "delegator_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"delegate_id", "created_at"}),
+		}).Create(delegation).Error
+	})
+}
+
+func (r *DelegationRepositoryImpl) DeleteDelegation(ctx context.Context, formID uint, delegatorID uint) error {
+	return r.db.WithContext(ctx).
+		Where("form_id = ? AND delegator_id = ?", formID, delegatorID).
+		Delete(&model.Delegation{}).Error
+}
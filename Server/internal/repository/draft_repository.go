@@ -1,15 +1,16 @@
 package repository
 
 import (
+	"context"
 	"github.com/luneto10/voting-system/api/model"
 	"gorm.io/gorm"
 )
 
 type DraftRepository interface {
-	SaveDraft(draft *model.DraftSubmission) error
-	GetDraft(formID uint, userID uint) (*model.DraftSubmission, error)
-	DeleteDraft(formID uint, userID uint) error
-	GetUserDrafts(userID uint) ([]*model.DraftSubmission, error)
+	SaveDraft(ctx context.Context, draft *model.DraftSubmission) error
+	GetDraft(ctx context.Context, formID uint, userID uint) (*model.DraftSubmission, error)
+	DeleteDraft(ctx context.Context, formID uint, userID uint) error
+	GetUserDrafts(ctx context.Context, userID uint) ([]*model.DraftSubmission, error)
 }
 
 type DraftRepositoryImpl struct {
@@ -20,13 +21,13 @@ func NewDraftRepository(db *gorm.DB) DraftRepository {
 	return &DraftRepositoryImpl{db: db}
 }
 
-func (r *DraftRepositoryImpl) SaveDraft(draft *model.DraftSubmission) error {
-	return r.db.Save(draft).Error
+func (r *DraftRepositoryImpl) SaveDraft(ctx context.Context, draft *model.DraftSubmission) error {
+	return r.db.WithContext(ctx).Save(draft).Error
 }
 
-func (r *DraftRepositoryImpl) GetDraft(formID uint, userID uint) (*model.DraftSubmission, error) {
+func (r *DraftRepositoryImpl) GetDraft(ctx context.Context, formID uint, userID uint) (*model.DraftSubmission, error) {
 	var draft model.DraftSubmission
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Form").
 		Where("form_id = ? AND user_id = ?", formID, userID).
 		First(&draft).Error; err != nil {
@@ -35,15 +36,15 @@ func (r *DraftRepositoryImpl) GetDraft(formID uint, userID uint) (*model.DraftSu
 	return &draft, nil
 }
 
-func (r *DraftRepositoryImpl) DeleteDraft(formID uint, userID uint) error {
-	return r.db.
+func (r *DraftRepositoryImpl) DeleteDraft(ctx context.Context, formID uint, userID uint) error {
+	return r.db.WithContext(ctx).
 		Where("form_id = ? AND user_id = ?", formID, userID).
 		Delete(&model.DraftSubmission{}).Error
 }
 
-func (r *DraftRepositoryImpl) GetUserDrafts(userID uint) ([]*model.DraftSubmission, error) {
+func (r *DraftRepositoryImpl) GetUserDrafts(ctx context.Context, userID uint) ([]*model.DraftSubmission, error) {
 	var drafts []*model.DraftSubmission
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Form").
 		Where("user_id = ?", userID).
 		Find(&drafts).Error; err != nil {
@@ -1,25 +1,39 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/luneto10/voting-system/api/model"
 	"gorm.io/gorm"
 )
 
 type FormRepository interface {
-	CreateForm(form *model.Form) error
-	GetForm(id uint) (*model.Form, error)
-	UpdateForm(id uint, form *model.Form) error
-	DeleteForm(id uint) error
-	GetFormsByUserID(userID uint) ([]*model.Form, error)
-	IsFormOwner(userID uint, formID uint) (bool, error)
-	CreateSubmission(submission *model.Submission) error
-	GetSubmissionByID(id uint) (*model.Submission, error)
-	GetSubmissionsByFormID(formID uint) ([]*model.Submission, error)
-	GetSubmissionsByUserID(userID uint) ([]*model.Submission, error)
-	GetFormVoters(formID uint) ([]*model.Submission, error)
-	UserSubmittedForm(userID uint, formID uint) (bool, error)
-	DeleteQuestion(id uint) error
-	DeleteOption(id uint) error
+	CreateForm(ctx context.Context, form *model.Form) error
+	GetForm(ctx context.Context, id uint) (*model.Form, error)
+	UpdateForm(ctx context.Context, id uint, form *model.Form) error
+	DeleteForm(ctx context.Context, id uint) error
+	GetFormsByUserID(ctx context.Context, userID uint) ([]*model.Form, error)
+	IsFormOwner(ctx context.Context, userID uint, formID uint) (bool, error)
+	CreateSubmission(ctx context.Context, submission *model.Submission) error
+	GetSubmissionByID(ctx context.Context, id uint) (*model.Submission, error)
+	GetSubmissionsByFormID(ctx context.Context, formID uint) ([]*model.Submission, error)
+	GetSubmissionsByUserID(ctx context.Context, userID uint) ([]*model.Submission, error)
+	GetFormVoters(ctx context.Context, formID uint) ([]*model.Submission, error)
+	GetFormAnswers(ctx context.Context, formID uint) ([]*model.Answer, error)
+	UserSubmittedForm(ctx context.Context, userID uint, formID uint) (bool, error)
+	GetUserSubmission(ctx context.Context, userID uint, formID uint) (*model.Submission, error)
+	ReplaceSubmission(ctx context.Context, replacedID uint, submission *model.Submission) error
+	GetSubmissionWeights(ctx context.Context, formID uint) ([]*model.Submission, error)
+	GetFirstCastTimes(ctx context.Context, formID uint) (map[uint]time.Time, error)
+	DeleteQuestion(ctx context.Context, formID uint, id uint) error
+	DeleteOption(ctx context.Context, questionID uint, id uint) error
+	SearchForms(ctx context.Context, query string, page, perPage int) ([]*model.Form, int64, error)
+	HasSubmissions(ctx context.Context, formID uint) (bool, error)
+	HasAnswers(ctx context.Context, questionID uint) (bool, error)
+	GetRunoffs(ctx context.Context, formID uint) ([]*model.Form, error)
+	UpdateResultsVisibility(ctx context.Context, formID uint, visibility model.ResultsVisibility, embargoUntil *time.Time) error
+	RotateResultsLink(ctx context.Context, formID uint) error
 }
 
 type FormRepositoryImpl struct {
@@ -30,13 +44,13 @@ func NewFormRepository(db *gorm.DB) FormRepository {
 	return &FormRepositoryImpl{db: db}
 }
 
-func (r *FormRepositoryImpl) CreateForm(form *model.Form) error {
-	return r.db.Create(form).Error
+func (r *FormRepositoryImpl) CreateForm(ctx context.Context, form *model.Form) error {
+	return r.db.WithContext(ctx).Create(form).Error
 }
 
-func (r *FormRepositoryImpl) GetForm(id uint) (*model.Form, error) {
+func (r *FormRepositoryImpl) GetForm(ctx context.Context, id uint) (*model.Form, error) {
 	var form model.Form
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Questions.Options").
 		Preload("User").
 		First(&form, id).Error; err != nil {
@@ -45,50 +59,42 @@ func (r *FormRepositoryImpl) GetForm(id uint) (*model.Form, error) {
 	return &form, nil
 }
 
-func (r *FormRepositoryImpl) UpdateForm(id uint, form *model.Form) error {
-	return r.db.Save(form).Error
+func (r *FormRepositoryImpl) UpdateForm(ctx context.Context, id uint, form *model.Form) error {
+	return r.db.WithContext(ctx).Save(form).Error
 }
 
-func (r *FormRepositoryImpl) DeleteForm(id uint) error {
-	// Start a transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
+func (r *FormRepositoryImpl) DeleteForm(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteForm(tx, id)
+	})
+}
 
+// deleteForm removes the form with its questions and submissions inside tx.
+func deleteForm(tx *gorm.DB, id uint) error {
 	// Update all user form participations to 'deleted' status
 	if err := tx.Model(&model.UserFormParticipation{}).
 		Where("form_id = ?", id).
 		Update("status", "deleted").Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	// Delete all submissions for this form
 	if err := tx.Where("form_id = ?", id).Delete(&model.Submission{}).Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	// Delete all questions and their options
 	if err := tx.Where("form_id = ?", id).Delete(&model.Question{}).Error; err != nil {
-		tx.Rollback()
 		return err
 	}
 
 	// Finally delete the form
-	if err := tx.Delete(&model.Form{}, id).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Commit the transaction
-	return tx.Commit().Error
+	return tx.Delete(&model.Form{}, id).Error
 }
 
-func (r *FormRepositoryImpl) GetFormsByUserID(userID uint) ([]*model.Form, error) {
+func (r *FormRepositoryImpl) GetFormsByUserID(ctx context.Context, userID uint) ([]*model.Form, error) {
 	var forms []*model.Form
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Questions.Options").
 		Where("user_id = ?", userID).
 		Find(&forms).Error; err != nil {
@@ -97,9 +103,9 @@ func (r *FormRepositoryImpl) GetFormsByUserID(userID uint) ([]*model.Form, error
 	return forms, nil
 }
 
-func (r *FormRepositoryImpl) IsFormOwner(userID uint, formID uint) (bool, error) {
+func (r *FormRepositoryImpl) IsFormOwner(ctx context.Context, userID uint, formID uint) (bool, error) {
 	var form model.Form
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Select("id").
 		Where("id = ? AND user_id = ?", formID, userID).
 		First(&form).Error; err != nil {
@@ -111,13 +117,13 @@ func (r *FormRepositoryImpl) IsFormOwner(userID uint, formID uint) (bool, error)
 	return true, nil
 }
 
-func (r *FormRepositoryImpl) CreateSubmission(submission *model.Submission) error {
-	return r.db.Create(submission).Error
+func (r *FormRepositoryImpl) CreateSubmission(ctx context.Context, submission *model.Submission) error {
+	return r.db.WithContext(ctx).Create(submission).Error
 }
 
-func (r *FormRepositoryImpl) GetSubmissionByID(id uint) (*model.Submission, error) {
+func (r *FormRepositoryImpl) GetSubmissionByID(ctx context.Context, id uint) (*model.Submission, error) {
 	var submission model.Submission
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Answers").
 		First(&submission, id).Error; err != nil {
 		return nil, err
@@ -125,9 +131,9 @@ func (r *FormRepositoryImpl) GetSubmissionByID(id uint) (*model.Submission, erro
 	return &submission, nil
 }
 
-func (r *FormRepositoryImpl) GetSubmissionsByFormID(formID uint) ([]*model.Submission, error) {
+func (r *FormRepositoryImpl) GetSubmissionsByFormID(ctx context.Context, formID uint) ([]*model.Submission, error) {
 	var submissions []*model.Submission
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("Answers").
 		Preload("User").
 		Where("form_id = ?", formID).
@@ -137,21 +143,23 @@ func (r *FormRepositoryImpl) GetSubmissionsByFormID(formID uint) ([]*model.Submi
 	return submissions, nil
 }
 
-func (r *FormRepositoryImpl) GetSubmissionsByUserID(userID uint) ([]*model.Submission, error) {
+func (r *FormRepositoryImpl) GetSubmissionsByUserID(ctx context.Context, userID uint) ([]*model.Submission, error) {
 	var submissions []*model.Submission
-	if err := r.db.
-		Preload("Answers").
-		First(&submissions, &model.Submission{UserID: userID}).Error; err != nil {
+	if err := r.db.WithContext(ctx).
+		Preload("Answers.Options").
+		Preload("Answers.Preferences").
+		Where("user_id = ?", userID).
+		Find(&submissions).Error; err != nil {
 		return nil, err
 	}
 	return submissions, nil
 }
 
-func (r *FormRepositoryImpl) GetFormVoters(formID uint) ([]*model.Submission, error) {
+func (r *FormRepositoryImpl) GetFormVoters(ctx context.Context, formID uint) ([]*model.Submission, error) {
 	var submissions []*model.Submission
 
 	// First get all submissions
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("User").
 		Where("form_id = ?", formID).
 		Find(&submissions).Error; err != nil {
@@ -160,7 +168,7 @@ func (r *FormRepositoryImpl) GetFormVoters(formID uint) ([]*model.Submission, er
 
 	// Then get all in-progress users
 	var inProgressUsers []*model.UserFormParticipation
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Preload("User").
 		Where("form_id = ? AND status = 'in_progress'", formID).
 		Find(&inProgressUsers).Error; err != nil {
@@ -179,9 +187,26 @@ func (r *FormRepositoryImpl) GetFormVoters(formID uint) ([]*model.Submission, er
 	return submissions, nil
 }
 
-func (r *FormRepositoryImpl) UserSubmittedForm(userID uint, formID uint) (bool, error) {
+// GetFormAnswers returns every submitted answer to the form's questions with
+// the chosen options and ballot preferences, in submission order.
+func (r *FormRepositoryImpl) GetFormAnswers(ctx context.Context, formID uint) ([]*model.Answer, error) {
+	var answers []*model.Answer
+	if err := r.db.WithContext(ctx).
+		Preload("Options").
+		Preload("Preferences").
+		Preload("Submission").
+		Joins("JOIN submissions ON submissions.id = answers.submission_id AND submissions.deleted_at IS NULL").
+		Where("submissions.form_id = ?", formID).
+		Order("answers.submission_id, answers.id").
+		Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *FormRepositoryImpl) UserSubmittedForm(ctx context.Context, userID uint, formID uint) (bool, error) {
 	var submission model.Submission
-	if err := r.db.
+	if err := r.db.WithContext(ctx).
 		Where("user_id = ? AND form_id = ?", userID, formID).
 		First(&submission).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -192,10 +217,149 @@ func (r *FormRepositoryImpl) UserSubmittedForm(userID uint, formID uint) (bool,
 	return true, nil
 }
 
-func (r *FormRepositoryImpl) DeleteQuestion(id uint) error {
-	return r.db.Delete(&model.Question{}, id).Error
+func (r *FormRepositoryImpl) GetUserSubmission(ctx context.Context, userID uint, formID uint) (*model.Submission, error) {
+	var submission model.Submission
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND form_id = ?", userID, formID).
+		First(&submission).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// ReplaceSubmission deletes a submission and creates its replacement in one
+// transaction, so the voter never has both or neither.
+func (r *FormRepositoryImpl) ReplaceSubmission(ctx context.Context, replacedID uint, submission *model.Submission) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Submission{}, replacedID).Error; err != nil {
+			return err
+		}
+		return tx.Create(submission).Error
+	})
+}
+
+// GetSubmissionWeights lists the form's submissions with their voters, weights
+// and proxies, without the answers.
+func (r *FormRepositoryImpl) GetSubmissionWeights(ctx context.Context, formID uint) ([]*model.Submission, error) {
+	var submissions []*model.Submission
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("CastBy").
+		Where("form_id = ?", formID).
+		Order("id").
+		Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+// DeleteQuestion deletes the question only if it belongs to the form.
+func (r *FormRepositoryImpl) DeleteQuestion(ctx context.Context, formID uint, id uint) error {
+	return r.db.WithContext(ctx).Where("form_id = ?", formID).Delete(&model.Question{}, id).Error
+}
+
+// DeleteOption deletes the option only if it belongs to the question.
+func (r *FormRepositoryImpl) DeleteOption(ctx context.Context, questionID uint, id uint) error {
+	return r.db.WithContext(ctx).
+		Where("id IN (SELECT option_id FROM question_options WHERE question_id = ?)", questionID).
+		Delete(&model.Option{}, id).Error
+}
+
+func (r *FormRepositoryImpl) SearchForms(ctx context.Context, query string, page, perPage int) ([]*model.Form, int64, error) {
+	var forms []*model.Form
+	var total int64
+
+	dbQuery := r.db.WithContext(ctx).Model(&model.Form{})
+	if query != "" {
+		dbQuery = dbQuery.Where("title ILIKE ?", "%"+query+"%")
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := dbQuery.
+		Preload("User").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&forms).Error
+
+	return forms, total, err
+}
+
+func (r *FormRepositoryImpl) HasSubmissions(ctx context.Context, formID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Submission{}).
+		Where("form_id = ?", formID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFirstCastTimes returns when each voter first cast a ballot on the form,
+// keyed by user ID. Replaced ballots are included, so voting again does not
+// move a voter's ballot later.
+func (r *FormRepositoryImpl) GetFirstCastTimes(ctx context.Context, formID uint) (map[uint]time.Time, error) {
+	var rows []struct {
+		UserID    uint
+		FirstCast time.Time
+	}
+	if err := r.db.WithContext(ctx).Unscoped().Model(&model.Submission{}).
+		Select("user_id, MIN(created_at) AS first_cast").
+		Where("form_id = ?", formID).
+		Group("user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	firstCast := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		firstCast[row.UserID] = row.FirstCast
+	}
+	return firstCast, nil
+}
+
+// HasAnswers reports whether a counted ballot answers the question. Answers of
+// ballots replaced by a later vote do not count.
+func (r *FormRepositoryImpl) HasAnswers(ctx context.Context, questionID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Answer{}).
+		Joins("JOIN submissions ON submissions.id = answers.submission_id AND submissions.deleted_at IS NULL").
+		Where("answers.question_id = ?", questionID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetRunoffs lists the runoffs started from the form, oldest first.
+func (r *FormRepositoryImpl) GetRunoffs(ctx context.Context, formID uint) ([]*model.Form, error) {
+	var forms []*model.Form
+	if err := r.db.WithContext(ctx).
+		Where("runoff_of_id = ?", formID).
+		Order("id").
+		Find(&forms).Error; err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+func (r *FormRepositoryImpl) UpdateResultsVisibility(ctx context.Context, formID uint, visibility model.ResultsVisibility, embargoUntil *time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Form{}).
+		Where("id = ?", formID).
+		Updates(map[string]any{
+			"results_visibility":    visibility,
+			"results_embargo_until": embargoUntil,
+		}).Error
 }
 
-func (r *FormRepositoryImpl) DeleteOption(id uint) error {
-	return r.db.Delete(&model.Option{}, id).Error
+// RotateResultsLink raises the form's link version, which revokes its share
+// links.
+func (r *FormRepositoryImpl) RotateResultsLink(ctx context.Context, formID uint) error {
+	return r.db.WithContext(ctx).Model(&model.Form{}).
+		Where("id = ?", formID).
+		Update("results_link_version", gorm.Expr("results_link_version + 1")).Error
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository interface {
+	GetLoginThrottle(ctx context.Context, key string) (*model.LoginThrottle, error)
+	RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration, maxFailures int, lockout time.Duration) error
+	DeleteLoginThrottle(ctx context.Context, key string) error
+}
+
+type LoginThrottleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &LoginThrottleRepositoryImpl{db: db}
+}
+
+func (r *LoginThrottleRepositoryImpl) GetLoginThrottle(ctx context.Context, key string) (*model.LoginThrottle, error) {
+	var throttle model.LoginThrottle
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&throttle).Error; err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordLoginFailure counts a failed login for key in a single statement, so
+// concurrent failures cannot overwrite each other's count. A throttle that is
+// no longer locked and whose last failure is older than window starts a fresh
+// count. Reaching maxFailures, when positive, locks the key for lockout.
+func (r *LoginThrottleRepositoryImpl) RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration, maxFailures int, lockout time.Duration) error {
+	return r.db.WithContext(ctx).Exec(`INSERT INTO login_throttles (key, failures, last_failure_at, locked_until, created_at, updated_at)
+		VALUES (@key, 1, @now, CASE WHEN @max > 0 AND 1 >= @max THEN CAST(@locked AS timestamptz) END, @now, @now)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN `+activeThrottle+` THEN login_throttles.failures + 1 ELSE 1 END,
+			locked_until = CASE
+				WHEN @max > 0 AND CASE WHEN `+activeThrottle+` THEN login_throttles.failures + 1 ELSE 1 END >= @max
+					THEN CAST(@locked AS timestamptz)
+				WHEN `+activeThrottle+` THEN login_throttles.locked_until
+			END,
+			last_failure_at = @now,
+			updated_at = @now,
+			deleted_at = NULL`,
+		map[string]any{
+			"key":         key,
+			"now":         now,
+			"max":         maxFailures,
+			"locked":      now.Add(lockout),
+			"windowStart": now.Add(-window),
+		}).Error
+}
+
+// activeThrottle matches a stored throttle that is still locked, or unlocked
+// with its last failure inside the failure window.
+const activeThrottle = `(login_throttles.deleted_at IS NULL AND (login_throttles.locked_until > @now OR
+	(login_throttles.locked_until IS NULL AND login_throttles.last_failure_at > @windowStart)))`
+
+func (r *LoginThrottleRepositoryImpl) DeleteLoginThrottle(ctx context.Context, key string) error {
+	// Hard delete so the unique key can be reused
+	return r.db.WithContext(ctx).Unscoped().Where("key = ?", key).Delete(&model.LoginThrottle{}).Error
+}
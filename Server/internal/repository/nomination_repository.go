@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NominationRepository interface {
+	GetNominations(ctx context.Context, formID uint) ([]*model.Nomination, error)
+	GetNomination(ctx context.Context, formID uint, nominationID uint) (*model.Nomination, error)
+	CreateNomination(ctx context.Context, nomination *model.Nomination) error
+	UpdateNominationStatus(ctx context.Context, nominationID uint, status model.NominationStatus) error
+	AddSupport(ctx context.Context, support *model.NominationSupport) error
+	ConvertNominations(ctx context.Context, formID uint, convertedAt time.Time, accepted []*model.Nomination) error
+}
+
+type NominationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNominationRepository(db *gorm.DB) NominationRepository {
+	return &NominationRepositoryImpl{db: db}
+}
+
+// GetNominations lists the nominations of a form with their supporters,
+// oldest first.
+func (r *NominationRepositoryImpl) GetNominations(ctx context.Context, formID uint) ([]*model.Nomination, error) {
+	var nominations []*model.Nomination
+	if err := r.db.WithContext(ctx).
+		Preload("NominatedBy").
+		Preload("Supporters").
+		Where("form_id = ?", formID).
+		Order("id").
+		Find(&nominations).Error; err != nil {
+		return nil, err
+	}
+	return nominations, nil
+}
+
+func (r *NominationRepositoryImpl) GetNomination(ctx context.Context, formID uint, nominationID uint) (*model.Nomination, error) {
+	var nomination model.Nomination
+	if err := r.db.WithContext(ctx).
+		Preload("NominatedBy").
+		Preload("Supporters").
+		Where("form_id = ?", formID).
+		First(&nomination, nominationID).Error; err != nil {
+		return nil, err
+	}
+	return &nomination, nil
+}
+
+func (r *NominationRepositoryImpl) CreateNomination(ctx context.Context, nomination *model.Nomination) error {
+	return r.db.WithContext(ctx).Create(nomination).Error
+}
+
+func (r *NominationRepositoryImpl) UpdateNominationStatus(ctx context.Context, nominationID uint, status model.NominationStatus) error {
+	return r.db.WithContext(ctx).Model(&model.Nomination{}).
+		Where("id = ?", nominationID).
+		Update("status", status).Error
+}
+
+// AddSupport records the user's support for a nomination. Supporting the
+// same nomination twice is a no-op.
+func (r *NominationRepositoryImpl) AddSupport(ctx context.Context, support *model.NominationSupport) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(support).Error
+}
+
+// ConvertNominations turns the accepted nominations of a form into options of
+// their questions and marks the form's nominations as converted. Forms that
+// were already converted are left alone, so concurrent callers convert the
+// nominations only once.
+func (r *NominationRepositoryImpl) ConvertNominations(ctx context.Context, formID uint, convertedAt time.Time, accepted []*model.Nomination) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Form{}).
+			Where("id = ? AND nominations_converted_at IS NULL", formID).
+			Update("nominations_converted_at", convertedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		for _, nomination := range accepted {
+			option := &model.Option{Title: nomination.Title}
+			if err := tx.Create(option).Error; err != nil {
+				return err
+			}
+			if err := tx.Table("question_options").Create(map[string]any{
+				"question_id": nomination.QuestionID,
+				"option_id":   option.ID,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.Nomination{}).
+				Where("id = ?", nomination.ID).
+				Update("option_id", option.ID).Error; err != nil {
+				return err
+			}
+			nomination.OptionID = &option.ID
+		}
+		return nil
+	})
+}
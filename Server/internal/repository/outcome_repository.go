@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutcomeRepository interface {
+	GetOutcome(ctx context.Context, formID uint) (*model.FormOutcome, error)
+	CreateOutcome(ctx context.Context, outcome *model.FormOutcome) (*model.FormOutcome, error)
+	FinalizeOutcome(ctx context.Context, outcome *model.FormOutcome) (*model.FormOutcome, error)
+	GetFormsAwaitingOutcome(ctx context.Context, now time.Time) ([]uint, error)
+	GetTieBreakDecisions(ctx context.Context, formID uint) ([]*model.TieBreakDecision, error)
+	CreateTieBreakDecision(ctx context.Context, decision *model.TieBreakDecision) error
+}
+
+type OutcomeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewOutcomeRepository(db *gorm.DB) OutcomeRepository {
+	return &OutcomeRepositoryImpl{db: db}
+}
+
+func (r *OutcomeRepositoryImpl) GetOutcome(ctx context.Context, formID uint) (*model.FormOutcome, error) {
+	var outcome model.FormOutcome
+	if err := r.db.WithContext(ctx).
+		Where("form_id = ?", formID).
+		First(&outcome).Error; err != nil {
+		return nil, err
+	}
+	return &outcome, nil
+}
+
+// CreateOutcome stores the outcome unless the form already has one, and
+// returns the stored outcome. Outcomes are never overwritten, so when two
+// requests freeze the same form at once both get the first one.
+func (r *OutcomeRepositoryImpl) CreateOutcome(ctx context.Context, outcome *model.FormOutcome) (*model.FormOutcome, error) {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "form_id"}}, DoNothing: true}).
+		Create(outcome).Error; err != nil {
+		return nil, err
+	}
+	return r.GetOutcome(ctx, outcome.FormID)
+}
+
+// FinalizeOutcome replaces the report of a provisional outcome and marks it
+// final. Final outcomes are left untouched; either way the stored outcome is
+// returned.
+func (r *OutcomeRepositoryImpl) FinalizeOutcome(ctx context.Context, outcome *model.FormOutcome) (*model.FormOutcome, error) {
+	if err := r.db.WithContext(ctx).Model(&model.FormOutcome{}).
+		Where("id = ? AND NOT final", outcome.ID).
+		Updates(map[string]any{
+			"final":          outcome.Final,
+			"quorum_reached": outcome.QuorumReached,
+			"report":         outcome.Report,
+			"decided_at":     outcome.DecidedAt,
+		}).Error; err != nil {
+		return nil, err
+	}
+	return r.GetOutcome(ctx, outcome.FormID)
+}
+
+// GetFormsAwaitingOutcome lists the forms whose voting closed by now but
+// whose outcome has not been stored yet.
+func (r *OutcomeRepositoryImpl) GetFormsAwaitingOutcome(ctx context.Context, now time.Time) ([]uint, error) {
+	var formIDs []uint
+	if err := r.db.WithContext(ctx).Model(&model.Form{}).
+		Where("end_at IS NOT NULL AND end_at <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM form_outcomes WHERE form_outcomes.form_id = forms.id)").
+		Order("end_at").
+		Pluck("id", &formIDs).Error; err != nil {
+		return nil, err
+	}
+	return formIDs, nil
+}
+
+func (r *OutcomeRepositoryImpl) GetTieBreakDecisions(ctx context.Context, formID uint) ([]*model.TieBreakDecision, error) {
+	var decisions []*model.TieBreakDecision
+	if err := r.db.WithContext(ctx).
+		Where("form_id = ?", formID).
+		Find(&decisions).Error; err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+func (r *OutcomeRepositoryImpl) CreateTieBreakDecision(ctx context.Context, decision *model.TieBreakDecision) error {
+	return r.db.WithContext(ctx).Create(decision).Error
+}
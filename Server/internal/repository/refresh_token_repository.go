@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"context"
 	"github.com/luneto10/voting-system/api/model"
 	"gorm.io/gorm"
 )
 
 type RefreshTokenRepository interface {
-	CreateRefreshToken(token *model.RefreshToken) error
-	GetRefreshTokenByToken(token string) (*model.RefreshToken, error)
-	DeleteRefreshToken(token string) error
-	RevokeRefreshToken(token string) error
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByToken(ctx context.Context, token string) (*model.RefreshToken, error)
+	DeleteRefreshToken(ctx context.Context, token string) error
+	RevokeRefreshToken(ctx context.Context, token string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint) error
+	RevokeUserRefreshTokensExcept(ctx context.Context, userID uint, keepToken string) error
 }
 
 type RefreshTokenRepositoryImpl struct {
@@ -20,22 +23,34 @@ func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepositoryImpl {
 	return &RefreshTokenRepositoryImpl{db: db}
 }
 
-func (r *RefreshTokenRepositoryImpl) CreateRefreshToken(token *model.RefreshToken) error {
-	return r.db.Create(token).Error
+func (r *RefreshTokenRepositoryImpl) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
 }
 
-func (r *RefreshTokenRepositoryImpl) GetRefreshTokenByToken(token string) (*model.RefreshToken, error) {
+func (r *RefreshTokenRepositoryImpl) GetRefreshTokenByToken(ctx context.Context, token string) (*model.RefreshToken, error) {
 	var refreshToken model.RefreshToken
-	if err := r.db.Where(&model.RefreshToken{Token: token}).First(&refreshToken).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where(&model.RefreshToken{Token: token}).First(&refreshToken).Error; err != nil {
 		return nil, err
 	}
 	return &refreshToken, nil
 }
 
-func (r *RefreshTokenRepositoryImpl) DeleteRefreshToken(token string) error {
-	return r.db.Where(&model.RefreshToken{Token: token}).Delete(&model.RefreshToken{}).Error
+func (r *RefreshTokenRepositoryImpl) DeleteRefreshToken(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Where(&model.RefreshToken{Token: token}).Delete(&model.RefreshToken{}).Error
 }
 
-func (r *RefreshTokenRepositoryImpl) RevokeRefreshToken(token string) error {
-	return r.db.Model(&model.RefreshToken{}).Where(&model.RefreshToken{Token: token}).Update("revoked", true).Error
+func (r *RefreshTokenRepositoryImpl) RevokeRefreshToken(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).Where(&model.RefreshToken{Token: token}).Update("revoked", true).Error
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeUserRefreshTokens(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Update("revoked", true).Error
+}
+
+func (r *RefreshTokenRepositoryImpl) RevokeUserRefreshTokensExcept(ctx context.Context, userID uint, keepToken string) error {
+	return r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked = ? AND token <> ?", userID, false, keepToken).
+		Update("revoked", true).Error
 }
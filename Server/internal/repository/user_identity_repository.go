@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	GetIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+}
+
+type UserIdentityRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &UserIdentityRepositoryImpl{db: db}
+}
+
+func (r *UserIdentityRepositoryImpl) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *UserIdentityRepositoryImpl) GetIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
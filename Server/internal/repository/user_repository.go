@@ -1,14 +1,22 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/luneto10/voting-system/api/model"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	CreateUser(user *model.User) error
-	GetUserByEmail(email string) (*model.User, error)
-	GetUserByID(id uint) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) error
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByID(ctx context.Context, id uint) (*model.User, error)
+	UpdateUser(ctx context.Context, user *model.User) error
+	SearchUsers(ctx context.Context, query string, page, perPage int) ([]*model.User, int64, error)
+	GetUserByEmailVerificationHash(ctx context.Context, hash string) (*model.User, error)
+	DeleteAccount(ctx context.Context, id uint, now time.Time) error
 }
 
 type UserRepositoryImpl struct {
@@ -19,22 +27,132 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &UserRepositoryImpl{db: db}
 }
 
-func (r *UserRepositoryImpl) CreateUser(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepositoryImpl) CreateUser(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *UserRepositoryImpl) GetUserByEmail(email string) (*model.User, error) {
+func (r *UserRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.First(&user, model.User{Email: email}).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, model.User{Email: email}).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *UserRepositoryImpl) GetUserByID(id uint) (*model.User, error) {
+func (r *UserRepositoryImpl) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) UpdateUser(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *UserRepositoryImpl) SearchUsers(ctx context.Context, query string, page, perPage int) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	dbQuery := r.db.WithContext(ctx).Model(&model.User{})
+	if query != "" {
+		dbQuery = dbQuery.Where("email ILIKE ?", "%"+query+"%")
+	}
+
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := dbQuery.
+		Order("id ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+func (r *UserRepositoryImpl) GetUserByEmailVerificationHash(ctx context.Context, hash string) (*model.User, error) {
 	var user model.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email_verification_hash = ?", hash).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// DeleteAccount deletes the user's forms nobody else voted on and closes the
+// others, takes the user off every form's team and off the rolls and
+// delegations of forms still open, then removes everything that identifies
+// the user while keeping the row their anonymous submissions point to. It
+// all happens in one transaction, so a failure leaves the account as it was.
+func (r *UserRepositoryImpl) DeleteAccount(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var formIDs []uint
+		if err := tx.Model(&model.Form{}).Where("user_id = ?", id).Pluck("id", &formIDs).Error; err != nil {
+			return err
+		}
+		for _, formID := range formIDs {
+			var votes int64
+			if err := tx.Model(&model.Submission{}).
+				Where("form_id = ? AND user_id <> ?", formID, id).
+				Count(&votes).Error; err != nil {
+				return err
+			}
+			if votes == 0 {
+				if err := deleteForm(tx, formID); err != nil {
+					return err
+				}
+				continue
+			}
+			// Keep the form so its results stay intact, but end voting now
+			if err := tx.Model(&model.Form{}).
+				Where("id = ? AND (end_at IS NULL OR end_at > ?)", formID, now).
+				Update("end_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.FormCollaborator{}).Error; err != nil {
+			return err
+		}
+		// Closed forms keep their rolls and delegations, as their outcomes
+		// were counted against them
+		openForms := tx.Model(&model.Form{}).Select("id").Where("end_at IS NULL OR end_at > ?", now)
+		if err := tx.Where("user_id = ? AND form_id IN (?)", id, openForms).Delete(&model.EligibleVoter{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("(delegator_id = ? OR delegate_id = ?) AND form_id IN (?)", id, id, openForms).
+			Delete(&model.Delegation{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.DraftSubmission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.UserFormParticipation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.RefreshToken{}).Where("user_id = ?", id).Update("revoked", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.APIToken{}).Where("user_id = ?", id).Update("revoked", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.User{}).Where("id = ?", id).Updates(map[string]any{
+			"email":                         fmt.Sprintf("deleted-user-%d@deleted.invalid", id),
+			"password":                      "",
+			"display_name":                  "",
+			"role":                          model.UserRoleUser,
+			"disabled":                      true,
+			"pending_email":                 nil,
+			"email_verification_hash":       "",
+			"email_verification_expires_at": nil,
+		}).Error
+	})
+}
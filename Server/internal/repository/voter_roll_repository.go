@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+type VoterRollRepository interface {
+	GetVoterRoll(ctx context.Context, formID uint) ([]*model.EligibleVoter, error)
+	ReplaceVoterRoll(ctx context.Context, formID uint, voters []model.EligibleVoter) error
+	CountVoterRoll(ctx context.Context, formID uint) (int64, error)
+	IsOnVoterRoll(ctx context.Context, formID uint, userID uint) (bool, error)
+	GetVoterWeight(ctx context.Context, formID uint, userID uint) (int, error)
+	CountParticipants(ctx context.Context, formID uint) (int64, error)
+}
+
+type VoterRollRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewVoterRollRepository(db *gorm.DB) VoterRollRepository {
+	return &VoterRollRepositoryImpl{db: db}
+}
+
+func (r *VoterRollRepositoryImpl) GetVoterRoll(ctx context.Context, formID uint) ([]*model.EligibleVoter, error) {
+	var voters []*model.EligibleVoter
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("form_id = ?", formID).
+		Order("id").
+		Find(&voters).Error; err != nil {
+		return nil, err
+	}
+	return voters, nil
+}
+
+// ReplaceVoterRoll makes the given voters the form's whole voter roll. An
+// empty list removes the roll.
+func (r *VoterRollRepositoryImpl) ReplaceVoterRoll(ctx context.Context, formID uint, voters []model.EligibleVoter) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("form_id = ?", formID).Delete(&model.EligibleVoter{}).Error; err != nil {
+			return err
+		}
+		if len(voters) == 0 {
+			return nil
+		}
+
+		for i := range voters {
+			voters[i].FormID = formID
+		}
+		return tx.Create(&voters).Error
+	})
+}
+
+func (r *VoterRollRepositoryImpl) CountVoterRoll(ctx context.Context, formID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.EligibleVoter{}).
+		Where("form_id = ?", formID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *VoterRollRepositoryImpl) IsOnVoterRoll(ctx context.Context, formID uint, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.EligibleVoter{}).
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetVoterWeight returns the weight of the user's vote on the form: their
+// weight on the voter roll, or 1 when they are not on it.
+func (r *VoterRollRepositoryImpl) GetVoterWeight(ctx context.Context, formID uint, userID uint) (int, error) {
+	var voter model.EligibleVoter
+	err := r.db.WithContext(ctx).
+		Where("form_id = ? AND user_id = ?", formID, userID).
+		First(&voter).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return voter.Weight, nil
+}
+
+// CountParticipants counts the form's submissions. When the form has a voter
+// roll only submissions from users still on it count.
+func (r *VoterRollRepositoryImpl) CountParticipants(ctx context.Context, formID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Submission{}).
+		Where("form_id = ?", formID).
+		Where(`NOT EXISTS (SELECT 1 FROM eligible_voters WHERE eligible_voters.form_id = submissions.form_id)
+			OR EXISTS (SELECT 1 FROM eligible_voters WHERE eligible_voters.form_id = submissions.form_id AND eligible_voters.user_id = submissions.user_id)`).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
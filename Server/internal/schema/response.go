@@ -2,20 +2,60 @@ package schema
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/luneto10/voting-system/internal/validation"
+	"github.com/luneto10/voting-system/internal/apperr"
 )
 
-func SendError(ctx *gin.Context, code int, msg string) {
-	ctx.Header("Content-type", "application/json")
-	ctx.JSON(code, gin.H{
-		"message":   msg,
-		"errorCode": code,
+// ProblemContentType is the media type of RFC 7807 problem responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is the stable error code
+// clients should switch on; Message repeats Detail for clients written
+// against the earlier {"message": ...} error format.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    []apperr.FieldError `json:"errors,omitempty"`
+	Message   string              `json:"message"`
+}
+
+// SendProblem writes err as a problem response.
+func SendProblem(ctx *gin.Context, err *apperr.Error) {
+	if err.RetryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	}
+
+	// gin keeps a content type that is already set instead of its JSON default
+	ctx.Header("Content-Type", ProblemContentType)
+	ctx.JSON(err.Status, Problem{
+		Type:      "urn:voting-system:error:" + err.Code,
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    err.Message,
+		Instance:  ctx.Request.URL.Path,
+		Code:      err.Code,
+		RequestID: ctx.GetString("request_id"),
+		Errors:    err.Fields,
+		Message:   err.Message,
 	})
 }
 
+// SendError writes a problem response with a generic code derived from the
+// status, for errors that have no more specific code.
+func SendError(ctx *gin.Context, code int, msg string) {
+	SendProblem(ctx, apperr.New(code, statusCode(code), msg))
+}
+
 func SendSuccess(ctx *gin.Context, op string, data any) {
 	ctx.Header("Content-type", "application/json")
 
@@ -25,10 +65,12 @@ func SendSuccess(ctx *gin.Context, op string, data any) {
 	})
 }
 
-func SendValidationError(ctx *gin.Context, err error) {
-	errors := validation.FormatErrors(err)
-	ctx.Header("Content-type", "application/json")
-	ctx.JSON(http.StatusUnprocessableEntity, gin.H{
-		"errors": errors,
-	})
+// statusCode turns a status into a code such as "not_found" or "conflict".
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	text = strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text)
+	return strings.ToLower(text)
 }
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/helper"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+	"github.com/luneto10/voting-system/internal/notify"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const emailVerificationExpiration = 24 * time.Hour
+
+var errEmailInUse = ErrUserAlreadyExists.WithMessage("email address is already in use")
+
+type AccountService interface {
+	GetProfile(ctx context.Context, userID uint) (*model.User, error)
+	UpdateProfile(ctx context.Context, userID uint, req *dto.UpdateProfileRequest) (*model.User, error)
+	RequestReauthentication(ctx context.Context, userID uint) error
+	RequestEmailChange(ctx context.Context, userID uint, newEmail string, reauth dto.Reauthentication) error
+	VerifyEmailChange(ctx context.Context, token string) (*model.User, error)
+	ExportData(ctx context.Context, userID uint) (*dto.AccountExport, error)
+	DeleteAccount(ctx context.Context, userID uint, reauth dto.Reauthentication) error
+}
+
+type AccountServiceImpl struct {
+	userRepository  repository.UserRepository
+	formRepository  repository.FormRepository
+	draftRepository repository.DraftRepository
+	notifier        notify.Notifier
+	frontendURL     string
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	formRepo repository.FormRepository,
+	draftRepo repository.DraftRepository,
+	notifier notify.Notifier,
+	frontendURL string,
+) AccountService {
+	return &AccountServiceImpl{
+		userRepository:  userRepo,
+		formRepository:  formRepo,
+		draftRepository: draftRepo,
+		notifier:        notifier,
+		frontendURL:     frontendURL,
+	}
+}
+
+func (s *AccountServiceImpl) GetProfile(ctx context.Context, userID uint) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.GetProfile")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (s *AccountServiceImpl) UpdateProfile(ctx context.Context, userID uint, req *dto.UpdateProfileRequest) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, ErrInvalidTimezone
+		}
+		user.Timezone = *req.Timezone
+	}
+
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RequestReauthentication emails a confirmation code to an account without a
+// local password. The code confirms an email change or the account's deletion
+// for auth.ReauthTokenExpiration.
+func (s *AccountServiceImpl) RequestReauthentication(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AccountService.RequestReauthentication")
+	defer span.End()
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password != "" {
+		return ErrReauthByPassword
+	}
+
+	token := auth.SignReauthToken(user.ID, user.Email, time.Now().Add(auth.ReauthTokenExpiration))
+	return s.notifier.Send(ctx, notify.Message{
+		To:      user.Email,
+		Subject: "Confirm your account change",
+		Body:    "Enter this code within 15 minutes to confirm the change to your account: " + token,
+	})
+}
+
+// RequestEmailChange stores the new address as pending and sends a
+// verification link to it. The address only changes once the link is used.
+func (s *AccountServiceImpl) RequestEmailChange(ctx context.Context, userID uint, newEmail string, reauth dto.Reauthentication) error {
+	ctx, span := tracing.Start(ctx, "AccountService.RequestEmailChange")
+	defer span.End()
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyReauthentication(user, reauth); err != nil {
+		return err
+	}
+
+	existingUser, err := s.userRepository.GetUserByEmail(ctx, newEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existingUser != nil {
+		return errEmailInUse
+	}
+
+	token, err := randomString()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(emailVerificationExpiration)
+	user.PendingEmail = &newEmail
+	user.EmailVerificationHash = hashVerificationToken(token)
+	user.EmailVerificationExpiresAt = &expiresAt
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	link := s.frontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.notifier.Send(ctx, notify.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body:    "Open this link within 24 hours to confirm your new email address: " + link,
+	})
+}
+
+func (s *AccountServiceImpl) VerifyEmailChange(ctx context.Context, token string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.VerifyEmailChange")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByEmailVerificationHash(ctx, hashVerificationToken(token))
+	if err != nil {
+		return nil, notFound(err, ErrInvalidVerification)
+	}
+
+	if user.PendingEmail == nil || user.EmailVerificationExpiresAt == nil ||
+		time.Now().After(*user.EmailVerificationExpiresAt) {
+		return nil, ErrInvalidVerification
+	}
+
+	// The address may have been taken since the change was requested
+	existingUser, err := s.userRepository.GetUserByEmail(ctx, *user.PendingEmail)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, errEmailInUse
+	}
+
+	user.Email = *user.PendingEmail
+	user.PendingEmail = nil
+	user.EmailVerificationHash = ""
+	user.EmailVerificationExpiresAt = nil
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ExportData collects the user's profile, forms, submissions and drafts.
+func (s *AccountServiceImpl) ExportData(ctx context.Context, userID uint) (*dto.AccountExport, error) {
+	ctx, span := tracing.Start(ctx, "AccountService.ExportData")
+	defer span.End()
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dto.AccountExport{
+		ExportedAt: time.Now(),
+		Profile: dto.UserProfileResponse{
+			ID:           user.ID,
+			Email:        user.Email,
+			DisplayName:  user.DisplayName,
+			Timezone:     user.Timezone,
+			Role:         string(user.Role),
+			PendingEmail: user.PendingEmail,
+		},
+		Forms:       []dto.GetFormResponse{},
+		Submissions: []dto.ExportSubmission{},
+		Drafts:      []dto.DraftSubmissionResponse{},
+	}
+
+	forms, err := s.formRepository.GetFormsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := copier.Copy(&export.Forms, forms); err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.formRepository.GetSubmissionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, submission := range submissions {
+		exported := dto.ExportSubmission{
+			ID:          submission.ID,
+			FormID:      submission.FormID,
+			CompletedAt: submission.CompletedAt,
+			Answers:     make([]dto.AnswerSubmission, len(submission.Answers)),
+		}
+		for i, answer := range submission.Answers {
+			exported.Answers[i].QuestionID = answer.QuestionID
+			exported.Answers[i].Abstain = answer.Abstain
+			if answer.Text != nil {
+				exported.Answers[i].Text = *answer.Text
+			}
+			if len(answer.Preferences) > 0 {
+				ranking, scores := ballotPreferences(answer.Preferences)
+				exported.Answers[i].Ranking = ranking
+				exported.Answers[i].Scores = scores
+				continue
+			}
+			for _, option := range answer.Options {
+				exported.Answers[i].OptionIDs = append(exported.Answers[i].OptionIDs, option.ID)
+			}
+		}
+		export.Submissions = append(export.Submissions, exported)
+	}
+
+	drafts, err := s.draftRepository.GetUserDrafts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, draft := range drafts {
+		var answers []dto.AnswerSubmission
+		if err := json.Unmarshal(draft.Answers, &answers); err != nil {
+			return nil, err
+		}
+		export.Drafts = append(export.Drafts, dto.DraftSubmissionResponse{
+			ID:                 draft.ID,
+			FormID:             draft.FormID,
+			UserID:             draft.UserID,
+			FormTitle:          draft.Form.Title,
+			FormDescription:    draft.Form.Description,
+			LastModified:       draft.UpdatedAt,
+			ProgressPercentage: draft.ProgressPercentage,
+			Answers:            answers,
+		})
+	}
+
+	return export, nil
+}
+
+// DeleteAccount applies the account deletion policy in one transaction:
+//   - forms nobody else voted on are deleted;
+//   - forms other people voted on are closed and kept so their results stay intact;
+//   - the user's own submissions are kept anonymously, since they count in other owners' results;
+//   - the user leaves every form's team, and the rolls and delegations of forms still open;
+//   - drafts, participation records, linked identities and sessions are removed;
+//   - the user record is anonymized and disabled, freeing the email address.
+func (s *AccountServiceImpl) DeleteAccount(ctx context.Context, userID uint, reauth dto.Reauthentication) error {
+	ctx, span := tracing.Start(ctx, "AccountService.DeleteAccount")
+	defer span.End()
+
+	user, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyReauthentication(user, reauth); err != nil {
+		return err
+	}
+
+	return s.userRepository.DeleteAccount(ctx, userID, time.Now())
+}
+
+// verifyReauthentication checks the password of accounts that have one.
+// Accounts created through single sign-on have no local password to confirm,
+// so they prove they still control the account with an emailed code instead.
+func verifyReauthentication(user *model.User, reauth dto.Reauthentication) error {
+	if user.Password == "" {
+		if auth.VerifyReauthToken(reauth.ConfirmationToken, user.ID, user.Email, time.Now()) != nil {
+			return ErrReauthRequired
+		}
+		return nil
+	}
+	if err := helper.ComparePassword(user.Password, reauth.Password); err != nil {
+		return ErrInvalidCredentials.WithMessage("password is incorrect")
+	}
+	return nil
+}
+
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+)
+
+// AuditActor identifies who performed an administrative action and from where.
+type AuditActor struct {
+	UserID    uint
+	IPAddress string
+}
+
+type AdminService interface {
+	SearchUsers(ctx context.Context, query string, page, perPage int) ([]*model.User, int64, error)
+	SetUserDisabled(ctx context.Context, actor AuditActor, userID uint, disabled bool) (*model.User, error)
+	SetUserRole(ctx context.Context, actor AuditActor, userID uint, role model.UserRole) (*model.User, error)
+	UnlockUser(ctx context.Context, actor AuditActor, userID uint) error
+	SearchForms(ctx context.Context, query string, page, perPage int) ([]*model.Form, int64, error)
+	GetForm(ctx context.Context, actor AuditActor, formID uint) (*model.Form, error)
+	TakeDownForm(ctx context.Context, actor AuditActor, formID uint, reason string) error
+	GetAuditLogs(ctx context.Context, action string, page, perPage int) ([]*model.AuditLog, int64, error)
+}
+
+type AdminServiceImpl struct {
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	formRepository         repository.FormRepository
+	auditLogRepository     repository.AuditLogRepository
+	loginProtectionService LoginProtectionService
+}
+
+func NewAdminService(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	formRepo repository.FormRepository,
+	auditLogRepo repository.AuditLogRepository,
+	loginProtectionService LoginProtectionService,
+) AdminService {
+	return &AdminServiceImpl{
+		userRepository:         userRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		formRepository:         formRepo,
+		auditLogRepository:     auditLogRepo,
+		loginProtectionService: loginProtectionService,
+	}
+}
+
+func (s *AdminServiceImpl) SearchUsers(ctx context.Context, query string, page, perPage int) ([]*model.User, int64, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.SearchUsers")
+	defer span.End()
+
+	return s.userRepository.SearchUsers(ctx, query, page, perPage)
+}
+
+func (s *AdminServiceImpl) SetUserDisabled(ctx context.Context, actor AuditActor, userID uint, disabled bool) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.SetUserDisabled")
+	defer span.End()
+
+	if actor.UserID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	user.Disabled = disabled
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// End existing sessions so a disabled user is signed out on next refresh
+	if disabled {
+		if err := s.refreshTokenRepository.RevokeUserRefreshTokens(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	action := model.AuditActionUserEnabled
+	if disabled {
+		action = model.AuditActionUserDisabled
+	}
+	if err := s.record(ctx, actor, action, "user", userID, nil); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *AdminServiceImpl) SetUserRole(ctx context.Context, actor AuditActor, userID uint, role model.UserRole) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.SetUserRole")
+	defer span.End()
+
+	if role != model.UserRoleAdmin && role != model.UserRoleUser {
+		return nil, ErrInvalidRole
+	}
+	if actor.UserID == userID && role != model.UserRoleAdmin {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrUserNotFound)
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{"from": previous, "to": role}
+	if err := s.record(ctx, actor, model.AuditActionUserRoleChange, "user", userID, details); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *AdminServiceImpl) UnlockUser(ctx context.Context, actor AuditActor, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AdminService.UnlockUser")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if err := s.loginProtectionService.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+
+	return s.record(ctx, actor, model.AuditActionUserUnlocked, "user", userID, nil)
+}
+
+func (s *AdminServiceImpl) SearchForms(ctx context.Context, query string, page, perPage int) ([]*model.Form, int64, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.SearchForms")
+	defer span.End()
+
+	return s.formRepository.SearchForms(ctx, query, page, perPage)
+}
+
+func (s *AdminServiceImpl) GetForm(ctx context.Context, actor AuditActor, formID uint) (*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetForm")
+	defer span.End()
+
+	form, err := s.formRepository.GetForm(ctx, formID)
+	if err != nil {
+		return nil, notFound(err, ErrFormNotFound)
+	}
+
+	if err := s.record(ctx, actor, model.AuditActionFormViewed, "form", formID, nil); err != nil {
+		return nil, err
+	}
+
+	return form, nil
+}
+
+func (s *AdminServiceImpl) TakeDownForm(ctx context.Context, actor AuditActor, formID uint, reason string) error {
+	ctx, span := tracing.Start(ctx, "AdminService.TakeDownForm")
+	defer span.End()
+
+	form, err := s.formRepository.GetForm(ctx, formID)
+	if err != nil {
+		return notFound(err, ErrFormNotFound)
+	}
+
+	if err := s.formRepository.DeleteForm(ctx, formID); err != nil {
+		return err
+	}
+
+	details := map[string]any{
+		"reason":   reason,
+		"title":    form.Title,
+		"owner_id": form.UserID,
+	}
+	return s.record(ctx, actor, model.AuditActionFormTakenDown, "form", formID, details)
+}
+
+func (s *AdminServiceImpl) GetAuditLogs(ctx context.Context, action string, page, perPage int) ([]*model.AuditLog, int64, error) {
+	ctx, span := tracing.Start(ctx, "AdminService.GetAuditLogs")
+	defer span.End()
+
+	return s.auditLogRepository.GetAuditLogs(ctx, action, page, perPage)
+}
+
+func (s *AdminServiceImpl) record(ctx context.Context, actor AuditActor, action, targetType string, targetID uint, details map[string]any) error {
+	var detailsJSON json.RawMessage
+	if details != nil {
+		encoded, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		detailsJSON = encoded
+	}
+
+	return s.auditLogRepository.CreateAuditLog(ctx, &model.AuditLog{
+		ActorID:    actor.UserID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    detailsJSON,
+		IPAddress:  actor.IPAddress,
+	})
+}
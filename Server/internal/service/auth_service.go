@@ -1,41 +1,62 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/luneto10/voting-system/api/model"
 	"github.com/luneto10/voting-system/internal/helper"
 	"github.com/luneto10/voting-system/internal/helper/auth"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/metrics"
 	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"github.com/luneto10/voting-system/internal/validation"
 	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Register(user *model.User) (*model.User, error)
-	Login(email, password string) (*model.User, string, string, error)
-	RefreshToken(refreshToken string) (string, error)
-	Logout(refreshToken string) error
-	GetUserByEmail(email string) (*model.User, error)
+	Register(ctx context.Context, user *model.User) (*model.User, error)
+	Login(ctx context.Context, email, password, clientIP string) (*model.User, string, string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	// AuthenticateUser loads the user an access token was issued to. Users
+	// that were deleted or disabled since get ErrInvalidToken.
+	AuthenticateUser(ctx context.Context, userID uint) (*model.User, error)
+	Logout(ctx context.Context, refreshToken string) error
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	IssueTokens(ctx context.Context, user *model.User) (string, string, error)
+	UnlockAccount(ctx context.Context, userID uint) error
+	ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword, currentRefreshToken string) error
 }
 
 type AuthServiceImpl struct {
 	userRepository         repository.UserRepository
 	refreshTokenRepository repository.RefreshTokenRepository
+	loginProtectionService LoginProtectionService
+	passwordPolicy         *validation.PasswordPolicy
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
+	loginProtectionService LoginProtectionService,
+	passwordPolicy *validation.PasswordPolicy,
 ) AuthService {
 	return &AuthServiceImpl{
 		userRepository:         userRepo,
 		refreshTokenRepository: refreshTokenRepo,
+		loginProtectionService: loginProtectionService,
+		passwordPolicy:         passwordPolicy,
 	}
 }
 
-func (s *AuthServiceImpl) Register(user *model.User) (*model.User, error) {
+func (s *AuthServiceImpl) Register(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	// Check if user already exists
-	existingUser, err := s.userRepository.GetUserByEmail(user.Email)
+	existingUser, err := s.userRepository.GetUserByEmail(ctx, user.Email)
 	// If error is not gorm.ErrRecordNotFound, return error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
@@ -46,74 +67,94 @@ func (s *AuthServiceImpl) Register(user *model.User) (*model.User, error) {
 		return nil, ErrUserAlreadyExists
 	}
 
+	// Enforce password policy
+	if err := s.passwordPolicy.Validate(user.Password, user.Email); err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	if user.Password, err = helper.HashPassword(user.Password); err != nil {
 		return nil, err
 	}
 
 	// Create user
-	if err := s.userRepository.CreateUser(user); err != nil {
+	if err := s.userRepository.CreateUser(ctx, user); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
 // Login handles user login and returns both JWT and refresh token
-func (s *AuthServiceImpl) Login(email, password string) (*model.User, string, string, error) {
+func (s *AuthServiceImpl) Login(ctx context.Context, email, password, clientIP string) (*model.User, string, string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	// Refuse early while the account or client is throttled
+	if err := s.loginProtectionService.CheckAllowed(ctx, email, clientIP); err != nil {
+		if errors.Is(err, ErrLoginThrottled) {
+			metrics.LoginsFailed.WithLabelValues(metrics.ReasonThrottled).Inc()
+		}
+		return nil, "", "", err
+	}
+
 	// Get user by email
-	user, err := s.userRepository.GetUserByEmail(email)
+	user, err := s.userRepository.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, "", "", ErrInvalidCredentials
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", err
+		}
+		return nil, "", "", s.failLogin(ctx, email, clientIP, false)
 	}
 
 	// Verify password
 	if err := helper.ComparePassword(user.Password, password); err != nil {
-		return nil, "", "", ErrInvalidCredentials
+		return nil, "", "", s.failLogin(ctx, email, clientIP, true)
 	}
 
-	// Generate JWT
-	jwtToken, err := auth.GenerateJWT(user)
-	if err != nil {
+	if err := s.loginProtectionService.RecordSuccess(ctx, email); err != nil {
 		return nil, "", "", err
 	}
 
-	// Generate refresh token
-	refreshToken, err := auth.GenerateRefreshToken(user.ID)
-	if err != nil {
-		return nil, "", "", err
+	if user.Disabled {
+		metrics.LoginsFailed.WithLabelValues(metrics.ReasonAccountDisabled).Inc()
+		return nil, "", "", ErrAccountDisabled
 	}
 
-	// Save refresh token to database
-	refreshTokenModel := &model.RefreshToken{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(auth.RefreshTokenExpiration),
-	}
-	if err := s.refreshTokenRepository.CreateRefreshToken(refreshTokenModel); err != nil {
+	jwtToken, refreshToken, err := s.IssueTokens(ctx, user)
+	if err != nil {
 		return nil, "", "", err
 	}
 
 	return user, jwtToken, refreshToken, nil
 }
 
-func (s *AuthServiceImpl) RefreshToken(refreshToken string) (string, error) {
+func (s *AuthServiceImpl) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RefreshToken")
+	defer span.End()
+
 	// Get refresh token from database
-	storedToken, err := s.refreshTokenRepository.GetRefreshTokenByToken(refreshToken)
+	storedToken, err := s.refreshTokenRepository.GetRefreshTokenByToken(ctx, refreshToken)
 	if err != nil {
-		return "", ErrInvalidToken
+		return "", notFound(err, errInvalidRefreshToken)
 	}
 
 	// Check if token is revoked or expired
 	if storedToken.Revoked || auth.IsRefreshTokenExpired(storedToken.ExpiresAt) {
-		return "", ErrInvalidToken
+		return "", errInvalidRefreshToken
 	}
 
 	// Get user
-	user, err := s.userRepository.GetUserByID(storedToken.UserID)
+	user, err := s.userRepository.GetUserByID(ctx, storedToken.UserID)
 	if err != nil {
 		return "", err
 	}
 
+	// Disabled accounts get the same answer as a bad token, which makes
+	// clients sign the user out
+	if user.Disabled {
+		return "", errInvalidRefreshToken
+	}
+
 	// Generate new JWT
 	newJWT, err := auth.GenerateJWT(user)
 	if err != nil {
@@ -123,14 +164,116 @@ func (s *AuthServiceImpl) RefreshToken(refreshToken string) (string, error) {
 	return newJWT, nil
 }
 
-func (s *AuthServiceImpl) Logout(refreshToken string) error {
-	return s.refreshTokenRepository.RevokeRefreshToken(refreshToken)
+func (s *AuthServiceImpl) AuthenticateUser(ctx context.Context, userID uint) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.AuthenticateUser")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, ErrInvalidToken)
+	}
+	if user.Disabled {
+		return nil, ErrInvalidToken
+	}
+	return user, nil
 }
 
-func (s *AuthServiceImpl) GetUserByEmail(email string) (*model.User, error) {
-	user, err := s.userRepository.GetUserByEmail(email)
+func (s *AuthServiceImpl) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	return s.refreshTokenRepository.RevokeRefreshToken(ctx, refreshToken)
+}
+
+func (s *AuthServiceImpl) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetUserByEmail")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, ErrUserNotFound
+		return nil, notFound(err, ErrUserNotFound)
 	}
 	return user, nil
 }
+
+// IssueTokens generates a JWT and a persisted refresh token for an authenticated user
+func (s *AuthServiceImpl) IssueTokens(ctx context.Context, user *model.User) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.IssueTokens")
+	defer span.End()
+
+	// Generate JWT
+	jwtToken, err := auth.GenerateJWT(user)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Generate refresh token
+	refreshToken, err := auth.GenerateRefreshToken(user.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Save refresh token to database
+	refreshTokenModel := &model.RefreshToken{
+		Token:     refreshToken,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(auth.RefreshTokenExpiration),
+	}
+	if err := s.refreshTokenRepository.CreateRefreshToken(ctx, refreshTokenModel); err != nil {
+		return "", "", err
+	}
+
+	return jwtToken, refreshToken, nil
+}
+
+// failLogin records a failed attempt and returns the error for the caller.
+func (s *AuthServiceImpl) failLogin(ctx context.Context, email, clientIP string, knownAccount bool) error {
+	applog.FromContext(ctx).WarnContext(ctx, "login failed", "email", email, "client_ip", clientIP)
+	metrics.LoginsFailed.WithLabelValues(metrics.ReasonInvalidCredentials).Inc()
+
+	if err := s.loginProtectionService.RecordFailure(ctx, email, clientIP, knownAccount); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+func (s *AuthServiceImpl) UnlockAccount(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Start(ctx, "AuthService.UnlockAccount")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+	return s.loginProtectionService.Unlock(ctx, user.Email)
+}
+
+// ChangePassword sets a new password and signs out every other session. The
+// session holding currentRefreshToken, if any, stays signed in.
+func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword, currentRefreshToken string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangePassword")
+	defer span.End()
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, ErrUserNotFound)
+	}
+
+	if err := helper.ComparePassword(user.Password, currentPassword); err != nil {
+		return ErrInvalidCredentials.WithMessage("current password is incorrect")
+	}
+
+	if err := s.passwordPolicy.Validate(newPassword, user.Email); err != nil {
+		return err
+	}
+
+	if user.Password, err = helper.HashPassword(newPassword); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepository.RevokeUserRefreshTokensExcept(ctx, userID, currentRefreshToken)
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/notify"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type CollaboratorService interface {
+	GetCollaborators(ctx context.Context, formID uint, userID uint) (*dto.FormCollaboratorsResponse, error)
+	InviteCollaborator(ctx context.Context, formID uint, userID uint, req *dto.InviteCollaboratorRequest) (*dto.FormCollaboratorsResponse, error)
+	UpdateCollaborator(ctx context.Context, formID uint, userID uint, collaboratorID uint, role model.CollaboratorRole) (*dto.FormCollaboratorsResponse, error)
+	RemoveCollaborator(ctx context.Context, formID uint, userID uint, collaboratorID uint) error
+	TransferOwnership(ctx context.Context, formID uint, userID uint, newOwnerEmail string) (*dto.FormCollaboratorsResponse, error)
+	GetSharedForms(ctx context.Context, userID uint) ([]*model.FormCollaborator, error)
+}
+
+type CollaboratorServiceImpl struct {
+	collaboratorRepository repository.CollaboratorRepository
+	formRepository         repository.FormRepository
+	userRepository         repository.UserRepository
+	formService            FormService
+	authorizationService   FormAuthorizationService
+	notifier               notify.Notifier
+	frontendURL            string
+}
+
+func NewCollaboratorService(
+	collaboratorRepository repository.CollaboratorRepository,
+	formRepository repository.FormRepository,
+	userRepository repository.UserRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+	notifier notify.Notifier,
+	frontendURL string,
+) CollaboratorService {
+	return &CollaboratorServiceImpl{
+		collaboratorRepository: collaboratorRepository,
+		formRepository:         formRepository,
+		userRepository:         userRepository,
+		formService:            formService,
+		authorizationService:   authorizationService,
+		notifier:               notifier,
+		frontendURL:            frontendURL,
+	}
+}
+
+// GetCollaborators lists the form's owner and collaborators to anyone on the
+// form's team.
+func (s *CollaboratorServiceImpl) GetCollaborators(ctx context.Context, formID uint, userID uint) (*dto.FormCollaboratorsResponse, error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.GetCollaborators")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	return s.collaboratorsResponse(ctx, formID)
+}
+
+// InviteCollaborator gives the user registered under the request's email a
+// role on the form and tells them about it by email. Collaborators cannot
+// vote on the form, so users who already have cannot join its team.
+func (s *CollaboratorServiceImpl) InviteCollaborator(ctx context.Context, formID uint, userID uint, req *dto.InviteCollaboratorRequest) (*dto.FormCollaboratorsResponse, error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.InviteCollaborator")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	invitee, err := s.findUser(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == form.UserID {
+		return nil, ErrInvalidCollaborator.WithMessage("the form owner is already on the form")
+	}
+	_, err = s.collaboratorRepository.GetCollaborator(ctx, formID, invitee.ID)
+	if err == nil {
+		return nil, ErrInvalidCollaborator.WithMessage("this user is already a collaborator, change their role instead")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	voted, err := s.formRepository.UserSubmittedForm(ctx, invitee.ID, formID)
+	if err != nil {
+		return nil, err
+	}
+	if voted {
+		return nil, ErrInvalidCollaborator.WithMessage("this user has voted on the form and cannot join its team")
+	}
+
+	role := model.CollaboratorRole(req.Role)
+	if err := s.collaboratorRepository.AddCollaborator(ctx, &model.FormCollaborator{
+		FormID:      formID,
+		UserID:      invitee.ID,
+		Role:        role,
+		InvitedByID: &userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, form, invitee.Email, "You have been added to "+form.Title,
+		fmt.Sprintf("You are now %s on %q: %s", roleDescription(role), form.Title, s.formLink(form)))
+	return s.collaboratorsResponse(ctx, formID)
+}
+
+// UpdateCollaborator changes the role of one of the form's collaborators.
+func (s *CollaboratorServiceImpl) UpdateCollaborator(ctx context.Context, formID uint, userID uint, collaboratorID uint, role model.CollaboratorRole) (*dto.FormCollaboratorsResponse, error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.UpdateCollaborator")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	if err := s.collaboratorRepository.UpdateCollaboratorRole(ctx, formID, collaboratorID, role); err != nil {
+		return nil, notFound(err, ErrCollaboratorNotFound)
+	}
+	return s.collaboratorsResponse(ctx, formID)
+}
+
+// RemoveCollaborator takes a collaborator off the form. Owners remove anyone;
+// other collaborators can only leave the form themselves.
+func (s *CollaboratorServiceImpl) RemoveCollaborator(ctx context.Context, formID uint, userID uint, collaboratorID uint) error {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.RemoveCollaborator")
+	defer span.End()
+
+	if collaboratorID != userID {
+		if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+			return err
+		}
+	}
+	if err := s.collaboratorRepository.RemoveCollaborator(ctx, formID, collaboratorID); err != nil {
+		return notFound(err, ErrCollaboratorNotFound)
+	}
+	return nil
+}
+
+// TransferOwnership hands the form to the user registered under
+// newOwnerEmail. Only the owner can transfer the form, and they stay on it as
+// a co-owner. Users who voted on the form cannot take it over, since owners
+// may not vote on their own forms.
+func (s *CollaboratorServiceImpl) TransferOwnership(ctx context.Context, formID uint, userID uint, newOwnerEmail string) (*dto.FormCollaboratorsResponse, error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.TransferOwnership")
+	defer span.End()
+
+	isOwner, err := s.authorizationService.IsFormOwner(ctx, userID, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwner {
+		return nil, ErrNotFormOwner.WithMessage("only the form owner can transfer the form")
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	newOwner, err := s.findUser(ctx, newOwnerEmail)
+	if err != nil {
+		return nil, err
+	}
+	if newOwner.ID == userID {
+		return nil, ErrInvalidCollaborator.WithMessage("you already own this form")
+	}
+	voted, err := s.formRepository.UserSubmittedForm(ctx, newOwner.ID, formID)
+	if err != nil {
+		return nil, err
+	}
+	if voted {
+		return nil, ErrInvalidCollaborator.WithMessage("this user has voted on the form and cannot own it")
+	}
+
+	if err := s.collaboratorRepository.TransferOwnership(ctx, formID, userID, newOwner.ID); err != nil {
+		return nil, notFound(err, ErrNotFormOwner)
+	}
+
+	s.notify(ctx, form, newOwner.Email, "You now own "+form.Title,
+		fmt.Sprintf("%q has been transferred to you: %s", form.Title, s.formLink(form)))
+	return s.collaboratorsResponse(ctx, formID)
+}
+
+// GetSharedForms returns the forms other users share with the user, with the
+// user's role on each.
+func (s *CollaboratorServiceImpl) GetSharedForms(ctx context.Context, userID uint) ([]*model.FormCollaborator, error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.GetSharedForms")
+	defer span.End()
+
+	return s.collaboratorRepository.GetSharedForms(ctx, userID)
+}
+
+func (s *CollaboratorServiceImpl) findUser(ctx context.Context, email string) (*model.User, error) {
+	user, err := s.userRepository.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		field := apperr.FieldError{Field: "email", Message: "no user is registered with this email"}
+		return nil, ErrInvalidCollaborator.WithMessage(field.Message).WithFields(field)
+	}
+	return user, err
+}
+
+func (s *CollaboratorServiceImpl) collaboratorsResponse(ctx context.Context, formID uint) (*dto.FormCollaboratorsResponse, error) {
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	collaborators, err := s.collaboratorRepository.GetCollaborators(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.FormCollaboratorsResponse{
+		FormID: form.ID,
+		Owner: dto.CollaboratorResponse{
+			UserID:      form.UserID,
+			Email:       form.User.Email,
+			DisplayName: form.User.DisplayName,
+			Role:        string(model.CollaboratorOwner),
+		},
+		Collaborators: make([]dto.CollaboratorResponse, len(collaborators)),
+	}
+	for i, collaborator := range collaborators {
+		resp.Collaborators[i] = dto.CollaboratorResponse{
+			UserID:      collaborator.UserID,
+			Email:       collaborator.User.Email,
+			DisplayName: collaborator.User.DisplayName,
+			Role:        string(collaborator.Role),
+			AddedAt:     &collaborator.CreatedAt,
+		}
+	}
+	return resp, nil
+}
+
+func (s *CollaboratorServiceImpl) formLink(form *model.Form) string {
+	return s.frontendURL + "/polls/" + strconv.FormatUint(uint64(form.ID), 10)
+}
+
+// notify emails a user about a change to their access to the form. The
+// change stands even when the email cannot be sent.
+func (s *CollaboratorServiceImpl) notify(ctx context.Context, form *model.Form, to string, subject string, body string) {
+	err := s.notifier.Send(ctx, notify.Message{To: to, Subject: subject, Body: body})
+	if err != nil {
+		applog.FromContext(ctx).WarnContext(ctx, "failed to notify collaborator",
+			"form_id", form.ID,
+			"error", err)
+	}
+}
+
+func roleDescription(role model.CollaboratorRole) string {
+	switch role {
+	case model.CollaboratorOwner:
+		return "a co-owner"
+	case model.CollaboratorEditor:
+		return "an editor"
+	default:
+		return "a results viewer"
+	}
+}
@@ -1,21 +1,23 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/jinzhu/copier"
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
 	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
 	"gorm.io/gorm"
 )
 
 type DashboardService interface {
-	GetDashboardData(userID uint) (*dto.DashboardData, error)
-	UpdateUserFormStatus(userID uint, formID uint, status string) error
-	GetUserFormParticipation(userID uint, formID uint) (*dto.FormParticipation, error)
-	DeleteFormParticipation(userID uint, formID uint) error
-	GetUserActivities(userID uint, status string, page, perPage int) ([]dto.DashboardActivity, int64, error)
+	GetDashboardData(ctx context.Context, userID uint) (*dto.DashboardData, error)
+	UpdateUserFormStatus(ctx context.Context, userID uint, formID uint, status string) error
+	GetUserFormParticipation(ctx context.Context, userID uint, formID uint) (*dto.FormParticipation, error)
+	DeleteFormParticipation(ctx context.Context, userID uint, formID uint) error
+	GetUserActivities(ctx context.Context, userID uint, status string, page, perPage int) ([]dto.DashboardActivity, int64, error)
 }
 
 type DashboardServiceImpl struct {
@@ -36,9 +38,12 @@ func NewDashboardService(
 	}
 }
 
-func (s *DashboardServiceImpl) GetDashboardData(userID uint) (*dto.DashboardData, error) {
+func (s *DashboardServiceImpl) GetDashboardData(ctx context.Context, userID uint) (*dto.DashboardData, error) {
+	ctx, span := tracing.Start(ctx, "DashboardService.GetDashboardData")
+	defer span.End()
+
 	// Get statistics
-	available, inProgress, completed, recentActivity, err := s.dashboardRepository.GetUserFormStatistics(userID)
+	available, inProgress, completed, recentActivity, err := s.dashboardRepository.GetUserFormStatistics(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +56,7 @@ func (s *DashboardServiceImpl) GetDashboardData(userID uint) (*dto.DashboardData
 	}
 
 	// Get recent activity
-	activities, err := s.dashboardRepository.GetUserRecentActivity(userID, 5)
+	activities, err := s.dashboardRepository.GetUserRecentActivity(ctx, userID, 5)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +76,7 @@ func (s *DashboardServiceImpl) GetDashboardData(userID uint) (*dto.DashboardData
 	}
 
 	// Get forms with participation status
-	forms, err := s.GetUserFormsWithStatus(userID)
+	forms, err := s.GetUserFormsWithStatus(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -85,8 +90,11 @@ func (s *DashboardServiceImpl) GetDashboardData(userID uint) (*dto.DashboardData
 	return dashboardData, nil
 }
 
-func (s *DashboardServiceImpl) GetUserFormsWithStatus(userID uint) ([]dto.DashboardForm, error) {
-	forms, err := s.dashboardRepository.GetUserFormsWithParticipation(userID)
+func (s *DashboardServiceImpl) GetUserFormsWithStatus(ctx context.Context, userID uint) ([]dto.DashboardForm, error) {
+	ctx, span := tracing.Start(ctx, "DashboardService.GetUserFormsWithStatus")
+	defer span.End()
+
+	forms, err := s.dashboardRepository.GetUserFormsWithParticipation(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +102,7 @@ func (s *DashboardServiceImpl) GetUserFormsWithStatus(userID uint) ([]dto.Dashbo
 	var dashboardForms []dto.DashboardForm
 	for _, form := range forms {
 		// Get participation status
-		participation, err := s.dashboardRepository.GetUserFormParticipation(userID, form.ID)
+		participation, err := s.dashboardRepository.GetUserFormParticipation(ctx, userID, form.ID)
 		var status string
 		var startedAt, completedAt, lastModified *time.Time
 		var progress float64
@@ -118,7 +126,7 @@ func (s *DashboardServiceImpl) GetUserFormsWithStatus(userID uint) ([]dto.Dashbo
 		}
 
 		if status == "in_progress" {
-			draft, err := s.draftRepository.GetDraft(form.ID, userID)
+			draft, err := s.draftRepository.GetDraft(ctx, form.ID, userID)
 			if err == nil {
 				progress = draft.ProgressPercentage
 				lastModified = &draft.UpdatedAt
@@ -147,8 +155,11 @@ func (s *DashboardServiceImpl) GetUserFormsWithStatus(userID uint) ([]dto.Dashbo
 	return dashboardForms, nil
 }
 
-func (s *DashboardServiceImpl) UpdateUserFormStatus(userID uint, formID uint, status string) error {
-    return s.dashboardRepository.WithTransaction(func(tx *gorm.DB) error {
+func (s *DashboardServiceImpl) UpdateUserFormStatus(ctx context.Context, userID uint, formID uint, status string) error {
+	ctx, span := tracing.Start(ctx, "DashboardService.UpdateUserFormStatus")
+	defer span.End()
+
+    return s.dashboardRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
         participation, err := s.dashboardRepository.GetUserFormParticipationTx(tx, userID, formID)
         now := time.Now()
 
@@ -194,8 +205,11 @@ func (s *DashboardServiceImpl) UpdateUserFormStatus(userID uint, formID uint, st
 }
 
 
-func (s *DashboardServiceImpl) GetUserFormParticipation(userID uint, formID uint) (*dto.FormParticipation, error) {
-	participation, err := s.dashboardRepository.GetUserFormParticipation(userID, formID)
+func (s *DashboardServiceImpl) GetUserFormParticipation(ctx context.Context, userID uint, formID uint) (*dto.FormParticipation, error) {
+	ctx, span := tracing.Start(ctx, "DashboardService.GetUserFormParticipation")
+	defer span.End()
+
+	participation, err := s.dashboardRepository.GetUserFormParticipation(ctx, userID, formID)
 	if err != nil {
 		return nil, err
 	}
@@ -206,12 +220,18 @@ func (s *DashboardServiceImpl) GetUserFormParticipation(userID uint, formID uint
 	}, nil
 }
 
-func (s *DashboardServiceImpl) DeleteFormParticipation(userID uint, formID uint) error {
-	return s.dashboardRepository.DeleteFormParticipation(userID, formID)
+func (s *DashboardServiceImpl) DeleteFormParticipation(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "DashboardService.DeleteFormParticipation")
+	defer span.End()
+
+	return s.dashboardRepository.DeleteFormParticipation(ctx, userID, formID)
 }
 
-func (s *DashboardServiceImpl) GetUserActivities(userID uint, status string, page, perPage int) ([]dto.DashboardActivity, int64, error) {
-	activities, total, err := s.dashboardRepository.GetUserActivities(userID, status, page, perPage)
+func (s *DashboardServiceImpl) GetUserActivities(ctx context.Context, userID uint, status string, page, perPage int) ([]dto.DashboardActivity, int64, error) {
+	ctx, span := tracing.Start(ctx, "DashboardService.GetUserActivities")
+	defer span.End()
+
+	activities, total, err := s.dashboardRepository.GetUserActivities(ctx, userID, status, page, perPage)
 	if err != nil {
 		return nil, 0, err
 	}
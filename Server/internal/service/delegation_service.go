@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type DelegationService interface {
+	GetDelegation(ctx context.Context, formID uint, userID uint) (*dto.DelegationResponse, error)
+	Delegate(ctx context.Context, formID uint, userID uint, delegateEmail string) (*dto.DelegationResponse, error)
+	RevokeDelegation(ctx context.Context, formID uint, userID uint) error
+}
+
+type DelegationServiceImpl struct {
+	delegationRepository repository.DelegationRepository
+	formRepository       repository.FormRepository
+	voterRollRepository  repository.VoterRollRepository
+	userRepository       repository.UserRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+}
+
+func NewDelegationService(
+	delegationRepository repository.DelegationRepository,
+	formRepository repository.FormRepository,
+	voterRollRepository repository.VoterRollRepository,
+	userRepository repository.UserRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+) DelegationService {
+	return &DelegationServiceImpl{
+		delegationRepository: delegationRepository,
+		formRepository:       formRepository,
+		voterRollRepository:  voterRollRepository,
+		userRepository:       userRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+	}
+}
+
+// GetDelegation returns who holds the user's vote on the form and whose votes
+// the user holds.
+func (s *DelegationServiceImpl) GetDelegation(ctx context.Context, formID uint, userID uint) (*dto.DelegationResponse, error) {
+	ctx, span := tracing.Start(ctx, "DelegationService.GetDelegation")
+	defer span.End()
+
+	if _, err := s.formService.GetForm(ctx, formID); err != nil {
+		return nil, err
+	}
+	return s.delegationResponse(ctx, formID, userID)
+}
+
+// Delegate hands the user's vote on the form to the user registered under
+// delegateEmail, replacing any earlier delegation. Votes are delegated at most
+// once, so delegates cannot pass them on and cycles cannot form.
+func (s *DelegationServiceImpl) Delegate(ctx context.Context, formID uint, userID uint, delegateEmail string) (*dto.DelegationResponse, error) {
+	ctx, span := tracing.Start(ctx, "DelegationService.Delegate")
+	defer span.End()
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if form.Closed(time.Now()) {
+		return nil, ErrFormClosed
+	}
+
+	if err := s.authorizationService.CanSubmitForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	submitted, err := s.formRepository.UserSubmittedForm(ctx, userID, formID)
+	if err != nil {
+		return nil, err
+	}
+	if submitted {
+		return nil, ErrInvalidDelegation.WithMessage("your vote on this form has already been cast")
+	}
+
+	delegate, err := s.userRepository.GetUserByEmail(ctx, strings.TrimSpace(delegateEmail))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		field := apperr.FieldError{Field: "delegate_email", Message: "no user is registered with this email"}
+		return nil, ErrInvalidDelegation.WithMessage(field.Message).WithFields(field)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDelegate(ctx, form, userID, delegate); err != nil {
+		return nil, err
+	}
+
+	err = s.delegationRepository.SaveDelegation(ctx, &model.Delegation{
+		FormID:      formID,
+		DelegatorID: userID,
+		DelegateID:  delegate.ID,
+		CreatedAt:   time.Now(),
+	})
+	switch {
+	case errors.Is(err, repository.ErrDelegateHasDelegated):
+		return nil, ErrInvalidDelegation.WithMessage("the delegate has delegated their own vote on this form")
+	case errors.Is(err, repository.ErrDelegatorHoldsProxies):
+		return nil, ErrInvalidDelegation.WithMessage("you hold the votes of other voters and cannot delegate your own")
+	case err != nil:
+		return nil, notFound(err, ErrFormNotFound)
+	}
+	return s.delegationResponse(ctx, formID, userID)
+}
+
+// checkDelegate checks that the delegate can hold the user's vote. Whether the
+// delegation would chain is checked as it is saved.
+func (s *DelegationServiceImpl) checkDelegate(ctx context.Context, form *model.Form, userID uint, delegate *model.User) error {
+	if delegate.ID == userID {
+		return ErrInvalidDelegation.WithMessage("you cannot delegate your vote to yourself")
+	}
+	role, err := s.authorizationService.FormRole(ctx, delegate.ID, form.ID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return ErrInvalidDelegation.WithMessage("the form's owner and collaborators cannot vote on it")
+	}
+
+	rollSize, err := s.voterRollRepository.CountVoterRoll(ctx, form.ID)
+	if err != nil {
+		return err
+	}
+	if rollSize > 0 {
+		eligible, err := s.voterRollRepository.IsOnVoterRoll(ctx, form.ID, delegate.ID)
+		if err != nil {
+			return err
+		}
+		if !eligible {
+			return ErrInvalidDelegation.WithMessage("the delegate is not on the voter roll of this form")
+		}
+	}
+
+	return nil
+}
+
+// RevokeDelegation takes the user's vote back from their delegate. A ballot the
+// delegate already cast stays counted until the user votes themselves.
+func (s *DelegationServiceImpl) RevokeDelegation(ctx context.Context, formID uint, userID uint) error {
+	ctx, span := tracing.Start(ctx, "DelegationService.RevokeDelegation")
+	defer span.End()
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return err
+	}
+	if form.Closed(time.Now()) {
+		return ErrFormClosed
+	}
+
+	if _, err := s.delegationRepository.GetDelegation(ctx, formID, userID); err != nil {
+		return notFound(err, ErrDelegationNotFound)
+	}
+	return s.delegationRepository.DeleteDelegation(ctx, formID, userID)
+}
+
+func (s *DelegationServiceImpl) delegationResponse(ctx context.Context, formID uint, userID uint) (*dto.DelegationResponse, error) {
+	resp := &dto.DelegationResponse{
+		FormID:  formID,
+		Proxies: []dto.DelegateResponse{},
+	}
+
+	delegation, err := s.delegationRepository.GetDelegation(ctx, formID, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		voted, err := s.formRepository.UserSubmittedForm(ctx, userID, formID)
+		if err != nil {
+			return nil, err
+		}
+		delegate := toDelegateResponse(&delegation.Delegate, voted, delegation.CreatedAt)
+		resp.Delegate = &delegate
+	}
+
+	proxies, err := s.delegationRepository.GetDelegationsTo(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, proxy := range proxies {
+		voted, err := s.formRepository.UserSubmittedForm(ctx, proxy.DelegatorID, formID)
+		if err != nil {
+			return nil, err
+		}
+		resp.Proxies = append(resp.Proxies, toDelegateResponse(&proxy.Delegator, voted, proxy.CreatedAt))
+	}
+	return resp, nil
+}
+
+func toDelegateResponse(user *model.User, voted bool, delegatedAt time.Time) dto.DelegateResponse {
+	return dto.DelegateResponse{
+		UserID:      user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Voted:       voted,
+		DelegatedAt: delegatedAt,
+	}
+}
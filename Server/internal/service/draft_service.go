@@ -1,19 +1,22 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/metrics"
 	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
 )
 
 type DraftService interface {
-	SaveDraft(userID uint, formID uint, req *dto.SaveDraftRequest) (*model.DraftSubmission, error)
-	GetDraft(userID uint, formID uint) (*dto.DraftSubmissionResponse, error)
-	DeleteDraft(userID uint, formID uint) error
-	CalculateProgress(formID uint, answers []dto.AnswerSubmission) (float64, error)
+	SaveDraft(ctx context.Context, userID uint, formID uint, req *dto.SaveDraftRequest) (*model.DraftSubmission, error)
+	GetDraft(ctx context.Context, userID uint, formID uint) (*dto.DraftSubmissionResponse, error)
+	DeleteDraft(ctx context.Context, userID uint, formID uint) error
+	CalculateProgress(ctx context.Context, formID uint, answers []dto.AnswerSubmission) (float64, error)
 }
 
 type DraftServiceImpl struct {
@@ -31,8 +34,11 @@ func NewDraftService(
 	}
 }
 
-func (s *DraftServiceImpl) SaveDraft(userID uint, formID uint, req *dto.SaveDraftRequest) (*model.DraftSubmission, error) {
-	progress, err := s.CalculateProgress(formID, req.Answers)
+func (s *DraftServiceImpl) SaveDraft(ctx context.Context, userID uint, formID uint, req *dto.SaveDraftRequest) (*model.DraftSubmission, error) {
+	ctx, span := tracing.Start(ctx, "DraftService.SaveDraft")
+	defer span.End()
+
+	progress, err := s.CalculateProgress(ctx, formID, req.Answers)
 	if err != nil {
 		return nil, err
 	}
@@ -42,15 +48,16 @@ func (s *DraftServiceImpl) SaveDraft(userID uint, formID uint, req *dto.SaveDraf
 		return nil, err
 	}
 
-	existingDraft, err := s.draftRepository.GetDraft(formID, userID)
+	existingDraft, err := s.draftRepository.GetDraft(ctx, formID, userID)
 	if err == nil {
 		existingDraft.Answers = answersJSON
 		existingDraft.ProgressPercentage = progress
 		existingDraft.UpdatedAt = time.Now()
 
-		if err := s.draftRepository.SaveDraft(existingDraft); err != nil {
+		if err := s.draftRepository.SaveDraft(ctx, existingDraft); err != nil {
 			return nil, err
 		}
+		metrics.DraftsSaved.Inc()
 		return existingDraft, nil
 	}
 
@@ -61,15 +68,19 @@ func (s *DraftServiceImpl) SaveDraft(userID uint, formID uint, req *dto.SaveDraf
 		ProgressPercentage: progress,
 	}
 
-	if err := s.draftRepository.SaveDraft(draft); err != nil {
+	if err := s.draftRepository.SaveDraft(ctx, draft); err != nil {
 		return nil, err
 	}
+	metrics.DraftsSaved.Inc()
 
 	return draft, nil
 }
 
-func (s *DraftServiceImpl) GetDraft(userID uint, formID uint) (*dto.DraftSubmissionResponse, error) {
-	draft, err := s.draftRepository.GetDraft(formID, userID)
+func (s *DraftServiceImpl) GetDraft(ctx context.Context, userID uint, formID uint) (*dto.DraftSubmissionResponse, error) {
+	ctx, span := tracing.Start(ctx, "DraftService.GetDraft")
+	defer span.End()
+
+	draft, err := s.draftRepository.GetDraft(ctx, formID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +91,7 @@ func (s *DraftServiceImpl) GetDraft(userID uint, formID uint) (*dto.DraftSubmiss
 	}
 
 	// Get form data
-	form, err := s.formRepository.GetForm(formID)
+	form, err := s.formRepository.GetForm(ctx, formID)
 	if err != nil {
 		return nil, err
 	}
@@ -99,12 +110,18 @@ func (s *DraftServiceImpl) GetDraft(userID uint, formID uint) (*dto.DraftSubmiss
 	return response, nil
 }
 
-func (s *DraftServiceImpl) DeleteDraft(userID uint, formID uint) error {
-	return s.draftRepository.DeleteDraft(formID, userID)
+func (s *DraftServiceImpl) DeleteDraft(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "DraftService.DeleteDraft")
+	defer span.End()
+
+	return s.draftRepository.DeleteDraft(ctx, formID, userID)
 }
 
-func (s *DraftServiceImpl) CalculateProgress(formID uint, answers []dto.AnswerSubmission) (float64, error) {
-	form, err := s.formRepository.GetForm(formID)
+func (s *DraftServiceImpl) CalculateProgress(ctx context.Context, formID uint, answers []dto.AnswerSubmission) (float64, error) {
+	ctx, span := tracing.Start(ctx, "DraftService.CalculateProgress")
+	defer span.End()
+
+	form, err := s.formRepository.GetForm(ctx, formID)
 	if err != nil {
 		return 0, err
 	}
@@ -116,7 +133,7 @@ func (s *DraftServiceImpl) CalculateProgress(formID uint, answers []dto.AnswerSu
 
 	answeredQuestions := 0
 	for _, answer := range answers {
-		if len(answer.OptionIDs) > 0 || answer.Text != "" {
+		if len(answer.OptionIDs) > 0 || len(answer.Ranking) > 0 || len(answer.Scores) > 0 || answer.Abstain || answer.Text != "" {
 			answeredQuestions++
 		}
 	}
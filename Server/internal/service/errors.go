@@ -1,16 +1,73 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"net/http"
 
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/validation"
+	"gorm.io/gorm"
+)
+
+// Errors returned by services. Their codes are part of the API contract and
+// must not change once released.
 var (
-	ErrInvalidTitle            = errors.New("title must be at least 5 characters long")
-	ErrFormNotFound            = errors.New("form not found")
-	ErrUserAlreadyExists       = errors.New("user already exists")
-	ErrInvalidCredentials      = errors.New("invalid credentials")
-	ErrInvalidForm             = errors.New("invalid form")
-	ErrInvalidToken            = errors.New("invalid token")
-	ErrSubmissionAlreadyExists = errors.New("user has already submitted the form")
-	ErrUserNotFound            = errors.New("user not found")
-	ErrNotFormOwner            = errors.New("user is not the owner of this form")
-	ErrCannotSubmitOwnForm     = errors.New("user cannot submit their own form")
+	ErrInvalidTitle            = apperr.New(http.StatusUnprocessableEntity, "invalid_title", "title must be at least 5 characters long")
+	ErrFormNotFound            = apperr.New(http.StatusNotFound, "form_not_found", "form not found")
+	ErrUserAlreadyExists       = apperr.New(http.StatusConflict, "user_already_exists", "user already exists")
+	ErrInvalidCredentials      = apperr.New(http.StatusUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrInvalidForm             = apperr.New(http.StatusBadRequest, "invalid_form", "invalid form")
+	ErrInvalidToken            = apperr.New(http.StatusUnauthorized, "invalid_token", "invalid token")
+	ErrSubmissionAlreadyExists = apperr.New(http.StatusConflict, "submission_already_exists", "user has already submitted the form")
+	ErrUserNotFound            = apperr.New(http.StatusNotFound, "user_not_found", "user not found")
+	ErrNotFormOwner            = apperr.New(http.StatusForbidden, "not_form_owner", "user is not the owner of this form")
+	ErrCannotSubmitOwnForm     = apperr.New(http.StatusForbidden, "cannot_submit_own_form", "user cannot submit their own form")
+	ErrOIDCDisabled            = apperr.New(http.StatusNotFound, "oidc_disabled", "single sign-on is not enabled")
+	ErrInvalidOIDCState        = apperr.New(http.StatusBadRequest, "invalid_oidc_state", "invalid or expired single sign-on state")
+	ErrOIDCUnavailable         = apperr.New(http.StatusBadGateway, "oidc_unavailable", "single sign-on provider is unavailable")
+	ErrEmailNotVerified        = apperr.New(http.StatusForbidden, "email_not_verified", "identity provider did not verify the email address")
+	ErrInvalidScope            = apperr.New(http.StatusUnprocessableEntity, "invalid_scope", "invalid token scope")
+	ErrInvalidTokenExpiry      = apperr.New(http.StatusUnprocessableEntity, "invalid_token_expiry", "token expiry must be in the future")
+	ErrAPITokenNotFound        = apperr.New(http.StatusNotFound, "api_token_not_found", "api token not found")
+	ErrAccountDisabled         = apperr.New(http.StatusForbidden, "account_disabled", "account is disabled")
+	ErrInvalidRole             = apperr.New(http.StatusUnprocessableEntity, "invalid_role", "invalid role")
+	ErrCannotModifySelf        = apperr.New(http.StatusConflict, "cannot_modify_self", "admins cannot disable or demote their own account")
+	ErrInvalidVerification     = apperr.New(http.StatusBadRequest, "invalid_verification_token", "invalid or expired verification link")
+	ErrInvalidTimezone         = apperr.New(http.StatusUnprocessableEntity, "invalid_timezone", "invalid time zone")
+	ErrFormClosed              = apperr.New(http.StatusConflict, "form_closed", "voting on this form has closed")
+	ErrNotEligibleVoter        = apperr.New(http.StatusForbidden, "not_eligible_voter", "user is not on the voter roll of this form")
+	ErrInvalidDelegation       = apperr.New(http.StatusUnprocessableEntity, "invalid_delegation", "invalid delegation")
+	ErrDelegationNotFound      = apperr.New(http.StatusNotFound, "delegation_not_found", "user has not delegated their vote on this form")
+	ErrNotDelegate             = apperr.New(http.StatusForbidden, "not_delegate", "user is not the delegate of this voter")
+	ErrTieBreakNotAllowed      = apperr.New(http.StatusConflict, "tie_break_not_allowed", "there is no tie for the form owner to break")
+	ErrRunoffNotAllowed        = apperr.New(http.StatusConflict, "runoff_not_allowed", "this question cannot have a runoff")
+	ErrNominationsClosed       = apperr.New(http.StatusConflict, "nominations_closed", "nominations are not open on this form")
+	ErrVotingNotOpen           = apperr.New(http.StatusConflict, "voting_not_open", "voting opens once the nomination phase ends")
+	ErrNominationNotFound      = apperr.New(http.StatusNotFound, "nomination_not_found", "nomination not found")
+	ErrInvalidNomination       = apperr.New(http.StatusUnprocessableEntity, "invalid_nomination", "invalid nomination")
+	ErrResultsNotVisible       = apperr.New(http.StatusForbidden, "results_not_visible", "the results of this form are not visible to you")
+	ErrResultsEmbargoed        = apperr.New(http.StatusForbidden, "results_embargoed", "the results of this form are embargoed")
+	ErrResultsLinkNotFound     = apperr.New(http.StatusNotFound, "results_link_not_found", "the share link is invalid or has been revoked")
+	ErrInsufficientFormRole    = apperr.New(http.StatusForbidden, "insufficient_form_role", "your role on this form does not allow this")
+	ErrInvalidCollaborator     = apperr.New(http.StatusUnprocessableEntity, "invalid_collaborator", "invalid collaborator")
+	ErrCollaboratorNotFound    = apperr.New(http.StatusNotFound, "collaborator_not_found", "user is not a collaborator on this form")
+	ErrReauthRequired          = apperr.New(http.StatusForbidden, "reauthentication_required", "confirm this change with the code sent to your email address")
+	ErrReauthByPassword        = apperr.New(http.StatusConflict, "reauthentication_by_password", "this account confirms changes with its password")
+	ErrInvalidAnswer           = validation.ErrInvalidAnswer
+
+	// ErrLoginThrottled is returned when too many failed logins were made for
+	// an account or from a client IP. The copy returned by the login
+	// protection service carries how long the caller has to wait.
+	ErrLoginThrottled = apperr.New(http.StatusTooManyRequests, "login_throttled", "too many failed login attempts, try again later")
 )
+
+var errInvalidRefreshToken = ErrInvalidToken.WithMessage("invalid refresh token")
+
+// notFound maps a missing record to the given sentinel error. Any other error,
+// such as a timeout, is passed through so it is not reported as a 404.
+func notFound(err error, sentinel error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return sentinel
+	}
+	return err
+}
@@ -1,37 +1,112 @@
 package service
 
-import "github.com/luneto10/voting-system/internal/repository"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
 
 type FormAuthorizationService interface {
-	IsFormOwner(userID uint, formID uint) (bool, error)
-	CanSubmitForm(userID uint, formID uint) error
-	CanViewFormResults(userID uint, formID uint) error
+	IsFormOwner(ctx context.Context, userID uint, formID uint) (bool, error)
+	CanSubmitForm(ctx context.Context, userID uint, formID uint) error
+	CanSubmitOnBehalf(ctx context.Context, proxyID uint, delegatorID uint, formID uint) error
+	CanNominate(ctx context.Context, userID uint, formID uint) error
+	FormRole(ctx context.Context, userID uint, formID uint) (model.CollaboratorRole, error)
+	CanViewForm(ctx context.Context, userID uint, formID uint) error
+	CanEditForm(ctx context.Context, userID uint, formID uint) error
+	CanManageForm(ctx context.Context, userID uint, formID uint) error
+	CanViewFormResults(ctx context.Context, userID uint, formID uint) error
+	CanViewSharedResults(ctx context.Context, token string) (uint, error)
 }
 
 type FormAuthorizationServiceImpl struct {
-	formRepository repository.FormRepository
+	formRepository         repository.FormRepository
+	voterRollRepository    repository.VoterRollRepository
+	delegationRepository   repository.DelegationRepository
+	collaboratorRepository repository.CollaboratorRepository
 }
 
-func NewFormAuthorizationService(formRepository repository.FormRepository) FormAuthorizationService {
-	return &FormAuthorizationServiceImpl{formRepository: formRepository}
+func NewFormAuthorizationService(
+	formRepository repository.FormRepository,
+	voterRollRepository repository.VoterRollRepository,
+	delegationRepository repository.DelegationRepository,
+	collaboratorRepository repository.CollaboratorRepository,
+) FormAuthorizationService {
+	return &FormAuthorizationServiceImpl{
+		formRepository:         formRepository,
+		voterRollRepository:    voterRollRepository,
+		delegationRepository:   delegationRepository,
+		collaboratorRepository: collaboratorRepository,
+	}
 }
 
-func (s *FormAuthorizationServiceImpl) IsFormOwner(userID uint, formID uint) (bool, error) {
-	return s.formRepository.IsFormOwner(userID, formID)
+func (s *FormAuthorizationServiceImpl) IsFormOwner(ctx context.Context, userID uint, formID uint) (bool, error) {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.IsFormOwner")
+	defer span.End()
+
+	return s.formRepository.IsFormOwner(ctx, userID, formID)
 }
 
-func (s *FormAuthorizationServiceImpl) CanSubmitForm(userID uint, formID uint) error {
-	// Check if user is trying to submit their own form
-	isOwner, err := s.IsFormOwner(userID, formID)
-	if err != nil {
+// CanSubmitForm checks that the user may vote on the form. The form's team,
+// its owner and collaborators in every role, runs the vote and so cannot take
+// part in it.
+func (s *FormAuthorizationServiceImpl) CanSubmitForm(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanSubmitForm")
+	defer span.End()
+
+	if err := s.checkNotOnTeam(ctx, userID, formID); err != nil {
 		return err
 	}
-	if isOwner {
-		return ErrCannotSubmitOwnForm
+
+	if err := s.checkVoterRoll(ctx, userID, formID); err != nil {
+		return err
+	}
+
+	// Check if user has already submitted the form. A ballot their delegate
+	// cast for them does not count: voting themselves overrides it
+	submission, err := s.formRepository.GetUserSubmission(ctx, userID, formID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && submission.CastByID == nil {
+		return ErrSubmissionAlreadyExists
 	}
 
-	// Check if user has already submitted the form
-	submitted, err := s.formRepository.UserSubmittedForm(userID, formID)
+	return nil
+}
+
+// CanSubmitOnBehalf checks that the proxy holds the delegator's vote on the
+// form and that nobody has cast it yet.
+func (s *FormAuthorizationServiceImpl) CanSubmitOnBehalf(ctx context.Context, proxyID uint, delegatorID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanSubmitOnBehalf")
+	defer span.End()
+
+	delegation, err := s.delegationRepository.GetDelegation(ctx, formID, delegatorID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err != nil || delegation.DelegateID != proxyID {
+		return ErrNotDelegate
+	}
+
+	// The proxy may have joined the form's team since
+	if err := s.checkNotOnTeam(ctx, proxyID, formID); err != nil {
+		return err
+	}
+
+	// The roll may have changed since the vote was delegated
+	if err := s.checkVoterRoll(ctx, delegatorID, formID); err != nil {
+		return err
+	}
+
+	submitted, err := s.formRepository.UserSubmittedForm(ctx, delegatorID, formID)
 	if err != nil {
 		return err
 	}
@@ -42,13 +117,198 @@ func (s *FormAuthorizationServiceImpl) CanSubmitForm(userID uint, formID uint) e
 	return nil
 }
 
-func (s *FormAuthorizationServiceImpl) CanViewFormResults(userID uint, formID uint) error {
-	isOwner, err := s.IsFormOwner(userID, formID)
+// CanNominate checks that the user may propose and support nominations on the
+// form: anyone who may vote on it, whether or not they have voted yet.
+func (s *FormAuthorizationServiceImpl) CanNominate(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanNominate")
+	defer span.End()
+
+	if err := s.checkNotOnTeam(ctx, userID, formID); err != nil {
+		return err
+	}
+	return s.checkVoterRoll(ctx, userID, formID)
+}
+
+// checkNotOnTeam rejects the form's owner and collaborators, who cannot vote
+// or nominate on it.
+func (s *FormAuthorizationServiceImpl) checkNotOnTeam(ctx context.Context, userID uint, formID uint) error {
+	role, err := s.FormRole(ctx, userID, formID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return ErrCannotSubmitOwnForm.WithMessage("the form's owner and collaborators cannot vote on it")
+	}
+	return nil
+}
+
+// checkVoterRoll rejects users missing from the form's voter roll. Forms
+// without a roll accept everyone.
+func (s *FormAuthorizationServiceImpl) checkVoterRoll(ctx context.Context, userID uint, formID uint) error {
+	rollSize, err := s.voterRollRepository.CountVoterRoll(ctx, formID)
 	if err != nil {
 		return err
 	}
-	if !isOwner {
+	if rollSize == 0 {
+		return nil
+	}
+
+	eligible, err := s.voterRollRepository.IsOnVoterRoll(ctx, formID, userID)
+	if err != nil {
+		return err
+	}
+	if !eligible {
+		return ErrNotEligibleVoter
+	}
+	return nil
+}
+
+// FormRole returns the user's role on the form: owner for the user who owns
+// it, their collaborator role for the rest of its team, and the empty role for
+// everyone else.
+func (s *FormAuthorizationServiceImpl) FormRole(ctx context.Context, userID uint, formID uint) (model.CollaboratorRole, error) {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.FormRole")
+	defer span.End()
+
+	isOwner, err := s.IsFormOwner(ctx, userID, formID)
+	if err != nil {
+		return "", err
+	}
+	if isOwner {
+		return model.CollaboratorOwner, nil
+	}
+
+	collaborator, err := s.collaboratorRepository.GetCollaborator(ctx, formID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return collaborator.Role, nil
+}
+
+// CanViewForm checks that the user is on the form's team, in any role, and so
+// may see the form with its voters and results.
+func (s *FormAuthorizationServiceImpl) CanViewForm(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanViewForm")
+	defer span.End()
+
+	return s.requireRole(ctx, userID, formID, model.CollaboratorResultsViewer)
+}
+
+// CanEditForm checks that the user may change the form and run its vote: its
+// owner, co-owners and editors.
+func (s *FormAuthorizationServiceImpl) CanEditForm(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanEditForm")
+	defer span.End()
+
+	return s.requireRole(ctx, userID, formID, model.CollaboratorEditor)
+}
+
+// CanManageForm checks that the user may delete the form, decide who sees its
+// results and manage its team: its owner and co-owners.
+func (s *FormAuthorizationServiceImpl) CanManageForm(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanManageForm")
+	defer span.End()
+
+	return s.requireRole(ctx, userID, formID, model.CollaboratorOwner)
+}
+
+// requireRole checks that the user has at least the required role on the
+// form. Users outside the team of an existing form get ErrNotFormOwner.
+func (s *FormAuthorizationServiceImpl) requireRole(ctx context.Context, userID uint, formID uint, required model.CollaboratorRole) error {
+	role, err := s.FormRole(ctx, userID, formID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		if _, err := s.formRepository.GetForm(ctx, formID); err != nil {
+			return notFound(err, ErrFormNotFound)
+		}
+		return ErrNotFormOwner
+	}
+	if !role.Includes(required) {
+		return ErrInsufficientFormRole.WithMessage(fmt.Sprintf("this requires the %s role on the form", required))
+	}
+	return nil
+}
+
+// CanViewFormResults checks the form's results visibility policy for the
+// user. The form's team always sees the results; everyone else waits for the
+// embargo to pass and needs the policy to allow them.
+func (s *FormAuthorizationServiceImpl) CanViewFormResults(ctx context.Context, userID uint, formID uint) error {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanViewFormResults")
+	defer span.End()
+
+	role, err := s.FormRole(ctx, userID, formID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return nil
+	}
+
+	form, err := s.formRepository.GetForm(ctx, formID)
+	if err != nil {
+		return notFound(err, ErrFormNotFound)
+	}
+	now := time.Now()
+	if err := checkEmbargo(form, now); err != nil {
+		return err
+	}
+
+	switch form.ResultsVisibility {
+	case model.ResultsVoters:
+		voted, err := s.formRepository.UserSubmittedForm(ctx, userID, formID)
+		if err != nil {
+			return err
+		}
+		if !voted {
+			return ErrResultsNotVisible.WithMessage("the results of this form are visible once you have voted")
+		}
+		return nil
+	case model.ResultsClosed:
+		if !form.Closed(now) {
+			return ErrResultsNotVisible.WithMessage("the results of this form are visible once voting closes")
+		}
+		return nil
+	case model.ResultsLink:
+		return ErrResultsNotVisible.WithMessage("the results of this form are only shared by link")
+	default:
 		return ErrNotFormOwner
 	}
+}
+
+// CanViewSharedResults checks a results share link and returns the form it
+// was made for. Links stop working when the form no longer shares its results
+// by link or the owner makes a new one.
+func (s *FormAuthorizationServiceImpl) CanViewSharedResults(ctx context.Context, token string) (uint, error) {
+	ctx, span := tracing.Start(ctx, "FormAuthorizationService.CanViewSharedResults")
+	defer span.End()
+
+	formID, version, err := auth.ParseResultsLink(token)
+	if err != nil {
+		return 0, ErrResultsLinkNotFound
+	}
+
+	form, err := s.formRepository.GetForm(ctx, formID)
+	if err != nil {
+		return 0, notFound(err, ErrResultsLinkNotFound)
+	}
+	if form.ResultsVisibility != model.ResultsLink || form.ResultsLinkVersion != version {
+		return 0, ErrResultsLinkNotFound
+	}
+	if err := checkEmbargo(form, time.Now()); err != nil {
+		return 0, err
+	}
+	return form.ID, nil
+}
+
+// checkEmbargo rejects viewers of a form whose results are still embargoed.
+func checkEmbargo(form *model.Form, now time.Time) error {
+	if form.ResultsEmbargoUntil != nil && now.Before(*form.ResultsEmbargoUntil) {
+		return ErrResultsEmbargoed.WithMessage("the results of this form are embargoed until " + form.ResultsEmbargoUntil.Format(time.RFC3339))
+	}
 	return nil
 }
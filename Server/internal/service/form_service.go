@@ -1,67 +1,175 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/metrics"
 	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tally"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"github.com/luneto10/voting-system/internal/validation"
 )
 
+const defaultMaxScore = 10
+
 type FormService interface {
-	CreateForm(f *model.Form) (*model.Form, error)
-	GetForm(id uint) (*model.Form, error)
-	UpdateForm(id uint, userID uint, updateForm *dto.UpdateFormRequest) (*model.Form, error)
-	DeleteForm(id uint, userID uint) error
-	GetFormsByUserID(userID uint) ([]*model.Form, error)
+	CreateForm(ctx context.Context, f *model.Form) (*model.Form, error)
+	GetForm(ctx context.Context, id uint) (*model.Form, error)
+	UpdateForm(ctx context.Context, id uint, userID uint, updateForm *dto.UpdateFormRequest) (*model.Form, error)
+	DeleteForm(ctx context.Context, id uint, userID uint) error
+	GetFormsByUserID(ctx context.Context, userID uint) ([]*model.Form, error)
 }
 
 type FormServiceImpl struct {
 	formRepository       repository.FormRepository
+	nominationRepository repository.NominationRepository
 	authorizationService FormAuthorizationService
 }
 
 func NewFormService(
 	formRepository repository.FormRepository,
+	nominationRepository repository.NominationRepository,
 	authorizationService FormAuthorizationService,
 ) FormService {
 	return &FormServiceImpl{
 		formRepository:       formRepository,
+		nominationRepository: nominationRepository,
 		authorizationService: authorizationService,
 	}
 }
 
-func (s *FormServiceImpl) CreateForm(f *model.Form) (*model.Form, error) {
-	if err := s.formRepository.CreateForm(f); err != nil {
+func (s *FormServiceImpl) CreateForm(ctx context.Context, f *model.Form) (*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "FormService.CreateForm")
+	defer span.End()
+
+	if err := validation.ValidateQuorum(f); err != nil {
+		return nil, err
+	}
+	for i := range f.Questions {
+		applyTallyDefaults(&f.Questions[i])
+		if err := validation.ValidateQuestion(i, &f.Questions[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := validation.ValidateNominationPhase(f); err != nil {
+		return nil, err
+	}
+	if err := assignTieBreakSeed(f); err != nil {
+		return nil, err
+	}
+
+	if err := s.formRepository.CreateForm(ctx, f); err != nil {
 		return nil, err
 	}
+	metrics.FormsCreated.Inc()
 	return f, nil
 }
 
-func (s *FormServiceImpl) GetForm(id uint) (*model.Form, error) {
-	form, err := s.formRepository.GetForm(id)
+func (s *FormServiceImpl) GetForm(ctx context.Context, id uint) (*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "FormService.GetForm")
+	defer span.End()
+
+	form, err := s.formRepository.GetForm(ctx, id)
 	if err != nil {
-		return nil, ErrFormNotFound
+		return nil, notFound(err, ErrFormNotFound)
+	}
+
+	// There is no scheduler, so the first request to load the form after
+	// its nomination phase ended turns the nominations into options
+	if form.NominationsDue(time.Now()) {
+		if err := s.convertNominations(ctx, form); err != nil {
+			return nil, err
+		}
+		form, err = s.formRepository.GetForm(ctx, id)
+		if err != nil {
+			return nil, notFound(err, ErrFormNotFound)
+		}
 	}
 	return form, nil
 }
 
-func (s *FormServiceImpl) UpdateForm(id uint, userID uint, updateForm *dto.UpdateFormRequest) (*model.Form, error) {
-	if err := s.authorizationService.CanViewFormResults(userID, id); err != nil {
+// convertNominations adds the accepted nominations of the form to their
+// questions as options. The repository makes sure this happens only once.
+func (s *FormServiceImpl) convertNominations(ctx context.Context, form *model.Form) error {
+	nominations, err := s.nominationRepository.GetNominations(ctx, form.ID)
+	if err != nil {
+		return err
+	}
+	accepted := acceptedNominations(form, nominations)
+	return s.nominationRepository.ConvertNominations(ctx, form.ID, time.Now(), accepted)
+}
+
+func (s *FormServiceImpl) UpdateForm(ctx context.Context, id uint, userID uint, updateForm *dto.UpdateFormRequest) (*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "FormService.UpdateForm")
+	defer span.End()
+
+	if err := s.authorizationService.CanEditForm(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	originalForm, err := s.GetForm(ctx, id)
+	if err != nil {
 		return nil, err
 	}
 
-	originalForm, err := s.GetForm(id)
+	// The outcome of a closed form is binding, so neither its questions nor
+	// its voting window can change any more
+	if originalForm.Closed(time.Now()) {
+		return nil, ErrFormClosed
+	}
+
+	// How questions are counted and what decides the outcome are fixed once
+	// the first ballot is cast, so nobody can pick a rule, an option set, a
+	// quorum or a closing time knowing how the votes fall
+	voted, err := s.formRepository.HasSubmissions(ctx, id)
 	if err != nil {
-		return nil, ErrFormNotFound
+		return nil, err
+	}
+
+	originalQuestions := make(map[uint]model.Question, len(originalForm.Questions))
+	for _, question := range originalForm.Questions {
+		originalQuestions[question.ID] = question
 	}
 
-	// Handle deleted questions
-	if len(updateForm.DeletedQuestionIds) > 0 {
-		for _, questionID := range updateForm.DeletedQuestionIds {
-			if err := s.formRepository.DeleteQuestion(questionID); err != nil {
+	// Deleted questions must belong to this form, and are removed only once
+	// the rest of the update is known to be valid
+	deletedQuestions := make(map[uint]bool, len(updateForm.DeletedQuestionIds))
+	for i, questionID := range updateForm.DeletedQuestionIds {
+		if _, ok := originalQuestions[questionID]; !ok {
+			return nil, foreignQuestion(fmt.Sprintf("deletedQuestionIds.%d", i))
+		}
+		if voted {
+			answered, err := s.formRepository.HasAnswers(ctx, questionID)
+			if err != nil {
 				return nil, err
 			}
+			if answered {
+				field := apperr.FieldError{
+					Field:   fmt.Sprintf("deletedQuestionIds.%d", i),
+					Message: "questions that have been voted on cannot be deleted",
+				}
+				return nil, validation.ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+			}
+		}
+		deletedQuestions[questionID] = true
+	}
+	if len(deletedQuestions) > 0 {
+		kept := make([]model.Question, 0, len(originalForm.Questions))
+		for _, question := range originalForm.Questions {
+			if !deletedQuestions[question.ID] {
+				kept = append(kept, question)
+			}
 		}
+		originalForm.Questions = kept
 	}
+	deletedOptions := make(map[uint][]uint)
 
 	// Update form fields
 	if updateForm.Title != nil {
@@ -74,57 +182,102 @@ func (s *FormServiceImpl) UpdateForm(id uint, userID uint, updateForm *dto.Updat
 		originalForm.StartAt = *updateForm.StartAt
 	}
 	if updateForm.EndAt != nil {
+		if voted && !extendsVoting(originalForm.EndAt, *updateForm.EndAt) {
+			field := apperr.FieldError{Field: "endAt", Message: "voting can only be extended once the first ballot is cast"}
+			return nil, apperr.ErrValidation.WithMessage(field.Message).WithFields(field)
+		}
 		originalForm.EndAt = *updateForm.EndAt
 	}
+	quorumType, quorum := originalForm.QuorumType, originalForm.Quorum
+	if updateForm.QuorumType != nil {
+		originalForm.QuorumType = model.QuorumType(*updateForm.QuorumType)
+		if originalForm.QuorumType == "none" {
+			originalForm.QuorumType = ""
+			originalForm.Quorum = 0
+		}
+	}
+	if updateForm.Quorum != nil {
+		originalForm.Quorum = *updateForm.Quorum
+	}
+	if updateForm.NominationStartAt != nil || updateForm.NominationEndAt != nil {
+		if originalForm.NominationsConvertedAt != nil {
+			field := apperr.FieldError{Field: "nomination_end_at", Message: "the nomination phase has already ended"}
+			return nil, validation.ErrInvalidNominationPhase.WithMessage(field.Message).WithFields(field)
+		}
+		if updateForm.NominationStartAt != nil {
+			originalForm.NominationStartAt = updateForm.NominationStartAt
+		}
+		if updateForm.NominationEndAt != nil {
+			originalForm.NominationEndAt = updateForm.NominationEndAt
+		}
+	}
+	if voted && (originalForm.QuorumType != quorumType || originalForm.Quorum != quorum) {
+		field := apperr.FieldError{Field: "quorum", Message: "the quorum cannot change once voting has started"}
+		return nil, validation.ErrInvalidQuorum.WithMessage(field.Message).WithFields(field)
+	}
+	if err := validation.ValidateQuorum(originalForm); err != nil {
+		return nil, err
+	}
 
-	// Update questions
+	// Update questions. Questions and options are matched by ID, and IDs
+	// from other forms or questions are rejected rather than moved here
 	if updateForm.Questions != nil {
 		questions := make([]model.Question, len(updateForm.Questions))
 		for i, q := range updateForm.Questions {
-			question := model.Question{
-				Title: *q.Title,
-				Type:  model.QuestionType(*q.Type),
-			}
+			var original model.Question
 			if q.ID != nil {
-				question.ID = *q.ID
+				var ok bool
+				if original, ok = originalQuestions[*q.ID]; !ok || deletedQuestions[*q.ID] {
+					return nil, foreignQuestion(fmt.Sprintf("questions.%d.id", i))
+				}
+			}
+			question, err := updatedQuestion(i, &q, &original)
+			if err != nil {
+				return nil, err
 			}
 
 			// Update options
 			if q.Options != nil {
+				originalOptions := make(map[uint]bool, len(original.Options))
+				for _, option := range original.Options {
+					originalOptions[option.ID] = true
+				}
+
 				options := make([]*model.Option, len(q.Options))
 				for j, o := range q.Options {
 					option := &model.Option{
 						Title: o.Title,
 					}
 					if o.ID != nil {
+						if !originalOptions[*o.ID] {
+							field := apperr.FieldError{
+								Field:   fmt.Sprintf("questions.%d.options.%d.id", i, j),
+								Message: "option is not part of this question",
+							}
+							return nil, validation.ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+						}
 						option.ID = *o.ID
+						delete(originalOptions, *o.ID)
 					}
 					options[j] = option
 				}
 				question.Options = options
-			}
 
-			if q.ID != nil {
-				originalQuestion := originalForm.Questions[i]
-				originalOptionIDs := make(map[uint]bool)
-				for _, opt := range originalQuestion.Options {
-					originalOptionIDs[opt.ID] = true
+				// Options left out of the request are removed
+				for _, option := range original.Options {
+					if originalOptions[option.ID] {
+						deletedOptions[original.ID] = append(deletedOptions[original.ID], option.ID)
+					}
 				}
+			}
 
-				// Find and delete options that are no longer present
-				for _, opt := range originalQuestion.Options {
-					optionStillExists := false
-					for _, newOpt := range q.Options {
-						if newOpt.ID != nil && *newOpt.ID == opt.ID {
-							optionStillExists = true
-							break
-						}
-					}
-					if !optionStillExists {
-						if err := s.formRepository.DeleteOption(opt.ID); err != nil {
-							return nil, err
-						}
-					}
+			applyTallyDefaults(&question)
+			if err := validation.ValidateQuestion(i, &question); err != nil {
+				return nil, err
+			}
+			if q.ID != nil && voted {
+				if err := checkCountingLocked(i, &original, &question, q.Options != nil); err != nil {
+					return nil, err
 				}
 			}
 
@@ -132,21 +285,224 @@ func (s *FormServiceImpl) UpdateForm(id uint, userID uint, updateForm *dto.Updat
 		}
 		originalForm.Questions = questions
 	}
+	if err := validation.ValidateNominationPhase(originalForm); err != nil {
+		return nil, err
+	}
+	if err := assignTieBreakSeed(originalForm); err != nil {
+		return nil, err
+	}
 
-	if err := s.formRepository.UpdateForm(id, originalForm); err != nil {
+	for questionID := range deletedQuestions {
+		if err := s.formRepository.DeleteQuestion(ctx, id, questionID); err != nil {
+			return nil, err
+		}
+	}
+	for questionID, optionIDs := range deletedOptions {
+		for _, optionID := range optionIDs {
+			if err := s.formRepository.DeleteOption(ctx, questionID, optionID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.formRepository.UpdateForm(ctx, id, originalForm); err != nil {
 		return nil, err
 	}
 	return originalForm, nil
 }
 
-func (s *FormServiceImpl) DeleteForm(id uint, userID uint) error {
-	if err := s.authorizationService.CanViewFormResults(userID, id); err != nil {
+// updatedQuestion applies a question of an update request on top of the
+// question it updates, whose ID is zero for new questions. Fields left out of
+// the request keep their values; new questions must have a title and a type.
+func updatedQuestion(index int, q *dto.UpdateQuestionRequest, original *model.Question) (model.Question, error) {
+	question := model.Question{
+		Title: original.Title,
+		Type:  original.Type,
+	}
+	if q.Title != nil {
+		question.Title = *q.Title
+	}
+	if q.Type != nil {
+		question.Type = model.QuestionType(*q.Type)
+	}
+	if original.ID == 0 {
+		var field apperr.FieldError
+		switch {
+		case q.Title == nil:
+			field = apperr.FieldError{Field: fmt.Sprintf("questions.%d.title", index), Message: "new questions need a title"}
+		case q.Type == nil:
+			field = apperr.FieldError{Field: fmt.Sprintf("questions.%d.type", index), Message: "new questions need a type"}
+		}
+		if field.Field != "" {
+			return model.Question{}, validation.ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+		}
+	} else {
+		question.ID = original.ID
+		// Keep the tally settings unless the request changes them
+		if original.Type == question.Type {
+			question.TallyMethod = original.TallyMethod
+			question.MaxScore = original.MaxScore
+			question.Seats = original.Seats
+			question.PassThreshold = original.PassThreshold
+			question.CountAbstentions = original.CountAbstentions
+			question.TieBreak = original.TieBreak
+			question.AcceptNominations = original.AcceptNominations
+			question.NominationSupport = original.NominationSupport
+			question.ModerateNominations = original.ModerateNominations
+		}
+	}
+
+	if q.TallyMethod != nil {
+		question.TallyMethod = model.TallyMethod(*q.TallyMethod)
+	}
+	if q.MaxScore != nil {
+		question.MaxScore = *q.MaxScore
+	}
+	if q.Seats != nil {
+		question.Seats = *q.Seats
+	}
+	if q.PassThreshold != nil {
+		question.PassThreshold = model.PassThreshold(*q.PassThreshold)
+		if question.PassThreshold == "none" {
+			question.PassThreshold = ""
+		}
+	}
+	if q.CountAbstentions != nil {
+		question.CountAbstentions = *q.CountAbstentions
+	}
+	if q.TieBreak != nil {
+		question.TieBreak = model.TieBreakPolicy(*q.TieBreak)
+		if question.TieBreak == "none" {
+			question.TieBreak = ""
+		}
+	}
+	if q.AcceptNominations != nil {
+		question.AcceptNominations = *q.AcceptNominations
+	}
+	if q.NominationSupport != nil {
+		question.NominationSupport = *q.NominationSupport
+	}
+	if q.ModerateNominations != nil {
+		question.ModerateNominations = *q.ModerateNominations
+	}
+	return question, nil
+}
+
+// extendsVoting reports whether moving a form's end from current to next keeps
+// voting open at least as long. Forms without an end never close.
+func extendsVoting(current, next time.Time) bool {
+	if current.IsZero() {
+		return next.IsZero()
+	}
+	return next.IsZero() || !next.Before(current)
+}
+
+// foreignQuestion rejects a question ID that does not belong to the form.
+func foreignQuestion(field string) error {
+	fieldErr := apperr.FieldError{Field: field, Message: "question is not part of this form"}
+	return validation.ErrInvalidQuestion.WithMessage(fieldErr.Message).WithFields(fieldErr)
+}
+
+// checkCountingLocked rejects changes to how a question is counted once its
+// form has ballots: its tally method, maximum score, seats, pass threshold,
+// abstention counting, tie-break policy and, when the request lists them, the
+// set of its options. Options can still be renamed.
+func checkCountingLocked(index int, original *model.Question, question *model.Question, optionsSent bool) error {
+	var field, message string
+	switch {
+	case question.TallyMethod != original.TallyMethod:
+		field, message = "tally_method", "tally methods cannot change once voting has started"
+	case question.MaxScore != original.MaxScore:
+		field, message = "max_score", "the maximum score cannot change once voting has started"
+	case question.Seats != original.Seats:
+		field, message = "seats", "the number of seats cannot change once voting has started"
+	case question.PassThreshold != original.PassThreshold:
+		field, message = "pass_threshold", "pass thresholds cannot change once voting has started"
+	case question.CountAbstentions != original.CountAbstentions:
+		field, message = "count_abstentions", "abstention counting cannot change once voting has started"
+	case question.TieBreak != original.TieBreak:
+		field, message = "tie_break", "tie-break policies cannot change once voting has started"
+	case optionsSent && !sameOptions(original.Options, question.Options):
+		field, message = "options", "options cannot be added or removed once voting has started"
+	default:
+		return nil
+	}
+	fieldErr := apperr.FieldError{Field: fmt.Sprintf("questions.%d.%s", index, field), Message: message}
+	return validation.ErrInvalidQuestion.WithMessage(message).WithFields(fieldErr)
+}
+
+// sameOptions reports whether both lists hold the same existing options.
+func sameOptions(original []*model.Option, options []*model.Option) bool {
+	if len(original) != len(options) {
+		return false
+	}
+	ids := make(map[uint]bool, len(original))
+	for _, option := range original {
+		ids[option.ID] = true
+	}
+	for _, option := range options {
+		if !ids[option.ID] {
+			return false
+		}
+		delete(ids, option.ID)
+	}
+	return true
+}
+
+// applyTallyDefaults gives choice questions without a tally method the one
+// that matches how they were always counted.
+func applyTallyDefaults(question *model.Question) {
+	if question.TallyMethod == "" {
+		switch question.Type {
+		case model.QuestionTypeSingleChoice:
+			question.TallyMethod = model.TallyMethodPlurality
+		case model.QuestionTypeMultipleChoice:
+			question.TallyMethod = model.TallyMethodApproval
+		}
+	}
+	if question.TallyMethod == model.TallyMethodScore && question.MaxScore == 0 {
+		question.MaxScore = defaultMaxScore
+	}
+	if question.TallyMethod == model.TallyMethodSTV && question.Seats == 0 {
+		question.Seats = 1
+	}
+}
+
+// assignTieBreakSeed commits the form to a random seed the first time one of
+// its questions breaks ties by a draw. Only the seed's hash is published until
+// voting closes, and the seed never changes afterwards.
+func assignTieBreakSeed(form *model.Form) error {
+	if form.TieBreakSeed != "" {
+		return nil
+	}
+	for _, question := range form.Questions {
+		if question.TieBreak != model.TieBreakRandom {
+			continue
+		}
+		seed := make([]byte, 32)
+		if _, err := rand.Read(seed); err != nil {
+			return err
+		}
+		form.TieBreakSeed = hex.EncodeToString(seed)
+		form.TieBreakSeedHash = tally.SeedHash(form.TieBreakSeed)
+		return nil
+	}
+	return nil
+}
+
+func (s *FormServiceImpl) DeleteForm(ctx context.Context, id uint, userID uint) error {
+	ctx, span := tracing.Start(ctx, "FormService.DeleteForm")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, id); err != nil {
 		return err
 	}
 
-	return s.formRepository.DeleteForm(id)
+	return s.formRepository.DeleteForm(ctx, id)
 }
 
-func (s *FormServiceImpl) GetFormsByUserID(userID uint) ([]*model.Form, error) {
-	return s.formRepository.GetFormsByUserID(userID)
+func (s *FormServiceImpl) GetFormsByUserID(ctx context.Context, userID uint) ([]*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "FormService.GetFormsByUserID")
+	defer span.End()
+
+	return s.formRepository.GetFormsByUserID(ctx, userID)
 }
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/validation"
+	"gorm.io/gorm"
+)
+
+func TestUpdatedQuestionKeepsTitleAndType(t *testing.T) {
+	original := &model.Question{
+		Model:       gorm.Model{ID: 3},
+		Title:       "Who should chair the board?",
+		Type:        model.QuestionTypeSingleChoice,
+		TallyMethod: model.TallyMethodPlurality,
+	}
+	threshold := "majority"
+
+	question, err := updatedQuestion(0, &dto.UpdateQuestionRequest{ID: &original.ID, PassThreshold: &threshold}, original)
+	if err != nil {
+		t.Fatalf("updatedQuestion() error = %v", err)
+	}
+	if question.ID != original.ID || question.Title != original.Title || question.Type != original.Type {
+		t.Errorf("question = %d %q %q, want %d %q %q",
+			question.ID, question.Title, question.Type, original.ID, original.Title, original.Type)
+	}
+	if question.TallyMethod != model.TallyMethodPlurality || question.PassThreshold != model.PassThreshold(threshold) {
+		t.Errorf("tally method = %q, pass threshold = %q", question.TallyMethod, question.PassThreshold)
+	}
+}
+
+func TestUpdatedQuestionNewQuestionNeedsTitleAndType(t *testing.T) {
+	title := "Which day suits you?"
+	questionType := string(model.QuestionTypeSingleChoice)
+	tests := []struct {
+		name  string
+		req   dto.UpdateQuestionRequest
+		field string
+	}{
+		{"missing title", dto.UpdateQuestionRequest{Type: &questionType}, "questions.1.title"},
+		{"missing type", dto.UpdateQuestionRequest{Title: &title}, "questions.1.type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := updatedQuestion(1, &tt.req, &model.Question{})
+			if !errors.Is(err, validation.ErrInvalidQuestion) {
+				t.Fatalf("updatedQuestion() error = %v, want ErrInvalidQuestion", err)
+			}
+			appErr, _ := apperr.As(err)
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.field {
+				t.Errorf("fields = %v, want %s", appErr.Fields, tt.field)
+			}
+		})
+	}
+}
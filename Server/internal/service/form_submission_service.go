@@ -1,52 +1,107 @@
 package service
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/db"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/metrics"
+	"github.com/luneto10/voting-system/internal/pubsub"
 	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
 	"github.com/luneto10/voting-system/internal/validation"
 	"gorm.io/gorm"
 )
 
 type FormSubmissionService interface {
-	SubmitForm(formID uint, userID uint, answers []dto.AnswerSubmission) (*model.Submission, error)
-	UserSubmittedForm(formID uint, userID uint) (bool, error)
-	GetFormVoters(formID uint, userID uint) ([]*model.Submission, error)
+	SubmitForm(ctx context.Context, formID uint, userID uint, answers []dto.AnswerSubmission) (*model.Submission, error)
+	SubmitFormOnBehalf(ctx context.Context, formID uint, proxyID uint, delegatorID uint, answers []dto.AnswerSubmission) (*model.Submission, error)
+	UserSubmittedForm(ctx context.Context, formID uint, userID uint) (bool, error)
+	GetFormVoters(ctx context.Context, formID uint, userID uint) ([]*model.Submission, error)
 }
 
 type FormSubmissionServiceImpl struct {
 	formRepository       repository.FormRepository
+	voterRollRepository  repository.VoterRollRepository
 	formService          FormService
 	authorizationService FormAuthorizationService
 	dashboardService     DashboardService
+	broker               pubsub.Broker
 }
 
 func NewFormSubmissionService(
 	formRepository repository.FormRepository,
+	voterRollRepository repository.VoterRollRepository,
 	formService FormService,
 	authorizationService FormAuthorizationService,
 	dashboardService DashboardService,
+	broker pubsub.Broker,
 ) FormSubmissionService {
 	return &FormSubmissionServiceImpl{
 		formRepository:       formRepository,
+		voterRollRepository:  voterRollRepository,
 		formService:          formService,
 		authorizationService: authorizationService,
 		dashboardService:     dashboardService,
+		broker:               broker,
 	}
 }
 
-func (s *FormSubmissionServiceImpl) SubmitForm(formID uint, userID uint, answers []dto.AnswerSubmission) (*model.Submission, error) {
+func (s *FormSubmissionServiceImpl) SubmitForm(ctx context.Context, formID uint, userID uint, answers []dto.AnswerSubmission) (*model.Submission, error) {
+	ctx, span := tracing.Start(ctx, "FormSubmissionService.SubmitForm")
+	defer span.End()
+
+	submission, err := s.submitForm(ctx, formID, userID, nil, answers)
+	recordSubmission(err)
+	return submission, err
+}
+
+// SubmitFormOnBehalf casts the ballot of a voter who delegated their vote to
+// the proxy. The ballot carries the delegator's weight, and the delegator can
+// still replace it by voting themselves.
+func (s *FormSubmissionServiceImpl) SubmitFormOnBehalf(ctx context.Context, formID uint, proxyID uint, delegatorID uint, answers []dto.AnswerSubmission) (*model.Submission, error) {
+	ctx, span := tracing.Start(ctx, "FormSubmissionService.SubmitFormOnBehalf")
+	defer span.End()
+
+	submission, err := s.submitForm(ctx, formID, delegatorID, &proxyID, answers)
+	recordSubmission(err)
+	return submission, err
+}
+
+// submitForm records the user's ballot. castByID is the proxy casting it, or
+// nil when the user votes themselves.
+func (s *FormSubmissionServiceImpl) submitForm(ctx context.Context, formID uint, userID uint, castByID *uint, answers []dto.AnswerSubmission) (*model.Submission, error) {
 
 	// First, verify that the form exists
-	form, err := s.formService.GetForm(formID)
+	form, err := s.formService.GetForm(ctx, formID)
 	if err != nil {
-		return nil, ErrFormNotFound
+		return nil, err
+	}
+
+	if form.Closed(time.Now()) {
+		return nil, ErrFormClosed
+	}
+	if form.HasNominationPhase() && time.Now().Before(*form.NominationEndAt) {
+		return nil, ErrVotingNotOpen
 	}
 
 	// Check authorization to submit form
-	if err := s.authorizationService.CanSubmitForm(userID, formID); err != nil {
+	if castByID != nil {
+		if err := s.authorizationService.CanSubmitOnBehalf(ctx, *castByID, userID, formID); err != nil {
+			return nil, err
+		}
+	} else if err := s.authorizationService.CanSubmitForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	weight, err := s.voterRollRepository.GetVoterWeight(ctx, formID, userID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -58,30 +113,48 @@ func (s *FormSubmissionServiceImpl) SubmitForm(formID uint, userID uint, answers
 
 	// Create the submission
 	submission := &model.Submission{
-		FormID: form.ID,
-		UserID: userID,
+		FormID:   form.ID,
+		UserID:   userID,
+		Weight:   weight,
+		CastByID: castByID,
 	}
 
 	// Convert answers and validate question types
 	modelAnswers := make([]model.Answer, len(answers))
+	answered := make(map[uint]bool, len(answers))
 	for i, answer := range answers {
 		question, exists := questionMap[answer.QuestionID]
 		if !exists {
-			return nil, fmt.Errorf("question with ID %d not found in form", answer.QuestionID)
+			return nil, validation.InvalidAnswer(answer.QuestionID, "question not found in form")
+		}
+		if answered[answer.QuestionID] {
+			return nil, validation.InvalidAnswer(answer.QuestionID, "question is answered more than once")
 		}
+		answered[answer.QuestionID] = true
 
 		if err := validation.ValidateAnswer(question, answer); err != nil {
 			return nil, err
 		}
 
 		modelAnswer := model.Answer{
-			QuestionID: answer.QuestionID,
-			Text:       &answer.Text,
+			QuestionID:  answer.QuestionID,
+			Text:        &answer.Text,
+			Abstain:     answer.Abstain,
+			Preferences: answerPreferences(answer),
+		}
+
+		// Ranked and scored options are also linked like chosen ones, so
+		// consumers that only read the options still see what was voted for
+		optionIDs := slices.Clone(answer.OptionIDs)
+		for _, preference := range modelAnswer.Preferences {
+			if !slices.Contains(optionIDs, preference.OptionID) {
+				optionIDs = append(optionIDs, preference.OptionID)
+			}
 		}
 
-		if len(answer.OptionIDs) > 0 {
-			options := make([]model.Option, len(answer.OptionIDs))
-			for j, optionID := range answer.OptionIDs {
+		if len(optionIDs) > 0 {
+			options := make([]model.Option, len(optionIDs))
+			for j, optionID := range optionIDs {
 				options[j] = model.Option{
 					Model: gorm.Model{ID: optionID},
 				}
@@ -94,31 +167,103 @@ func (s *FormSubmissionServiceImpl) SubmitForm(formID uint, userID uint, answers
 
 	submission.Answers = modelAnswers
 
-	
-	if err := s.formRepository.CreateSubmission(submission); err != nil {
+	// A voter who votes themselves replaces the ballot their delegate cast
+	var replaced *model.Submission
+	if castByID == nil {
+		replaced, err = s.formRepository.GetUserSubmission(ctx, userID, formID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if replaced != nil {
+		err = s.formRepository.ReplaceSubmission(ctx, replaced.ID, submission)
+	} else {
+		err = s.formRepository.CreateSubmission(ctx, submission)
+	}
+	// A concurrent submission may have been stored since the checks above
+	if db.IsUniqueViolation(err) {
+		return nil, ErrSubmissionAlreadyExists
+	}
+	if err != nil {
 		return nil, err
 	}
 
 	// Update form status to completed
-	if err := s.dashboardService.UpdateUserFormStatus(userID, formID, "completed"); err != nil {
+	if err := s.dashboardService.UpdateUserFormStatus(ctx, userID, formID, "completed"); err != nil {
 		return nil, err
 	}
 
+	s.publishSubmission(ctx, submission)
 	return submission, nil
 }
 
-func (s *FormSubmissionServiceImpl) UserSubmittedForm(formID uint, userID uint) (bool, error) {
-	submitted, err := s.formRepository.UserSubmittedForm(userID, formID)
+// publishSubmission announces a stored ballot to live results viewers. The
+// ballot counts whether or not anyone hears about it.
+func (s *FormSubmissionServiceImpl) publishSubmission(ctx context.Context, submission *model.Submission) {
+	payload, err := json.Marshal(submissionEvent{FormID: submission.FormID, SubmissionID: submission.ID})
+	if err == nil {
+		err = s.broker.Publish(ctx, submissionsTopic(submission.FormID), payload)
+	}
+	if err != nil {
+		applog.FromContext(ctx).WarnContext(ctx, "failed to publish submission",
+			"form_id", submission.FormID,
+			"error", err)
+	}
+}
+
+func (s *FormSubmissionServiceImpl) UserSubmittedForm(ctx context.Context, formID uint, userID uint) (bool, error) {
+	ctx, span := tracing.Start(ctx, "FormSubmissionService.UserSubmittedForm")
+	defer span.End()
+
+	submitted, err := s.formRepository.UserSubmittedForm(ctx, userID, formID)
 	if err != nil {
 		return false, err
 	}
 	return submitted, nil
 }
 
-func (s *FormSubmissionServiceImpl) GetFormVoters(formID uint, userID uint) ([]*model.Submission, error) {
-	if err := s.authorizationService.CanViewFormResults(userID, formID); err != nil {
+func (s *FormSubmissionServiceImpl) GetFormVoters(ctx context.Context, formID uint, userID uint) ([]*model.Submission, error) {
+	ctx, span := tracing.Start(ctx, "FormSubmissionService.GetFormVoters")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewForm(ctx, userID, formID); err != nil {
 		return nil, err
 	}
 
-	return s.formRepository.GetFormVoters(formID)
+	return s.formRepository.GetFormVoters(ctx, formID)
+}
+
+// answerPreferences turns the ranking or scores of a ballot into rows that
+// keep the order and the scores.
+func answerPreferences(answer dto.AnswerSubmission) []model.AnswerPreference {
+	var preferences []model.AnswerPreference
+	for i, optionID := range answer.Ranking {
+		preferences = append(preferences, model.AnswerPreference{OptionID: optionID, Rank: i + 1})
+	}
+	for _, score := range answer.Scores {
+		preferences = append(preferences, model.AnswerPreference{OptionID: score.OptionID, Score: score.Score})
+	}
+	return preferences
+}
+
+// recordSubmission counts the outcome of a submission attempt.
+func recordSubmission(err error) {
+	switch {
+	case err == nil:
+		metrics.SubmissionsAccepted.Inc()
+	case errors.Is(err, ErrSubmissionAlreadyExists):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonAlreadySubmitted).Inc()
+	case errors.Is(err, ErrCannotSubmitOwnForm):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonOwnForm).Inc()
+	case errors.Is(err, ErrInvalidAnswer):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonValidationError).Inc()
+	case errors.Is(err, ErrFormNotFound):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonFormNotFound).Inc()
+	case errors.Is(err, ErrFormClosed):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonFormClosed).Inc()
+	case errors.Is(err, ErrNotEligibleVoter):
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonNotEligible).Inc()
+	default:
+		metrics.SubmissionsRejected.WithLabelValues(metrics.ReasonOther).Inc()
+	}
 }
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/metrics"
+	"github.com/luneto10/voting-system/internal/pubsub"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+)
+
+// liveResultsInterval is the shortest time between two updates of a form's
+// live results, so a burst of ballots is counted once.
+const liveResultsInterval = time.Second
+
+// liveAccessInterval is how often a viewer's access to the results is checked
+// again while they watch.
+const liveAccessInterval = 30 * time.Second
+
+// submissionsTopic is the broker topic a form's stored ballots are announced
+// on.
+func submissionsTopic(formID uint) string {
+	return "forms." + strconv.FormatUint(uint64(formID), 10) + ".submissions"
+}
+
+// submissionEvent is published on a form's submissions topic once a ballot
+// has been stored.
+type submissionEvent struct {
+	FormID       uint `json:"form_id"`
+	SubmissionID uint `json:"submission_id"`
+}
+
+type LiveResultsService interface {
+	// Subscribe streams the form's results to the user: the current count
+	// right away and a new one after ballots are stored. The channel is
+	// closed when ctx ends, the stream shuts down or the user may no longer
+	// see the results.
+	Subscribe(ctx context.Context, formID uint, userID uint) (<-chan *dto.LiveResultsResponse, error)
+	// StreamToken issues a short-lived token that opens the form's results
+	// stream for the user without an Authorization header.
+	StreamToken(ctx context.Context, formID uint, userID uint) (*dto.StreamTokenResponse, error)
+}
+
+// LiveResultsServiceImpl keeps one feed per watched form. A feed holds the
+// form's only broker subscription and counts the ballots once per update for
+// all of its viewers.
+type LiveResultsServiceImpl struct {
+	formRepository       repository.FormRepository
+	voterRollRepository  repository.VoterRollRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+	broker               pubsub.Broker
+
+	mu    sync.Mutex
+	feeds map[uint]*liveFeed
+}
+
+type liveFeed struct {
+	viewers  map[*liveViewer]struct{}
+	latest   *dto.LiveResultsResponse
+	sequence uint64
+	cancel   context.CancelFunc
+}
+
+type liveViewer struct {
+	ctx    context.Context
+	userID uint
+	// checkedAt is when the viewer's access was last checked. Only the feed's
+	// run goroutine touches it once the viewer has joined
+	checkedAt time.Time
+	// updates holds at most the newest update the viewer has not read yet
+	updates chan *dto.LiveResultsResponse
+}
+
+func NewLiveResultsService(
+	formRepository repository.FormRepository,
+	voterRollRepository repository.VoterRollRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+	broker pubsub.Broker,
+) LiveResultsService {
+	return &LiveResultsServiceImpl{
+		formRepository:       formRepository,
+		voterRollRepository:  voterRollRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+		broker:               broker,
+		feeds:                make(map[uint]*liveFeed),
+	}
+}
+
+func (s *LiveResultsServiceImpl) Subscribe(ctx context.Context, formID uint, userID uint) (<-chan *dto.LiveResultsResponse, error) {
+	ctx, span := tracing.Start(ctx, "LiveResultsService.Subscribe")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewFormResults(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	viewer := &liveViewer{
+		ctx:       ctx,
+		userID:    userID,
+		checkedAt: time.Now(),
+		updates:   make(chan *dto.LiveResultsResponse, 1),
+	}
+	feed, latest, err := s.join(formID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		s.leave(formID, feed, viewer)
+	}()
+
+	// Viewers joining a watched form start from the feed's last count
+	if latest == nil {
+		snapshot, err := s.snapshot(ctx, formID)
+		if err != nil {
+			s.leave(formID, feed, viewer)
+			return nil, err
+		}
+		s.mu.Lock()
+		if feed.latest == nil {
+			s.setLatest(feed, snapshot)
+		}
+		latest = feed.latest
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	if _, ok := feed.viewers[viewer]; ok {
+		deliver(viewer, latest)
+	}
+	s.mu.Unlock()
+
+	return viewer.updates, nil
+}
+
+func (s *LiveResultsServiceImpl) StreamToken(ctx context.Context, formID uint, userID uint) (*dto.StreamTokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "LiveResultsService.StreamToken")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewFormResults(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(auth.StreamTokenExpiration)
+	return &dto.StreamTokenResponse{
+		Token:     auth.SignStreamToken(formID, userID, expiresAt),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// join adds the viewer to the form's feed, starting the feed if the form is
+// not watched yet, and returns the feed's last count.
+func (s *LiveResultsServiceImpl) join(formID uint, viewer *liveViewer) (*liveFeed, *dto.LiveResultsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feed := s.feeds[formID]
+	if feed == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := s.broker.Subscribe(ctx, submissionsTopic(formID))
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		feed = &liveFeed{
+			viewers: make(map[*liveViewer]struct{}),
+			cancel:  cancel,
+		}
+		s.feeds[formID] = feed
+		go s.run(ctx, formID, feed, events)
+	}
+
+	feed.viewers[viewer] = struct{}{}
+	metrics.LiveResultsViewers.Inc()
+	return feed, feed.latest, nil
+}
+
+// leave removes the viewer from the feed and stops the feed once nobody
+// watches the form any more.
+func (s *LiveResultsServiceImpl) leave(formID uint, feed *liveFeed, viewer *liveViewer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := feed.viewers[viewer]; !ok {
+		return
+	}
+	delete(feed.viewers, viewer)
+	close(viewer.updates)
+	metrics.LiveResultsViewers.Dec()
+
+	if len(feed.viewers) == 0 {
+		feed.cancel()
+		if s.feeds[formID] == feed {
+			delete(s.feeds, formID)
+		}
+	}
+}
+
+// run recounts the form whenever ballots are announced until the feed stops
+// or the broker closes, and then disconnects the remaining viewers.
+func (s *LiveResultsServiceImpl) run(ctx context.Context, formID uint, feed *liveFeed, events <-chan []byte) {
+	defer s.stop(formID, feed)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		}
+
+		snapshot, err := s.snapshot(ctx, formID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			applog.FromContext(ctx).WarnContext(ctx, "failed to count live results",
+				"form_id", formID,
+				"error", err)
+		} else {
+			s.broadcast(formID, feed, snapshot)
+		}
+
+		// Ballots announced meanwhile wait in the subscription and are
+		// counted together
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(liveResultsInterval):
+		}
+	}
+}
+
+func (s *LiveResultsServiceImpl) stop(formID uint, feed *liveFeed) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for viewer := range feed.viewers {
+		close(viewer.updates)
+		metrics.LiveResultsViewers.Dec()
+	}
+	clear(feed.viewers)
+	feed.cancel()
+	if s.feeds[formID] == feed {
+		delete(s.feeds, formID)
+	}
+}
+
+// broadcast sends a new count to every viewer that may still see the
+// results. Access is checked when viewers subscribe and again at most every
+// liveAccessInterval, so a busy form does not query it for every ballot.
+// Viewers who lost access are disconnected.
+func (s *LiveResultsServiceImpl) broadcast(formID uint, feed *liveFeed, snapshot *dto.LiveResultsResponse) {
+	s.mu.Lock()
+	s.setLatest(feed, snapshot)
+	viewers := make([]*liveViewer, 0, len(feed.viewers))
+	for viewer := range feed.viewers {
+		viewers = append(viewers, viewer)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	allowed := make([]*liveViewer, 0, len(viewers))
+	for _, viewer := range viewers {
+		if now.Sub(viewer.checkedAt) >= liveAccessInterval {
+			if err := s.authorizationService.CanViewFormResults(viewer.ctx, viewer.userID, formID); err != nil {
+				s.leave(formID, feed, viewer)
+				continue
+			}
+			viewer.checkedAt = now
+		}
+		allowed = append(allowed, viewer)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, viewer := range allowed {
+		if _, ok := feed.viewers[viewer]; ok {
+			deliver(viewer, snapshot)
+		}
+	}
+}
+
+// setLatest numbers the count and makes it the feed's newest. The caller
+// holds s.mu.
+func (s *LiveResultsServiceImpl) setLatest(feed *liveFeed, snapshot *dto.LiveResultsResponse) {
+	feed.sequence++
+	snapshot.Sequence = feed.sequence
+	feed.latest = snapshot
+}
+
+// deliver replaces any update the viewer has not read yet with the newer one.
+// The caller holds s.mu, so nothing else writes to the channel meanwhile.
+func deliver(viewer *liveViewer, update *dto.LiveResultsResponse) {
+	select {
+	case <-viewer.updates:
+	default:
+	}
+	viewer.updates <- update
+}
+
+// snapshot counts the form's ballots. It is shared by every viewer of the
+// form and must not be modified once sent.
+func (s *LiveResultsServiceImpl) snapshot(ctx context.Context, formID uint) (*dto.LiveResultsResponse, error) {
+	ctx, span := tracing.Start(ctx, "LiveResultsService.snapshot")
+	defer span.End()
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.formRepository.GetFormAnswers(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := tallyForm(form, answers)
+	if err != nil {
+		return nil, err
+	}
+	rollSize, err := s.voterRollRepository.CountVoterRoll(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &dto.LiveResultsResponse{
+		FormID:         form.ID,
+		Submissions:    results.Submissions,
+		EligibleVoters: int(rollSize),
+		Closed:         form.Closed(now),
+		Questions:      results.Questions,
+		UpdatedAt:      now,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type LoginProtectionService interface {
+	CheckAllowed(ctx context.Context, email, clientIP string) error
+	RecordFailure(ctx context.Context, email, clientIP string, knownAccount bool) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type LoginProtectionServiceImpl struct {
+	cfg                     config.LoginProtectionConfig
+	loginThrottleRepository repository.LoginThrottleRepository
+}
+
+func NewLoginProtectionService(
+	cfg config.LoginProtectionConfig,
+	loginThrottleRepo repository.LoginThrottleRepository,
+) LoginProtectionService {
+	return &LoginProtectionServiceImpl{
+		cfg:                     cfg,
+		loginThrottleRepository: loginThrottleRepo,
+	}
+}
+
+// CheckAllowed returns ErrLoginThrottled, carrying the remaining wait, when
+// either the account or the client IP must wait before trying again.
+func (s *LoginProtectionServiceImpl) CheckAllowed(ctx context.Context, email, clientIP string) error {
+	ctx, span := tracing.Start(ctx, "LoginProtectionService.CheckAllowed")
+	defer span.End()
+
+	now := time.Now()
+
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(clientIP)} {
+		throttle, err := s.getActiveThrottle(ctx, key, now)
+		if err != nil {
+			return err
+		}
+		if throttle == nil {
+			continue
+		}
+		if d := s.retryAfter(throttle, now); d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		return ErrLoginThrottled.WithRetryAfter(wait)
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the client IP and, when the
+// email belongs to an existing account, against the account. Unknown
+// addresses get no throttle of their own, so guessing them cannot fill the
+// table; the per-IP limit covers them.
+func (s *LoginProtectionServiceImpl) RecordFailure(ctx context.Context, email, clientIP string, knownAccount bool) error {
+	ctx, span := tracing.Start(ctx, "LoginProtectionService.RecordFailure")
+	defer span.End()
+
+	now := time.Now()
+	if knownAccount {
+		if err := s.loginThrottleRepository.RecordLoginFailure(ctx, accountKey(email), now,
+			s.cfg.FailureWindow, s.cfg.MaxAccountFailures, s.cfg.LockoutDuration); err != nil {
+			return err
+		}
+	}
+	return s.loginThrottleRepository.RecordLoginFailure(ctx, ipKey(clientIP), now,
+		s.cfg.FailureWindow, s.cfg.MaxIPFailures, s.cfg.LockoutDuration)
+}
+
+// RecordSuccess clears the account's failure history. The IP history is kept
+// so a single valid account cannot be used to reset an attacker's counter.
+func (s *LoginProtectionServiceImpl) RecordSuccess(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "LoginProtectionService.RecordSuccess")
+	defer span.End()
+
+	return s.loginThrottleRepository.DeleteLoginThrottle(ctx, accountKey(email))
+}
+
+func (s *LoginProtectionServiceImpl) Unlock(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "LoginProtectionService.Unlock")
+	defer span.End()
+
+	return s.loginThrottleRepository.DeleteLoginThrottle(ctx, accountKey(email))
+}
+
+// getActiveThrottle returns the throttle for key, or nil if there is none or
+// it has gone stale (outside the failure window and no longer locked).
+func (s *LoginProtectionServiceImpl) getActiveThrottle(ctx context.Context, key string, now time.Time) (*model.LoginThrottle, error) {
+	throttle, err := s.loginThrottleRepository.GetLoginThrottle(ctx, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+		return throttle, nil
+	}
+	if throttle.LockedUntil == nil && now.Sub(throttle.LastFailureAt) < s.cfg.FailureWindow {
+		return throttle, nil
+	}
+	return nil, nil
+}
+
+func (s *LoginProtectionServiceImpl) retryAfter(throttle *model.LoginThrottle, now time.Time) time.Duration {
+	if throttle.LockedUntil != nil {
+		return throttle.LockedUntil.Sub(now)
+	}
+
+	// Exponential backoff: BaseDelay, 2*BaseDelay, 4*BaseDelay... up to MaxDelay
+	delay := s.cfg.BaseDelay
+	for i := 1; i < throttle.Failures && delay < s.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.MaxDelay {
+		delay = s.cfg.MaxDelay
+	}
+
+	return throttle.LastFailureAt.Add(delay).Sub(now)
+}
+
+func accountKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(clientIP string) string {
+	return "ip:" + clientIP
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+)
+
+type NominationService interface {
+	GetNominations(ctx context.Context, formID uint, userID uint) (*dto.FormNominationsResponse, error)
+	Nominate(ctx context.Context, formID uint, userID uint, req *dto.NominateRequest) (*dto.NominationResponse, error)
+	SupportNomination(ctx context.Context, formID uint, nominationID uint, userID uint) (*dto.NominationResponse, error)
+	ModerateNomination(ctx context.Context, formID uint, nominationID uint, userID uint, status model.NominationStatus) (*dto.NominationResponse, error)
+}
+
+type NominationServiceImpl struct {
+	nominationRepository repository.NominationRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+}
+
+func NewNominationService(
+	nominationRepository repository.NominationRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+) NominationService {
+	return &NominationServiceImpl{
+		nominationRepository: nominationRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+	}
+}
+
+// GetNominations lists the nominations of a form. The owner sees every
+// nomination; voters do not see the ones the owner rejected.
+func (s *NominationServiceImpl) GetNominations(ctx context.Context, formID uint, userID uint) (*dto.FormNominationsResponse, error) {
+	ctx, span := tracing.Start(ctx, "NominationService.GetNominations")
+	defer span.End()
+
+	// Loading the form converts the nominations if the phase has ended
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.authorizationService.FormRole(ctx, userID, formID)
+	if err != nil {
+		return nil, err
+	}
+	moderator := role.Includes(model.CollaboratorEditor)
+	if role == "" {
+		if err := s.authorizationService.CanNominate(ctx, userID, formID); err != nil {
+			return nil, err
+		}
+	}
+
+	nominations, err := s.nominationRepository.GetNominations(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.FormNominationsResponse{
+		FormID:            form.ID,
+		NominationStartAt: form.NominationStartAt,
+		NominationEndAt:   form.NominationEndAt,
+		Open:              form.NominationsOpen(time.Now()),
+		Converted:         form.NominationsConvertedAt != nil,
+		Nominations:       []dto.NominationResponse{},
+	}
+	for _, nomination := range nominations {
+		if !moderator && nomination.Status == model.NominationRejected {
+			continue
+		}
+		resp.Nominations = append(resp.Nominations, nominationResponse(nomination, userID))
+	}
+	return resp, nil
+}
+
+// Nominate proposes an option for a question of the form while its
+// nomination phase is open. Titles must differ from the question's options
+// and other nominations, so voters support an existing nomination instead of
+// repeating it.
+func (s *NominationServiceImpl) Nominate(ctx context.Context, formID uint, userID uint, req *dto.NominateRequest) (*dto.NominationResponse, error) {
+	ctx, span := tracing.Start(ctx, "NominationService.Nominate")
+	defer span.End()
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.NominationsOpen(time.Now()) {
+		return nil, ErrNominationsClosed
+	}
+	if err := s.authorizationService.CanNominate(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	var question *model.Question
+	for i := range form.Questions {
+		if form.Questions[i].ID == req.QuestionID {
+			question = &form.Questions[i]
+		}
+	}
+	if question == nil || !question.AcceptNominations {
+		field := apperr.FieldError{Field: "question_id", Message: "the question does not accept nominations"}
+		return nil, ErrInvalidNomination.WithMessage(field.Message).WithFields(field)
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		field := apperr.FieldError{Field: "title", Message: "title is required"}
+		return nil, ErrInvalidNomination.WithMessage(field.Message).WithFields(field)
+	}
+	nominations, err := s.nominationRepository.GetNominations(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(optionTitles(question, nominations), strings.ToLower(title)) {
+		field := apperr.FieldError{Field: "title", Message: "this option has already been nominated, support the nomination instead"}
+		return nil, ErrInvalidNomination.WithMessage(field.Message).WithFields(field)
+	}
+
+	nomination := &model.Nomination{
+		FormID:        formID,
+		QuestionID:    question.ID,
+		Title:         title,
+		NominatedByID: userID,
+		Status:        model.NominationPending,
+	}
+	if err := s.nominationRepository.CreateNomination(ctx, nomination); err != nil {
+		return nil, err
+	}
+	return s.nominationResponse(ctx, formID, nomination.ID, userID)
+}
+
+// SupportNomination seconds another voter's nomination while the nomination
+// phase is open.
+func (s *NominationServiceImpl) SupportNomination(ctx context.Context, formID uint, nominationID uint, userID uint) (*dto.NominationResponse, error) {
+	ctx, span := tracing.Start(ctx, "NominationService.SupportNomination")
+	defer span.End()
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.NominationsOpen(time.Now()) {
+		return nil, ErrNominationsClosed
+	}
+	if err := s.authorizationService.CanNominate(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	nomination, err := s.nominationRepository.GetNomination(ctx, formID, nominationID)
+	if err != nil {
+		return nil, notFound(err, ErrNominationNotFound)
+	}
+	if nomination.NominatedByID == userID {
+		return nil, ErrInvalidNomination.WithMessage("you cannot support your own nomination")
+	}
+	if nomination.Status == model.NominationRejected {
+		return nil, ErrInvalidNomination.WithMessage("this nomination was rejected")
+	}
+
+	if err := s.nominationRepository.AddSupport(ctx, &model.NominationSupport{
+		NominationID: nomination.ID,
+		UserID:       userID,
+	}); err != nil {
+		return nil, err
+	}
+	return s.nominationResponse(ctx, formID, nomination.ID, userID)
+}
+
+// ModerateNomination lets the form's owner and editors approve or reject a
+// nomination until the nomination phase ends.
+func (s *NominationServiceImpl) ModerateNomination(ctx context.Context, formID uint, nominationID uint, userID uint, status model.NominationStatus) (*dto.NominationResponse, error) {
+	ctx, span := tracing.Start(ctx, "NominationService.ModerateNomination")
+	defer span.End()
+
+	if err := s.authorizationService.CanEditForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.HasNominationPhase() || !time.Now().Before(*form.NominationEndAt) {
+		return nil, ErrNominationsClosed
+	}
+
+	nomination, err := s.nominationRepository.GetNomination(ctx, formID, nominationID)
+	if err != nil {
+		return nil, notFound(err, ErrNominationNotFound)
+	}
+	if err := s.nominationRepository.UpdateNominationStatus(ctx, nomination.ID, status); err != nil {
+		return nil, err
+	}
+	return s.nominationResponse(ctx, formID, nomination.ID, userID)
+}
+
+func (s *NominationServiceImpl) nominationResponse(ctx context.Context, formID uint, nominationID uint, userID uint) (*dto.NominationResponse, error) {
+	nomination, err := s.nominationRepository.GetNomination(ctx, formID, nominationID)
+	if err != nil {
+		return nil, notFound(err, ErrNominationNotFound)
+	}
+	resp := nominationResponse(nomination, userID)
+	return &resp, nil
+}
+
+func nominationResponse(nomination *model.Nomination, userID uint) dto.NominationResponse {
+	resp := dto.NominationResponse{
+		ID:          nomination.ID,
+		QuestionID:  nomination.QuestionID,
+		Title:       nomination.Title,
+		NominatedBy: nomination.NominatedBy.Email,
+		Status:      string(nomination.Status),
+		Support:     len(nomination.Supporters),
+		OptionID:    nomination.OptionID,
+		CreatedAt:   nomination.CreatedAt,
+	}
+	for _, supporter := range nomination.Supporters {
+		if supporter.UserID == userID {
+			resp.Supported = true
+		}
+	}
+	return resp
+}
+
+// acceptedNominations picks the nominations that become options: those with
+// enough support and, on moderated questions, the owner's approval. A title
+// the question already has as an option is only added once.
+func acceptedNominations(form *model.Form, nominations []*model.Nomination) []*model.Nomination {
+	questions := make(map[uint]*model.Question, len(form.Questions))
+	titles := make(map[uint][]string, len(form.Questions))
+	for i := range form.Questions {
+		question := &form.Questions[i]
+		questions[question.ID] = question
+		titles[question.ID] = optionTitles(question, nil)
+	}
+
+	var accepted []*model.Nomination
+	for _, nomination := range nominations {
+		question, ok := questions[nomination.QuestionID]
+		switch {
+		case !ok || !question.AcceptNominations:
+			continue
+		case nomination.Status == model.NominationRejected:
+			continue
+		case question.ModerateNominations && nomination.Status != model.NominationApproved:
+			continue
+		case len(nomination.Supporters) < question.NominationSupport:
+			continue
+		}
+
+		title := strings.ToLower(nomination.Title)
+		if slices.Contains(titles[question.ID], title) {
+			continue
+		}
+		titles[question.ID] = append(titles[question.ID], title)
+		accepted = append(accepted, nomination)
+	}
+	return accepted
+}
+
+// optionTitles lists the lowercased titles of the question's options and of
+// the nominations made for it that were not rejected.
+func optionTitles(question *model.Question, nominations []*model.Nomination) []string {
+	titles := make([]string, 0, len(question.Options)+len(nominations))
+	for _, option := range question.Options {
+		titles = append(titles, strings.ToLower(option.Title))
+	}
+	for _, nomination := range nominations {
+		if nomination.QuestionID == question.ID && nomination.Status != model.NominationRejected {
+			titles = append(titles, strings.ToLower(nomination.Title))
+		}
+	}
+	return titles
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+type OIDCService interface {
+	Enabled() bool
+	AuthorizationURL(ctx context.Context) (string, string, error)
+	HandleCallback(ctx context.Context, loginState, state, code string) (*model.User, string, string, error)
+}
+
+// oidcLoginState is what we need to remember between the redirect to the
+// provider and the callback: the state, the PKCE verifier and the expected
+// nonce. It is kept in a signed cookie rather than on the server, so the
+// callback only succeeds in the browser that started the login, on whichever
+// instance it lands.
+type oidcLoginState struct {
+	State     string    `json:"state"`
+	Verifier  string    `json:"verifier"`
+	Nonce     string    `json:"nonce"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type OIDCServiceImpl struct {
+	cfg                    config.OIDCConfig
+	userRepository         repository.UserRepository
+	userIdentityRepository repository.UserIdentityRepository
+	authService            AuthService
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(
+	cfg config.OIDCConfig,
+	userRepo repository.UserRepository,
+	userIdentityRepo repository.UserIdentityRepository,
+	authService AuthService,
+) OIDCService {
+	return &OIDCServiceImpl{
+		cfg:                    cfg,
+		userRepository:         userRepo,
+		userIdentityRepository: userIdentityRepo,
+		authService:            authService,
+	}
+}
+
+func (s *OIDCServiceImpl) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// AuthorizationURL starts an authorization code flow with PKCE. It returns the
+// provider URL the user should be redirected to and the login state the
+// browser has to present on the callback.
+func (s *OIDCServiceImpl) AuthorizationURL(ctx context.Context) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "OIDCService.AuthorizationURL")
+	defer span.End()
+
+	oauthCfg, _, err := s.oauthConfig(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	payload, err := json.Marshal(oidcLoginState{
+		State:     state,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(auth.OIDCStateExpiration),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := oauthCfg.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return authURL, auth.SignOIDCState(payload), nil
+}
+
+// HandleCallback checks the state returned by the provider against the
+// browser's login state, exchanges the authorization code, verifies the ID
+// token and returns the linked (or newly provisioned) user with a fresh token
+// pair.
+func (s *OIDCServiceImpl) HandleCallback(ctx context.Context, loginState, state, code string) (*model.User, string, string, error) {
+	ctx, span := tracing.Start(ctx, "OIDCService.HandleCallback")
+	defer span.End()
+
+	oauthCfg, verifier, err := s.oauthConfig(ctx)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	login, err := parseLoginState(loginState, state)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	token, err := oauthCfg.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, "", "", errors.New("token response did not include an id_token")
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if idToken.Nonce != login.Nonce {
+		return nil, "", "", ErrInvalidOIDCState
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, "", "", err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, "", "", ErrEmailNotVerified
+	}
+
+	user, err := s.findOrProvisionUser(ctx, idToken.Issuer, idToken.Subject, claims.Email)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if user.Disabled {
+		return nil, "", "", ErrAccountDisabled
+	}
+
+	jwtToken, refreshToken, err := s.authService.IssueTokens(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, jwtToken, refreshToken, nil
+}
+
+// findOrProvisionUser resolves the external identity to a local user. Unknown
+// identities are linked to an existing account with the same verified email,
+// or a new account is created on first login.
+func (s *OIDCServiceImpl) findOrProvisionUser(ctx context.Context, provider, subject, email string) (*model.User, error) {
+	identity, err := s.userIdentityRepository.GetIdentity(ctx, provider, subject)
+	if err == nil {
+		return &identity.User, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		// SSO users have no local password, so password login stays impossible
+		user = &model.User{
+			Email: email,
+			Role:  model.UserRoleUser,
+		}
+		if err := s.userRepository.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.userIdentityRepository.CreateIdentity(ctx, &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// oauthConfig lazily discovers the provider so the server can boot while the
+// identity provider is unreachable.
+func (s *OIDCServiceImpl) oauthConfig(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	if !s.cfg.Enabled {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.cfg.IssuerURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.provider = provider
+	}
+
+	oauthCfg := &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       s.cfg.Scopes,
+	}
+	verifier := s.provider.Verifier(&oidc.Config{ClientID: s.cfg.ClientID})
+
+	return oauthCfg, verifier, nil
+}
+
+// parseLoginState checks the signature and expiry of the browser's login
+// state and that it belongs to the state the provider sent back.
+func parseLoginState(loginState, state string) (*oidcLoginState, error) {
+	payload, err := auth.ParseOIDCState(loginState)
+	if err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	var login oidcLoginState
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	if time.Now().After(login.ExpiresAt) || subtle.ConstantTimeCompare([]byte(login.State), []byte(state)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+	return &login, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,521 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tally"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+const (
+	motionPassed  = "passed"
+	motionFailed  = "failed"
+	motionPending = "pending"
+)
+
+type OutcomeService interface {
+	GetFormOutcome(ctx context.Context, formID uint, userID uint) (*dto.FormOutcomeResponse, error)
+	FreezeOutcomes(ctx context.Context) error
+	DecideTie(ctx context.Context, formID uint, questionID uint, userID uint, optionID uint) (*dto.FormOutcomeResponse, error)
+}
+
+type OutcomeServiceImpl struct {
+	formRepository       repository.FormRepository
+	outcomeRepository    repository.OutcomeRepository
+	voterRollRepository  repository.VoterRollRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+}
+
+func NewOutcomeService(
+	formRepository repository.FormRepository,
+	outcomeRepository repository.OutcomeRepository,
+	voterRollRepository repository.VoterRollRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+) OutcomeService {
+	return &OutcomeServiceImpl{
+		formRepository:       formRepository,
+		outcomeRepository:    outcomeRepository,
+		voterRollRepository:  voterRollRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+	}
+}
+
+// GetFormOutcome reports whether the form reached its quorum and which of its
+// questions passed. While voting is open the outcome is recomputed on every
+// request; once it closes the stored outcome is returned unchanged.
+func (s *OutcomeServiceImpl) GetFormOutcome(ctx context.Context, formID uint, userID uint) (*dto.FormOutcomeResponse, error) {
+	ctx, span := tracing.Start(ctx, "OutcomeService.GetFormOutcome")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewFormResults(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !form.Closed(time.Now()) {
+		quorum, err := s.quorum(ctx, form)
+		if err != nil {
+			return nil, err
+		}
+		return s.decide(ctx, form, quorum, false)
+	}
+
+	stored, err := s.freeze(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	return decodeOutcome(stored)
+}
+
+// FreezeOutcomes stores the outcome of every form whose voting has closed
+// since the last run. It is meant to run periodically, so outcomes are
+// decided when voting closes rather than when they are first requested. A
+// form that fails does not hold up the others.
+func (s *OutcomeServiceImpl) FreezeOutcomes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "OutcomeService.FreezeOutcomes")
+	defer span.End()
+
+	formIDs, err := s.outcomeRepository.GetFormsAwaitingOutcome(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, formID := range formIDs {
+		form, err := s.formService.GetForm(ctx, formID)
+		if err == nil {
+			_, err = s.freeze(ctx, form)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("form %d: %w", formID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// freeze returns the stored outcome of a closed form, storing it first if
+// this is the first time it is needed. The quorum is counted once, here, so
+// the outcome never depends on voter rolls or accounts changing later.
+func (s *OutcomeServiceImpl) freeze(ctx context.Context, form *model.Form) (*model.FormOutcome, error) {
+	stored, err := s.outcomeRepository.GetOutcome(ctx, form.ID)
+	if err == nil {
+		return stored, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	quorum, err := s.quorum(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := s.decide(ctx, form, quorum, true)
+	if err != nil {
+		return nil, err
+	}
+	frozen, err := storedOutcome(form, outcome)
+	if err != nil {
+		return nil, err
+	}
+	return s.outcomeRepository.CreateOutcome(ctx, frozen)
+}
+
+// DecideTie records the option the form's owner chose to break a tie on a
+// question with the owner tie-break policy. Ties can only be broken once
+// voting has closed, and only until the outcome is final.
+func (s *OutcomeServiceImpl) DecideTie(ctx context.Context, formID uint, questionID uint, userID uint, optionID uint) (*dto.FormOutcomeResponse, error) {
+	ctx, span := tracing.Start(ctx, "OutcomeService.DecideTie")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.Closed(time.Now()) {
+		return nil, ErrTieBreakNotAllowed.WithMessage("ties can only be broken once voting has closed")
+	}
+	stored, err := s.freeze(ctx, form)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Final {
+		return nil, ErrTieBreakNotAllowed.WithMessage("the outcome of this form is already final")
+	}
+	outcome, err := decodeOutcome(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var motion *dto.MotionOutcomeResponse
+	for i := range outcome.Motions {
+		if outcome.Motions[i].QuestionID == questionID {
+			motion = &outcome.Motions[i]
+		}
+	}
+	if motion == nil {
+		return nil, apperr.ErrNotFound.WithMessage(fmt.Sprintf("question %d is not part of this form", questionID))
+	}
+	if motion.Status != motionPending || motion.TieBreak.Policy != string(model.TieBreakOwner) {
+		return nil, ErrTieBreakNotAllowed.WithMessage("this question has no tie waiting for the owner")
+	}
+	if !slices.Contains(motion.TieBreak.Tied, optionID) {
+		field := apperr.FieldError{Field: "option_id", Message: "the option is not one of the tied options"}
+		return nil, apperr.ErrValidation.WithMessage(field.Message).WithFields(field)
+	}
+
+	if err := s.outcomeRepository.CreateTieBreakDecision(ctx, &model.TieBreakDecision{
+		FormID:      formID,
+		QuestionID:  questionID,
+		OptionID:    optionID,
+		DecidedByID: userID,
+	}); err != nil {
+		return nil, err
+	}
+
+	// The decision is applied to the quorum counted when voting closed
+	outcome, err = s.decide(ctx, form, &outcome.Quorum, true)
+	if err != nil {
+		return nil, err
+	}
+	decided, err := storedOutcome(form, outcome)
+	if err != nil {
+		return nil, err
+	}
+	decided.ID = stored.ID
+	if stored, err = s.outcomeRepository.FinalizeOutcome(ctx, decided); err != nil {
+		return nil, err
+	}
+	return decodeOutcome(stored)
+}
+
+// decide computes the outcome of the form from the answers submitted so far,
+// measured against the given quorum. Random tie-breaks are only drawn once the
+// form is closed.
+func (s *OutcomeServiceImpl) decide(ctx context.Context, form *model.Form, quorum *dto.QuorumResponse, closed bool) (*dto.FormOutcomeResponse, error) {
+	answers, err := s.formRepository.GetFormAnswers(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := tallyForm(form, answers)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.outcomeRepository.GetTieBreakDecisions(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+	decisions := make(map[uint]*model.TieBreakDecision, len(stored))
+	for _, decision := range stored {
+		decisions[decision.QuestionID] = decision
+	}
+
+	questions := make(map[uint]*model.Question, len(form.Questions))
+	for i := range form.Questions {
+		questions[form.Questions[i].ID] = &form.Questions[i]
+	}
+
+	outcome := &dto.FormOutcomeResponse{
+		FormID:           form.ID,
+		Title:            form.Title,
+		Quorum:           *quorum,
+		Motions:          make([]dto.MotionOutcomeResponse, len(results.Questions)),
+		TieBreakSeedHash: form.TieBreakSeedHash,
+	}
+	if closed {
+		outcome.ClosedAt = &form.EndAt
+		outcome.TieBreakSeed = form.TieBreakSeed
+	}
+	// Only loaded once a question breaks a tie by the earliest votes
+	var firstCast map[uint]time.Time
+	for i, result := range results.Questions {
+		question := questions[result.QuestionID]
+
+		var tieBreak *dto.TieBreakResponse
+		if quorum.Reached && tiedResult(&result) && question.TieBreak != "" {
+			if question.TieBreak == model.TieBreakEarliest && firstCast == nil {
+				if firstCast, err = s.formRepository.GetFirstCastTimes(ctx, form.ID); err != nil {
+					return nil, err
+				}
+			}
+			tieBreak = breakTie(form, question, &result, answers, firstCast, decisions[question.ID], closed)
+		}
+		outcome.Motions[i] = decideMotion(question, &result, quorum.Reached, tieBreak)
+	}
+	return outcome, nil
+}
+
+// tiedResult reports whether several options tie for first place. STV elects
+// several options, so its winners never tie.
+func tiedResult(result *dto.QuestionResultResponse) bool {
+	return len(result.Winners) > 1 && tally.Method(result.TallyMethod) != tally.STV
+}
+
+// breakTie applies the question's tie-break policy to the options tied for
+// first place. Ties left to the owner stay unbroken until they decide, and
+// random draws wait until voting closes so the seed can be revealed.
+// firstCast holds when each voter first voted, for the earliest votes policy.
+func breakTie(form *model.Form, question *model.Question, result *dto.QuestionResultResponse, answers []*model.Answer, firstCast map[uint]time.Time, decision *model.TieBreakDecision, closed bool) *dto.TieBreakResponse {
+	names := optionNames(result)
+	tieBreak := &dto.TieBreakResponse{
+		Policy: string(question.TieBreak),
+		Tied:   result.Winners,
+	}
+
+	switch question.TieBreak {
+	case model.TieBreakOwner:
+		if decision == nil {
+			tieBreak.Explanation = fmt.Sprintf("%s tie; the form owner has to choose between them.",
+				joinOptionNames(names, result.Winners))
+			return tieBreak
+		}
+		tieBreak.Winner = decision.OptionID
+		tieBreak.DecidedBy = decision.DecidedByID
+		tieBreak.DecidedAt = &decision.CreatedAt
+		tieBreak.Explanation = fmt.Sprintf("The form owner broke the tie in favour of %s.", names[decision.OptionID])
+
+	case model.TieBreakEarliest:
+		// A voter's ballot counts from when they first voted, so replacing
+		// it does not move it behind ballots cast in the meantime
+		var counted []*model.Answer
+		for _, answer := range firstAnswers(answers) {
+			if answer.QuestionID == question.ID && !answer.Abstain {
+				counted = append(counted, answer)
+			}
+		}
+		slices.SortStableFunc(counted, func(a, b *model.Answer) int {
+			return firstCast[a.Submission.UserID].Compare(firstCast[b.Submission.UserID])
+		})
+		ballots := make([]tally.Ballot, len(counted))
+		for i, answer := range counted {
+			ballots[i] = toBallot(answer)
+		}
+		winner, ok := tally.ByArrival(questionContest(question), ballots, result.Winners)
+		if !ok {
+			tieBreak.Explanation = "The tied options reached their totals with the same ballot, so the tie could not be broken."
+			return tieBreak
+		}
+		tieBreak.Winner = winner
+		tieBreak.Explanation = fmt.Sprintf("%s reached its total first, breaking the tie.", names[winner])
+
+	case model.TieBreakRandom:
+		if !closed {
+			tieBreak.Explanation = "The tie will be broken by a draw once voting closes."
+			return tieBreak
+		}
+		tieBreak.Seed = form.TieBreakSeed
+		for _, id := range result.Winners {
+			tieBreak.Draws = append(tieBreak.Draws, dto.TieBreakDrawResponse{
+				OptionID: id,
+				Digest:   tally.DrawDigest(form.TieBreakSeed, question.ID, id),
+			})
+		}
+		tieBreak.Winner = tally.Draw(form.TieBreakSeed, question.ID, result.Winners)
+		tieBreak.Explanation = fmt.Sprintf("%s won the draw with the committed seed, breaking the tie.", names[tieBreak.Winner])
+	}
+	return tieBreak
+}
+
+// quorum counts the participants against the form's quorum. Percentage
+// quorums are measured against the voter roll, so they cannot be reached by a
+// form without one.
+func (s *OutcomeServiceImpl) quorum(ctx context.Context, form *model.Form) (*dto.QuorumResponse, error) {
+	rollSize, err := s.voterRollRepository.CountVoterRoll(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+	participants, err := s.voterRollRepository.CountParticipants(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	quorum := &dto.QuorumResponse{
+		Type:           string(form.QuorumType),
+		Value:          form.Quorum,
+		EligibleVoters: int(rollSize),
+		Participants:   int(participants),
+	}
+
+	switch form.QuorumType {
+	case "":
+		quorum.Reached = true
+		quorum.Explanation = "The form has no quorum."
+		return quorum, nil
+	case model.QuorumAbsolute:
+		quorum.Required = form.Quorum
+	case model.QuorumPercentage:
+		if rollSize == 0 {
+			quorum.Explanation = "A percentage quorum needs a voter roll, and the form has none."
+			return quorum, nil
+		}
+		// Round up: 50% of 5 voters needs 3 participants
+		quorum.Required = (form.Quorum*int(rollSize) + 99) / 100
+	}
+
+	quorum.Reached = quorum.Participants >= quorum.Required
+	if quorum.Reached {
+		quorum.Explanation = fmt.Sprintf("%d participants took part, meeting the quorum of %d.", quorum.Participants, quorum.Required)
+	} else {
+		quorum.Explanation = fmt.Sprintf("Only %d participants took part, short of the quorum of %d.", quorum.Participants, quorum.Required)
+	}
+	return quorum, nil
+}
+
+// decideMotion decides whether the question passed. A broken tie counts as a
+// win for the option it picked; ties still waiting for the owner or a draw
+// leave the motion pending.
+func decideMotion(question *model.Question, result *dto.QuestionResultResponse, quorumReached bool, tieBreak *dto.TieBreakResponse) dto.MotionOutcomeResponse {
+	motion := dto.MotionOutcomeResponse{
+		QuestionID:    result.QuestionID,
+		Title:         result.Title,
+		TallyMethod:   result.TallyMethod,
+		PassThreshold: string(question.PassThreshold),
+		Status:        motionFailed,
+		Winners:       result.Winners,
+		Base:          result.Weight,
+		Abstentions:   result.Abstentions,
+		TieBreak:      tieBreak,
+	}
+	if question.CountAbstentions {
+		motion.Base += result.AbstentionWeight
+	}
+
+	names := optionNames(result)
+	for _, total := range result.Totals {
+		if len(result.Winners) > 0 && total.OptionID == result.Winners[0] {
+			motion.Votes = total.Total
+		}
+	}
+
+	tied := tiedResult(result)
+	explanation := result.Explanation
+	if tied && tieBreak != nil && tieBreak.Winner != 0 {
+		tied = false
+		motion.Winners = []uint{tieBreak.Winner}
+		explanation = fmt.Sprintf("%s tie with %d votes each. %s",
+			joinOptionNames(names, result.Winners), motion.Votes, tieBreak.Explanation)
+	}
+
+	switch {
+	case !quorumReached:
+		motion.Explanation = "The quorum was not reached, so nothing was decided."
+	case len(result.Winners) == 0:
+		motion.Explanation = "No votes were cast."
+	case tied && tieBreak != nil && tieBreak.Policy != string(model.TieBreakEarliest):
+		motion.Status = motionPending
+		motion.Explanation = tieBreak.Explanation
+	case tied:
+		motion.Explanation = fmt.Sprintf("%s tie with %d votes each, so no option was chosen.",
+			joinOptionNames(names, result.Winners), motion.Votes)
+		if tieBreak != nil {
+			motion.Explanation += " " + tieBreak.Explanation
+		}
+	case question.PassThreshold == "":
+		motion.Status = motionPassed
+		motion.Explanation = explanation
+	default:
+		threshold := tally.Threshold(question.PassThreshold)
+		base := "votes"
+		if question.CountAbstentions {
+			base = "votes and abstentions"
+		}
+		share := float64(motion.Votes) / float64(max(motion.Base, 1)) * 100
+
+		if threshold.Met(motion.Votes, motion.Base) {
+			motion.Status = motionPassed
+			motion.Explanation = fmt.Sprintf("%s passes with %d of %d %s (%.1f%%), meeting %s.",
+				names[motion.Winners[0]], motion.Votes, motion.Base, base, share, threshold.Describe())
+		} else {
+			motion.Explanation = fmt.Sprintf("%s has %d of %d %s (%.1f%%), short of %s.",
+				names[motion.Winners[0]], motion.Votes, motion.Base, base, share, threshold.Describe())
+		}
+		if tieBreak != nil && tieBreak.Winner != 0 {
+			motion.Explanation += " " + tieBreak.Explanation
+		}
+	}
+	return motion
+}
+
+// optionNames maps the options of a result to their quoted titles.
+func optionNames(result *dto.QuestionResultResponse) map[uint]string {
+	names := make(map[uint]string, len(result.Totals))
+	for _, total := range result.Totals {
+		names[total.OptionID] = fmt.Sprintf("%q", total.Title)
+	}
+	return names
+}
+
+func joinOptionNames(names map[uint]string, ids []uint) string {
+	joined := ""
+	for i, id := range ids {
+		switch {
+		case i == 0:
+		case i == len(ids)-1:
+			joined += " and "
+		default:
+			joined += ", "
+		}
+		joined += names[id]
+	}
+	return joined
+}
+
+// storedOutcome prepares the outcome of a closed form for storage. It is
+// final unless a motion is still pending.
+func storedOutcome(form *model.Form, outcome *dto.FormOutcomeResponse) (*model.FormOutcome, error) {
+	outcome.Final = true
+	for _, motion := range outcome.Motions {
+		if motion.Status == motionPending {
+			outcome.Final = false
+		}
+	}
+	report, err := json.Marshal(outcome)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &model.FormOutcome{
+		FormID:         form.ID,
+		ClosedAt:       form.EndAt,
+		Final:          outcome.Final,
+		QuorumReached:  outcome.Quorum.Reached,
+		EligibleVoters: outcome.Quorum.EligibleVoters,
+		Participants:   outcome.Quorum.Participants,
+		QuorumRequired: outcome.Quorum.Required,
+		Report:         string(report),
+	}
+	if outcome.Final {
+		now := time.Now()
+		stored.DecidedAt = &now
+	}
+	return stored, nil
+}
+
+func decodeOutcome(stored *model.FormOutcome) (*dto.FormOutcomeResponse, error) {
+	outcome := new(dto.FormOutcomeResponse)
+	if err := json.Unmarshal([]byte(stored.Report), outcome); err != nil {
+		return nil, err
+	}
+	outcome.Final = stored.Final
+	outcome.DecidedAt = stored.DecidedAt
+	return outcome, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"gorm.io/gorm"
+)
+
+func TestBreakTieEarliestKeepsReplacedBallotInPlace(t *testing.T) {
+	yes := model.Option{Model: gorm.Model{ID: 1}, Title: "Yes"}
+	no := model.Option{Model: gorm.Model{ID: 2}, Title: "No"}
+	question := &model.Question{
+		Model:       gorm.Model{ID: 7},
+		Type:        model.QuestionTypeSingleChoice,
+		TallyMethod: model.TallyMethodPlurality,
+		TieBreak:    model.TieBreakEarliest,
+		Options:     []*model.Option{&yes, &no},
+	}
+	result := &dto.QuestionResultResponse{
+		QuestionID: question.ID,
+		Totals: []dto.OptionTotalResponse{
+			{OptionID: yes.ID, Title: yes.Title, Total: 1},
+			{OptionID: no.ID, Title: no.Title, Total: 1},
+		},
+		Winners: []uint{yes.ID, no.ID},
+	}
+
+	// User 10 voted first and later replaced their ballot, so it was stored
+	// again after user 11's
+	answers := []*model.Answer{
+		{SubmissionID: 2, Submission: model.Submission{Model: gorm.Model{ID: 2}, UserID: 11}, QuestionID: question.ID, Options: []model.Option{no}},
+		{SubmissionID: 3, Submission: model.Submission{Model: gorm.Model{ID: 3}, UserID: 10}, QuestionID: question.ID, Options: []model.Option{yes}},
+	}
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	firstCast := map[uint]time.Time{
+		10: start,
+		11: start.Add(time.Minute),
+	}
+
+	tieBreak := breakTie(&model.Form{}, question, result, answers, firstCast, nil, false)
+	if tieBreak.Winner != yes.ID {
+		t.Errorf("winner = %d, want %d (%s)", tieBreak.Winner, yes.ID, tieBreak.Explanation)
+	}
+}
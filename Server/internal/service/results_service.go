@@ -0,0 +1,651 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/helper/auth"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tally"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type ResultsService interface {
+	GetFormResults(ctx context.Context, formID uint, userID uint) (*dto.FormResultsResponse, error)
+	ExportBallots(ctx context.Context, formID uint, userID uint) (*dto.BallotExportResponse, error)
+	ExportBLT(ctx context.Context, formID uint, questionID uint, userID uint) ([]byte, error)
+	GetWeightAudit(ctx context.Context, formID uint, userID uint) (*dto.WeightAuditResponse, error)
+	GetSharedResults(ctx context.Context, token string) (*dto.FormResultsResponse, error)
+	GetResultsVisibility(ctx context.Context, formID uint, userID uint) (*dto.ResultsVisibilityResponse, error)
+	SetResultsVisibility(ctx context.Context, formID uint, userID uint, req *dto.SetResultsVisibilityRequest) (*dto.ResultsVisibilityResponse, error)
+	RotateResultsLink(ctx context.Context, formID uint, userID uint) (*dto.ResultsVisibilityResponse, error)
+}
+
+type ResultsServiceImpl struct {
+	formRepository       repository.FormRepository
+	delegationRepository repository.DelegationRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+}
+
+func NewResultsService(
+	formRepository repository.FormRepository,
+	delegationRepository repository.DelegationRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+) ResultsService {
+	return &ResultsServiceImpl{
+		formRepository:       formRepository,
+		delegationRepository: delegationRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+	}
+}
+
+// GetFormResults tallies every choice question of the form with its tally
+// method. Text questions are left out. Results link to the round the form is
+// a runoff of and to the runoffs started from it.
+func (s *ResultsServiceImpl) GetFormResults(ctx context.Context, formID uint, userID uint) (*dto.FormResultsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.GetFormResults")
+	defer span.End()
+
+	form, answers, err := s.loadBallots(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.formResults(ctx, form, answers)
+}
+
+// GetSharedResults returns the results of a form to anyone holding its share
+// link.
+func (s *ResultsServiceImpl) GetSharedResults(ctx context.Context, token string) (*dto.FormResultsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.GetSharedResults")
+	defer span.End()
+
+	formID, err := s.authorizationService.CanViewSharedResults(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	form, answers, err := s.loadForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	return s.formResults(ctx, form, answers)
+}
+
+func (s *ResultsServiceImpl) formResults(ctx context.Context, form *model.Form, answers []*model.Answer) (*dto.FormResultsResponse, error) {
+	resp, err := tallyForm(form, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.RunoffOfID != nil {
+		previous, err := s.formRepository.GetForm(ctx, *form.RunoffOfID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			resp.RunoffOf = &dto.RoundLinkResponse{
+				FormID:     previous.ID,
+				QuestionID: *form.RunoffOfQuestionID,
+				Title:      previous.Title,
+				EndAt:      previous.EndAt,
+			}
+		}
+	}
+
+	runoffs, err := s.formRepository.GetRunoffs(ctx, form.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, runoff := range runoffs {
+		resp.Runoffs = append(resp.Runoffs, dto.RoundLinkResponse{
+			FormID:     runoff.ID,
+			QuestionID: *runoff.RunoffOfQuestionID,
+			Title:      runoff.Title,
+			EndAt:      runoff.EndAt,
+		})
+	}
+	return resp, nil
+}
+
+// tallyForm counts the answers to every choice question of the form, each
+// ballot carrying the weight of its voter. Abstentions are counted apart and
+// are not ballots.
+func tallyForm(form *model.Form, answers []*model.Answer) (*dto.FormResultsResponse, error) {
+	submissions := make(map[uint]bool)
+	ballots := make(map[uint][]tally.Ballot)
+	abstentions := make(map[uint]int)
+	abstentionWeights := make(map[uint]int)
+	for _, answer := range firstAnswers(answers) {
+		submissions[answer.SubmissionID] = true
+		if answer.Abstain {
+			abstentions[answer.QuestionID]++
+			abstentionWeights[answer.QuestionID] += submissionWeight(answer)
+			continue
+		}
+		ballots[answer.QuestionID] = append(ballots[answer.QuestionID], toBallot(answer))
+	}
+
+	resp := &dto.FormResultsResponse{
+		FormID:      form.ID,
+		Title:       form.Title,
+		Submissions: len(submissions),
+		Questions:   []dto.QuestionResultResponse{},
+	}
+
+	for i := range form.Questions {
+		question := &form.Questions[i]
+		if question.TallyMethod == "" {
+			continue
+		}
+
+		questionResult, err := tallyQuestion(question, ballots[question.ID])
+		if err != nil {
+			return nil, err
+		}
+		questionResult.Abstentions = abstentions[question.ID]
+		questionResult.AbstentionWeight = abstentionWeights[question.ID]
+		resp.Questions = append(resp.Questions, *questionResult)
+	}
+
+	return resp, nil
+}
+
+// ExportBallots lists the answers to the form's choice questions. Ballots carry
+// no submission or user IDs, identical ones are merged into one entry with
+// their count and total weight, and entries are sorted by content, so the
+// export reveals nothing about who voted when or with which weight, yet
+// counting it gives the same results as GetFormResults.
+func (s *ResultsServiceImpl) ExportBallots(ctx context.Context, formID uint, userID uint) (*dto.BallotExportResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.ExportBallots")
+	defer span.End()
+
+	form, answers, err := s.loadBallots(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.BallotExportResponse{
+		FormID:    form.ID,
+		Title:     form.Title,
+		Questions: []dto.BallotQuestionResponse{},
+		Ballots:   []dto.ExportedBallot{},
+	}
+	counted := make(map[uint]bool)
+	for i := range form.Questions {
+		question := &form.Questions[i]
+		if question.TallyMethod == "" {
+			continue
+		}
+		counted[question.ID] = true
+
+		exported := dto.BallotQuestionResponse{
+			QuestionID:  question.ID,
+			Title:       question.Title,
+			TallyMethod: string(question.TallyMethod),
+			MaxScore:    question.MaxScore,
+			Seats:       question.Seats,
+		}
+		for _, option := range questionOptions(question) {
+			exported.Options = append(exported.Options, dto.GetOptionResponse{ID: option.ID, Title: option.Name})
+		}
+		resp.Questions = append(resp.Questions, exported)
+	}
+	sort.Slice(resp.Questions, func(i, j int) bool {
+		return resp.Questions[i].QuestionID < resp.Questions[j].QuestionID
+	})
+
+	bySubmission := make(map[uint]*dto.ExportedBallot)
+	for _, answer := range answers {
+		if !counted[answer.QuestionID] {
+			continue
+		}
+		ballot, ok := bySubmission[answer.SubmissionID]
+		if !ok {
+			ballot = &dto.ExportedBallot{Count: 1, Weight: submissionWeight(answer)}
+			bySubmission[answer.SubmissionID] = ballot
+		}
+		ballot.Answers = append(ballot.Answers, exportAnswer(answer))
+	}
+
+	// Identical ballots are merged, so no single voter's weight shows
+	merged := make(map[string]*dto.ExportedBallot, len(bySubmission))
+	keys := make([]string, 0, len(bySubmission))
+	for _, ballot := range bySubmission {
+		sort.Slice(ballot.Answers, func(i, j int) bool {
+			return ballot.Answers[i].QuestionID < ballot.Answers[j].QuestionID
+		})
+		encoded, err := json.Marshal(ballot.Answers)
+		if err != nil {
+			return nil, err
+		}
+		key := string(encoded)
+		if existing, ok := merged[key]; ok {
+			existing.Count += ballot.Count
+			existing.Weight += ballot.Weight
+			continue
+		}
+		merged[key] = ballot
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resp.Ballots = append(resp.Ballots, *merged[key])
+	}
+
+	return resp, nil
+}
+
+// ExportBLT writes the ranked ballots of one question in the BLT format used
+// by STV counting programs.
+func (s *ResultsServiceImpl) ExportBLT(ctx context.Context, formID uint, questionID uint, userID uint) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.ExportBLT")
+	defer span.End()
+
+	form, answers, err := s.loadBallots(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var question *model.Question
+	for i := range form.Questions {
+		if form.Questions[i].ID == questionID {
+			question = &form.Questions[i]
+		}
+	}
+	if question == nil {
+		return nil, apperr.ErrNotFound.WithMessage(fmt.Sprintf("question %d is not part of this form", questionID))
+	}
+	if !tally.Method(question.TallyMethod).Ranked() {
+		return nil, apperr.ErrBadRequest.WithMessage("only ranked questions can be exported as BLT")
+	}
+
+	var ballots []tally.Ballot
+	for _, answer := range answers {
+		if answer.QuestionID == questionID {
+			ballots = append(ballots, toBallot(answer))
+		}
+	}
+	// BLT files list ballots in order; sort them so the file does not reveal
+	// the order in which people voted, and merge identical rankings so it
+	// does not reveal any single voter's weight either
+	sort.Slice(ballots, func(i, j int) bool {
+		return lessRanking(ballots[i].Ranking, ballots[j].Ranking)
+	})
+	ballots = mergeRankings(ballots)
+
+	var buf bytes.Buffer
+	if err := tally.WriteBLT(&buf, question.Title, questionContest(question), ballots); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeRankings combines adjacent ballots with the same ranking into one
+// carrying their total weight.
+func mergeRankings(ballots []tally.Ballot) []tally.Ballot {
+	var merged []tally.Ballot
+	for _, ballot := range ballots {
+		weight := max(ballot.Weight, 1)
+		if last := len(merged) - 1; last >= 0 && slices.Equal(merged[last].Ranking, ballot.Ranking) {
+			merged[last].Weight += weight
+			continue
+		}
+		ballot.Weight = weight
+		merged = append(merged, ballot)
+	}
+	return merged
+}
+
+// GetWeightAudit lists which voter cast each ballot of the form, with which
+// weight and through which proxy, alongside the form's delegations. Only the
+// form's owners see it, as it names every voter.
+func (s *ResultsServiceImpl) GetWeightAudit(ctx context.Context, formID uint, userID uint) (*dto.WeightAuditResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.GetWeightAudit")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	submissions, err := s.formRepository.GetSubmissionWeights(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	delegations, err := s.delegationRepository.GetFormDelegations(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.WeightAuditResponse{
+		FormID:      formID,
+		Ballots:     make([]dto.WeightedBallotResponse, len(submissions)),
+		Delegations: make([]dto.DelegationAuditResponse, len(delegations)),
+	}
+	for i, submission := range submissions {
+		ballot := dto.WeightedBallotResponse{
+			SubmissionID: submission.ID,
+			UserID:       submission.UserID,
+			Email:        submission.User.Email,
+			Weight:       submission.Weight,
+			CastByID:     submission.CastByID,
+			SubmittedAt:  submission.CompletedAt,
+		}
+		if submission.CastBy != nil {
+			ballot.CastByEmail = submission.CastBy.Email
+		}
+		resp.Ballots[i] = ballot
+		resp.TotalWeight += submission.Weight
+	}
+	for i, delegation := range delegations {
+		resp.Delegations[i] = dto.DelegationAuditResponse{
+			DelegatorID:    delegation.DelegatorID,
+			DelegatorEmail: delegation.Delegator.Email,
+			DelegateID:     delegation.DelegateID,
+			DelegateEmail:  delegation.Delegate.Email,
+			CreatedAt:      delegation.CreatedAt,
+		}
+	}
+	return resp, nil
+}
+
+// GetResultsVisibility returns who can see the form's results and, when they
+// are shared by link, the link.
+func (s *ResultsServiceImpl) GetResultsVisibility(ctx context.Context, formID uint, userID uint) (*dto.ResultsVisibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.GetResultsVisibility")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	return s.resultsVisibility(ctx, formID)
+}
+
+// SetResultsVisibility replaces the form's results visibility policy and
+// embargo. Requests without an embargo lift it.
+func (s *ResultsServiceImpl) SetResultsVisibility(ctx context.Context, formID uint, userID uint, req *dto.SetResultsVisibilityRequest) (*dto.ResultsVisibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.SetResultsVisibility")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	visibility := model.ResultsVisibility(req.Visibility)
+	if err := s.formRepository.UpdateResultsVisibility(ctx, formID, visibility, req.EmbargoUntil); err != nil {
+		return nil, err
+	}
+	return s.resultsVisibility(ctx, formID)
+}
+
+// RotateResultsLink replaces the form's share link, so links handed out
+// before stop working.
+func (s *ResultsServiceImpl) RotateResultsLink(ctx context.Context, formID uint, userID uint) (*dto.ResultsVisibilityResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResultsService.RotateResultsLink")
+	defer span.End()
+
+	if err := s.authorizationService.CanManageForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	if err := s.formRepository.RotateResultsLink(ctx, formID); err != nil {
+		return nil, err
+	}
+	return s.resultsVisibility(ctx, formID)
+}
+
+func (s *ResultsServiceImpl) resultsVisibility(ctx context.Context, formID uint) (*dto.ResultsVisibilityResponse, error) {
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ResultsVisibilityResponse{
+		FormID:       form.ID,
+		Visibility:   string(form.ResultsVisibility),
+		EmbargoUntil: form.ResultsEmbargoUntil,
+	}
+	if resp.Visibility == "" {
+		resp.Visibility = string(model.ResultsOwner)
+	}
+	if form.ResultsVisibility == model.ResultsLink {
+		resp.ShareToken = auth.SignResultsLink(form.ID, form.ResultsLinkVersion)
+		resp.SharePath = "/api/v1/shared/results/" + resp.ShareToken
+	}
+	return resp, nil
+}
+
+// loadBallots checks that the user may see the form's results and loads the
+// form with every submitted answer.
+func (s *ResultsServiceImpl) loadBallots(ctx context.Context, formID uint, userID uint) (*model.Form, []*model.Answer, error) {
+	if err := s.authorizationService.CanViewFormResults(ctx, userID, formID); err != nil {
+		return nil, nil, err
+	}
+	return s.loadForm(ctx, formID)
+}
+
+func (s *ResultsServiceImpl) loadForm(ctx context.Context, formID uint) (*model.Form, []*model.Answer, error) {
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	answers, err := s.formRepository.GetFormAnswers(ctx, formID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return form, firstAnswers(answers), nil
+}
+
+// firstAnswers keeps one answer per submission and question, so a submission
+// never counts as more than one ballot on a question. Submissions are checked
+// for repeated questions before they are stored; this guards the count
+// against any stored before that check.
+func firstAnswers(answers []*model.Answer) []*model.Answer {
+	type key struct{ submissionID, questionID uint }
+	seen := make(map[key]bool, len(answers))
+	first := make([]*model.Answer, 0, len(answers))
+	for _, answer := range answers {
+		k := key{answer.SubmissionID, answer.QuestionID}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		first = append(first, answer)
+	}
+	return first
+}
+
+func tallyQuestion(question *model.Question, ballots []tally.Ballot) (*dto.QuestionResultResponse, error) {
+	contest := questionContest(question)
+	options := contest.Options
+	result, err := tally.Count(contest, ballots)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[uint]string, len(options))
+	for _, option := range options {
+		titles[option.ID] = option.Name
+	}
+
+	resp := &dto.QuestionResultResponse{
+		QuestionID:  question.ID,
+		Title:       question.Title,
+		TallyMethod: string(question.TallyMethod),
+		Ballots:     result.Ballots,
+		Weight:      result.Weight,
+		Totals:      make([]dto.OptionTotalResponse, len(result.Totals)),
+		Winners:     result.Winners,
+		Explanation: result.Explanation,
+	}
+	if resp.Winners == nil {
+		resp.Winners = []uint{}
+	}
+	for i, total := range result.Totals {
+		resp.Totals[i] = dto.OptionTotalResponse{
+			OptionID: total.OptionID,
+			Title:    titles[total.OptionID],
+			Total:    total.Total,
+		}
+	}
+	if result.Pairwise != nil {
+		resp.Pairwise = &dto.PairwiseResponse{
+			Options:        result.Pairwise.Options,
+			Preferences:    result.Pairwise.Preferences,
+			StrongestPaths: result.Pairwise.StrongestPaths,
+		}
+	}
+
+	if result.STV != nil {
+		resp.STV = stvReport(result.STV)
+	}
+
+	return resp, nil
+}
+
+func stvReport(report *tally.STVReport) *dto.STVReportResponse {
+	resp := &dto.STVReportResponse{
+		Seats:        report.Seats,
+		ValidBallots: report.ValidBallots,
+		ValidVotes:   report.ValidVotes,
+		Quota:        report.Quota,
+		Rounds:       make([]dto.STVRoundResponse, len(report.Rounds)),
+	}
+	for i, round := range report.Rounds {
+		r := dto.STVRoundResponse{
+			Round:      round.Number,
+			Votes:      make([]dto.OptionVotesResponse, len(round.Votes)),
+			Exhausted:  round.Exhausted,
+			Elected:    round.Elected,
+			Eliminated: round.Eliminated,
+			Transfers:  make([]dto.STVTransferResponse, len(round.Transfers)),
+			Note:       round.Note,
+		}
+		if r.Elected == nil {
+			r.Elected = []uint{}
+		}
+		if r.Eliminated == nil {
+			r.Eliminated = []uint{}
+		}
+		for j, votes := range round.Votes {
+			r.Votes[j] = dto.OptionVotesResponse{OptionID: votes.OptionID, Votes: votes.Votes}
+		}
+		for j, transfer := range round.Transfers {
+			r.Transfers[j] = dto.STVTransferResponse{
+				From:   transfer.From,
+				Reason: transfer.Reason,
+				Votes:  transfer.Votes,
+				Factor: transfer.Factor,
+			}
+		}
+		resp.Rounds[i] = r
+	}
+	return resp
+}
+
+func questionContest(question *model.Question) tally.Contest {
+	return tally.Contest{
+		Method:  tally.Method(question.TallyMethod),
+		Options: questionOptions(question),
+		Seats:   question.Seats,
+	}
+}
+
+// questionOptions lists the question's options by ID so results are stable.
+func questionOptions(question *model.Question) []tally.Option {
+	options := make([]tally.Option, len(question.Options))
+	for i, option := range question.Options {
+		options[i] = tally.Option{ID: option.ID, Name: option.Title}
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].ID < options[j].ID
+	})
+	return options
+}
+
+func toBallot(answer *model.Answer) tally.Ballot {
+	ranking, scores := ballotPreferences(answer.Preferences)
+
+	ballot := tally.Ballot{Ranking: ranking, Weight: submissionWeight(answer)}
+	if len(scores) > 0 {
+		ballot.Scores = make(map[uint]int, len(scores))
+		for _, score := range scores {
+			ballot.Scores[score.OptionID] = score.Score
+		}
+	}
+	for _, option := range answer.Options {
+		ballot.Choices = append(ballot.Choices, option.ID)
+	}
+	return ballot
+}
+
+// submissionWeight is the weight of the voter who gave the answer. Answers
+// loaded without their submission count once.
+func submissionWeight(answer *model.Answer) int {
+	if answer.Submission.Weight < 1 {
+		return 1
+	}
+	return answer.Submission.Weight
+}
+
+func exportAnswer(answer *model.Answer) dto.ExportedAnswer {
+	ranking, scores := ballotPreferences(answer.Preferences)
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].OptionID < scores[j].OptionID
+	})
+
+	exported := dto.ExportedAnswer{QuestionID: answer.QuestionID, Ranking: ranking, Scores: scores, Abstain: answer.Abstain}
+	// Ranked and score ballots link their options too; only list choices for
+	// the methods that read them
+	if len(ranking) == 0 && len(scores) == 0 {
+		for _, option := range answer.Options {
+			exported.OptionIDs = append(exported.OptionIDs, option.ID)
+		}
+		sort.Slice(exported.OptionIDs, func(i, j int) bool {
+			return exported.OptionIDs[i] < exported.OptionIDs[j]
+		})
+	}
+	return exported
+}
+
+func lessRanking(a, b []uint) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// ballotPreferences splits stored preferences into the ranking, most
+// preferred first, and the scores of the ballot.
+func ballotPreferences(preferences []model.AnswerPreference) ([]uint, []dto.OptionScore) {
+	var ranked []model.AnswerPreference
+	var scores []dto.OptionScore
+	for _, preference := range preferences {
+		if preference.Rank > 0 {
+			ranked = append(ranked, preference)
+		} else {
+			scores = append(scores, dto.OptionScore{OptionID: preference.OptionID, Score: preference.Score})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].Rank < ranked[j].Rank
+	})
+	var ranking []uint
+	for _, preference := range ranked {
+		ranking = append(ranking, preference.OptionID)
+	}
+
+	return ranking, scores
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/notify"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+)
+
+const defaultRunoffOptions = 2
+
+type RunoffService interface {
+	CreateRunoff(ctx context.Context, formID uint, userID uint, req *dto.CreateRunoffRequest) (*model.Form, error)
+}
+
+type RunoffServiceImpl struct {
+	formRepository         repository.FormRepository
+	voterRollRepository    repository.VoterRollRepository
+	collaboratorRepository repository.CollaboratorRepository
+	formService            FormService
+	resultsService         ResultsService
+	outcomeService         OutcomeService
+	authorizationService   FormAuthorizationService
+	notifier               notify.Notifier
+	frontendURL            string
+}
+
+func NewRunoffService(
+	formRepository repository.FormRepository,
+	voterRollRepository repository.VoterRollRepository,
+	collaboratorRepository repository.CollaboratorRepository,
+	formService FormService,
+	resultsService ResultsService,
+	outcomeService OutcomeService,
+	authorizationService FormAuthorizationService,
+	notifier notify.Notifier,
+	frontendURL string,
+) RunoffService {
+	return &RunoffServiceImpl{
+		formRepository:         formRepository,
+		voterRollRepository:    voterRollRepository,
+		collaboratorRepository: collaboratorRepository,
+		formService:            formService,
+		resultsService:         resultsService,
+		outcomeService:         outcomeService,
+		authorizationService:   authorizationService,
+		notifier:               notifier,
+		frontendURL:            frontendURL,
+	}
+}
+
+// CreateRunoff starts a new form that decides a failed question of a closed
+// form between its leading options. The runoff keeps the question's settings,
+// the form's quorum, its voter roll and its collaborators, and the eligible
+// voters are told about it.
+func (s *RunoffServiceImpl) CreateRunoff(ctx context.Context, formID uint, userID uint, req *dto.CreateRunoffRequest) (*model.Form, error) {
+	ctx, span := tracing.Start(ctx, "RunoffService.CreateRunoff")
+	defer span.End()
+
+	if err := s.authorizationService.CanEditForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	outcome, err := s.outcomeService.GetFormOutcome(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !outcome.Final {
+		return nil, ErrRunoffNotAllowed.WithMessage("runoffs can only start once the outcome of the form is final")
+	}
+
+	var motion *dto.MotionOutcomeResponse
+	for i := range outcome.Motions {
+		if outcome.Motions[i].QuestionID == req.QuestionID {
+			motion = &outcome.Motions[i]
+		}
+	}
+	if motion == nil {
+		return nil, apperr.ErrNotFound.WithMessage(fmt.Sprintf("question %d is not part of this form", req.QuestionID))
+	}
+	if motion.Status != motionFailed {
+		return nil, ErrRunoffNotAllowed.WithMessage("only questions that failed can have a runoff")
+	}
+
+	runoffs, err := s.formRepository.GetRunoffs(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	for _, runoff := range runoffs {
+		if runoff.RunoffOfQuestionID != nil && *runoff.RunoffOfQuestionID == req.QuestionID {
+			return nil, ErrRunoffNotAllowed.WithMessage("this question already has a runoff")
+		}
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.resultsService.GetFormResults(ctx, formID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var question *model.Question
+	for i := range form.Questions {
+		if form.Questions[i].ID == req.QuestionID {
+			question = &form.Questions[i]
+		}
+	}
+	var result *dto.QuestionResultResponse
+	for i := range results.Questions {
+		if results.Questions[i].QuestionID == req.QuestionID {
+			result = &results.Questions[i]
+		}
+	}
+
+	count := req.Options
+	if count == 0 {
+		count = defaultRunoffOptions
+	}
+	leading := leadingOptions(result, count)
+	if len(leading) < 2 {
+		return nil, ErrRunoffNotAllowed.WithMessage("a runoff needs at least two options that received votes")
+	}
+
+	startAt := time.Now()
+	if req.StartAt != nil {
+		startAt = *req.StartAt
+	}
+	if !req.EndAt.After(startAt) || !req.EndAt.After(time.Now()) {
+		field := apperr.FieldError{Field: "endAt", Message: "the runoff must end in the future and after it starts"}
+		return nil, apperr.ErrValidation.WithMessage(field.Message).WithFields(field)
+	}
+
+	title := form.Title + " (runoff)"
+	if req.Title != nil {
+		title = *req.Title
+	}
+
+	options := make([]*model.Option, len(leading))
+	for i, total := range leading {
+		options[i] = &model.Option{Title: total.Title}
+	}
+	runoff := &model.Form{
+		Title:              title,
+		Description:        form.Description,
+		StartAt:            startAt,
+		EndAt:              req.EndAt,
+		QuorumType:         form.QuorumType,
+		Quorum:             form.Quorum,
+		UserID:             form.UserID,
+		RunoffOfID:         &form.ID,
+		RunoffOfQuestionID: &question.ID,
+		Questions: []model.Question{{
+			Title:            question.Title,
+			Type:             question.Type,
+			TallyMethod:      question.TallyMethod,
+			MaxScore:         question.MaxScore,
+			Seats:            question.Seats,
+			PassThreshold:    question.PassThreshold,
+			CountAbstentions: question.CountAbstentions,
+			TieBreak:         question.TieBreak,
+			Options:          options,
+		}},
+	}
+	runoff, err = s.formService.CreateForm(ctx, runoff)
+	if err != nil {
+		return nil, err
+	}
+
+	roll, err := s.voterRollRepository.GetVoterRoll(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roll) > 0 {
+		voters := make([]model.EligibleVoter, len(roll))
+		for i, voter := range roll {
+			voters[i] = model.EligibleVoter{UserID: voter.UserID, Weight: voter.Weight}
+		}
+		if err := s.voterRollRepository.ReplaceVoterRoll(ctx, runoff.ID, voters); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.collaboratorRepository.CopyCollaborators(ctx, formID, runoff.ID); err != nil {
+		return nil, err
+	}
+
+	s.notifyVoters(ctx, form, runoff, roll)
+	return runoff, nil
+}
+
+// leadingOptions returns the count options with the highest totals. Options
+// tied with the last one taken go through as well, and options without votes
+// never do.
+func leadingOptions(result *dto.QuestionResultResponse, count int) []dto.OptionTotalResponse {
+	totals := make([]dto.OptionTotalResponse, 0, len(result.Totals))
+	for _, total := range result.Totals {
+		if total.Total > 0 {
+			totals = append(totals, total)
+		}
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].Total > totals[j].Total
+	})
+
+	n := min(count, len(totals))
+	for n < len(totals) && n > 0 && totals[n].Total == totals[n-1].Total {
+		n++
+	}
+	return totals[:n]
+}
+
+// notifyVoters tells the voters of the runoff that it has started: everyone
+// on the voter roll, or everyone who voted in the first round when the form
+// has no roll. Notifications are best effort; the runoff exists either way.
+func (s *RunoffServiceImpl) notifyVoters(ctx context.Context, form *model.Form, runoff *model.Form, roll []*model.EligibleVoter) {
+	var emails []string
+	if len(roll) > 0 {
+		for _, voter := range roll {
+			emails = append(emails, voter.User.Email)
+		}
+	} else {
+		submissions, err := s.formRepository.GetSubmissionWeights(ctx, form.ID)
+		if err != nil {
+			applog.FromContext(ctx).WarnContext(ctx, "failed to load runoff voters",
+				"form_id", runoff.ID,
+				"error", err)
+			return
+		}
+		for _, submission := range submissions {
+			emails = append(emails, submission.User.Email)
+		}
+	}
+
+	link := s.frontendURL + "/polls/" + strconv.FormatUint(uint64(runoff.ID), 10) + "/submit"
+	for _, email := range emails {
+		err := s.notifier.Send(ctx, notify.Message{
+			To:      email,
+			Subject: "Runoff: " + runoff.Title,
+			Body: fmt.Sprintf("No option won %q, so a runoff between the leading options is open until %s: %s",
+				form.Title, runoff.EndAt.Format(time.RFC1123), link),
+		})
+		if err != nil {
+			applog.FromContext(ctx).WarnContext(ctx, "failed to notify runoff voter",
+				"form_id", runoff.ID,
+				"error", err)
+		}
+	}
+}
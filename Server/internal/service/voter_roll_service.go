@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luneto10/voting-system/api/dto"
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+	"github.com/luneto10/voting-system/internal/repository"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"gorm.io/gorm"
+)
+
+type VoterRollService interface {
+	GetVoterRoll(ctx context.Context, formID uint, userID uint) ([]*model.EligibleVoter, error)
+	SetVoterRoll(ctx context.Context, formID uint, userID uint, req *dto.SetVoterRollRequest) ([]*model.EligibleVoter, error)
+}
+
+type VoterRollServiceImpl struct {
+	voterRollRepository  repository.VoterRollRepository
+	userRepository       repository.UserRepository
+	formService          FormService
+	authorizationService FormAuthorizationService
+}
+
+func NewVoterRollService(
+	voterRollRepository repository.VoterRollRepository,
+	userRepository repository.UserRepository,
+	formService FormService,
+	authorizationService FormAuthorizationService,
+) VoterRollService {
+	return &VoterRollServiceImpl{
+		voterRollRepository:  voterRollRepository,
+		userRepository:       userRepository,
+		formService:          formService,
+		authorizationService: authorizationService,
+	}
+}
+
+func (s *VoterRollServiceImpl) GetVoterRoll(ctx context.Context, formID uint, userID uint) ([]*model.EligibleVoter, error) {
+	ctx, span := tracing.Start(ctx, "VoterRollService.GetVoterRoll")
+	defer span.End()
+
+	if err := s.authorizationService.CanViewForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+	return s.voterRollRepository.GetVoterRoll(ctx, formID)
+}
+
+// SetVoterRoll replaces the form's voter roll with the users registered under
+// the given emails. Voters listed by plain email get a weight of 1. An empty
+// list removes the roll, opening the form to every user again. The roll of a
+// closed form cannot change.
+func (s *VoterRollServiceImpl) SetVoterRoll(ctx context.Context, formID uint, userID uint, req *dto.SetVoterRollRequest) ([]*model.EligibleVoter, error) {
+	ctx, span := tracing.Start(ctx, "VoterRollService.SetVoterRoll")
+	defer span.End()
+
+	if err := s.authorizationService.CanEditForm(ctx, userID, formID); err != nil {
+		return nil, err
+	}
+
+	form, err := s.formService.GetForm(ctx, formID)
+	if err != nil {
+		return nil, err
+	}
+	if form.Closed(time.Now()) {
+		return nil, ErrFormClosed
+	}
+
+	type entry struct {
+		field  string
+		email  string
+		weight int
+	}
+	entries := make([]entry, 0, len(req.Emails)+len(req.Voters))
+	for i, email := range req.Emails {
+		entries = append(entries, entry{fmt.Sprintf("emails.%d", i), email, 1})
+	}
+	for i, voter := range req.Voters {
+		entries = append(entries, entry{fmt.Sprintf("voters.%d.email", i), voter.Email, max(voter.Weight, 1)})
+	}
+
+	var voters []model.EligibleVoter
+	var fields []apperr.FieldError
+	seen := make(map[uint]bool, len(entries))
+	for _, e := range entries {
+		user, err := s.userRepository.GetUserByEmail(ctx, strings.TrimSpace(e.email))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fields = append(fields, apperr.FieldError{Field: e.field, Message: "no user is registered with this email"})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if user.ID == form.UserID {
+			fields = append(fields, apperr.FieldError{Field: e.field, Message: "the form owner cannot vote on their own form"})
+			continue
+		}
+		if !seen[user.ID] {
+			seen[user.ID] = true
+			voters = append(voters, model.EligibleVoter{UserID: user.ID, Weight: e.weight})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, apperr.ErrValidation.WithMessage("some voters could not be added to the roll").WithFields(fields...)
+	}
+
+	if err := s.voterRollRepository.ReplaceVoterRoll(ctx, formID, voters); err != nil {
+		return nil, err
+	}
+	return s.voterRollRepository.GetVoterRoll(ctx, formID)
+}
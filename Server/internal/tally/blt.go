@@ -0,0 +1,54 @@
+package tally
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteBLT writes the ranked ballots of a contest in the BLT format read by
+// most STV counting programs, so a count can be checked independently.
+// Candidates are numbered from 1 in the order of contest.Options, each ballot
+// line starts with the ballot's weight, and ballots ranking no option are left
+// out.
+func WriteBLT(w io.Writer, title string, contest Contest, ballots []Ballot) error {
+	bw := bufio.NewWriter(w)
+	index := optionIndex(contest.Options)
+
+	seats := contest.Seats
+	if seats < 1 {
+		seats = 1
+	}
+	fmt.Fprintf(bw, "%d %d\n", len(contest.Options), seats)
+
+	for _, ballot := range ballots {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%d", ballot.weight())
+		ranked := 0
+		for _, id := range dedupe(ballot.Ranking) {
+			if i, ok := index[id]; ok {
+				fmt.Fprintf(&line, " %d", i+1)
+				ranked++
+			}
+		}
+		if ranked == 0 {
+			continue
+		}
+		line.WriteString(" 0\n")
+		bw.WriteString(line.String())
+	}
+	bw.WriteString("0\n")
+
+	for _, option := range contest.Options {
+		fmt.Fprintf(bw, "%s\n", bltQuote(option.Name))
+	}
+	fmt.Fprintf(bw, "%s\n", bltQuote(title))
+
+	return bw.Flush()
+}
+
+func bltQuote(s string) string {
+	s = strings.NewReplacer("\"", "'", "\n", " ", "\r", " ").Replace(s)
+	return "\"" + s + "\""
+}
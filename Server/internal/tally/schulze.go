@@ -0,0 +1,170 @@
+package tally
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pairwise holds the head-to-head comparison of every pair of options.
+type Pairwise struct {
+	Options []uint
+	// Preferences[i][j] is the number of votes ranking Options[i] above
+	// Options[j], counting each ballot by its weight.
+	Preferences [][]int
+	// StrongestPaths[i][j] is the strength of the strongest path from
+	// Options[i] to Options[j], where a link i->j is as strong as the number
+	// of ballots preferring i when more ballots prefer i over j than the
+	// reverse, and absent otherwise.
+	StrongestPaths [][]int
+}
+
+// countSchulze elects the options that beat or tie every other option on
+// strongest paths. That is always the Condorcet winner when there is one.
+func countSchulze(options []Option, ballots []Ballot, result *Result) {
+	n := len(options)
+	pairwise := &Pairwise{
+		Options:        make([]uint, n),
+		Preferences:    newMatrix(n),
+		StrongestPaths: newMatrix(n),
+	}
+	for i, option := range options {
+		pairwise.Options[i] = option.ID
+	}
+
+	index := optionIndex(options)
+	for _, ballot := range ballots {
+		// Unranked options share the position after the last ranked one
+		rank := make([]int, n)
+		for i := range rank {
+			rank[i] = n
+		}
+		position := 0
+		for _, id := range dedupe(ballot.Ranking) {
+			if i, ok := index[id]; ok {
+				rank[i] = position
+				position++
+			}
+		}
+
+		weight := ballot.weight()
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if rank[i] < rank[j] {
+					pairwise.Preferences[i][j] += weight
+				}
+			}
+		}
+	}
+
+	d, p := pairwise.Preferences, pairwise.StrongestPaths
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && d[i][j] > d[j][i] {
+				p[i][j] = d[i][j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if j == i || j == k {
+					continue
+				}
+				p[i][j] = max(p[i][j], min(p[i][k], p[k][j]))
+			}
+		}
+	}
+
+	result.Pairwise = pairwise
+	result.Totals = make([]OptionTotal, n)
+	for i := 0; i < n; i++ {
+		result.Totals[i].OptionID = options[i].ID
+		beatsOrTiesAll := true
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			if p[i][j] > p[j][i] {
+				result.Totals[i].Total++
+			}
+			if p[i][j] < p[j][i] {
+				beatsOrTiesAll = false
+			}
+		}
+		if beatsOrTiesAll {
+			result.Winners = append(result.Winners, options[i].ID)
+		}
+	}
+
+	result.Explanation = explainSchulze(options, pairwise, result.Winners)
+}
+
+func explainSchulze(options []Option, pairwise *Pairwise, winners []uint) string {
+	if len(options) == 0 {
+		return "There are no options to choose from."
+	}
+
+	names := optionNames(options)
+	index := optionIndex(options)
+	d, p := pairwise.Preferences, pairwise.StrongestPaths
+
+	if c, ok := condorcetWinner(d); ok {
+		var wins []string
+		for j := range options {
+			if j != c {
+				wins = append(wins, fmt.Sprintf("%s: %d to %d", names[options[j].ID], d[c][j], d[j][c]))
+			}
+		}
+		explanation := fmt.Sprintf("%s is the Condorcet winner: more voters prefer it to each other option", names[options[c].ID])
+		if len(wins) > 0 {
+			explanation += " (" + strings.Join(wins, ", ") + ")"
+		}
+		return explanation + "."
+	}
+
+	explanation := "There is no Condorcet winner, as no option beats every other one head to head. "
+	if len(winners) > 1 {
+		return explanation + fmt.Sprintf("%s tie on strongest paths.", joinNames(names, winners))
+	}
+
+	w := index[winners[0]]
+	var paths []string
+	for j := range options {
+		if j != w {
+			paths = append(paths, fmt.Sprintf("%s: %d to %d", names[options[j].ID], p[w][j], p[j][w]))
+		}
+	}
+	return explanation + fmt.Sprintf(
+		"%s wins under Schulze: its strongest path to every other option is at least as strong as the path back (%s).",
+		names[winners[0]], strings.Join(paths, ", "),
+	)
+}
+
+// condorcetWinner returns the index of the option preferred to every other
+// option by more voters than the reverse, if there is one.
+func condorcetWinner(d [][]int) (int, bool) {
+	for i := range d {
+		winner := true
+		for j := range d {
+			if i != j && d[i][j] <= d[j][i] {
+				winner = false
+				break
+			}
+		}
+		if winner {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func newMatrix(n int) [][]int {
+	matrix := make([][]int, n)
+	for i := range matrix {
+		matrix[i] = make([]int, n)
+	}
+	return matrix
+}
@@ -0,0 +1,113 @@
+package tally
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCountSchulze(t *testing.T) {
+	tests := []struct {
+		name           string
+		ballots        []Ballot
+		preferences    [][]int
+		strongestPaths [][]int
+		totals         []OptionTotal
+		winners        []uint
+		explanation    string
+	}{
+		{
+			name: "condorcet winner",
+			ballots: []Ballot{
+				{Ranking: []uint{optA, optB, optC}, Weight: 3},
+				{Ranking: []uint{optB, optC, optA}, Weight: 2},
+			},
+			preferences:    [][]int{{0, 3, 3}, {2, 0, 5}, {2, 0, 0}},
+			strongestPaths: [][]int{{0, 3, 3}, {0, 0, 5}, {0, 0, 0}},
+			totals:         totals(2, 1, 0),
+			winners:        []uint{optA},
+			explanation:    `"A" is the Condorcet winner: more voters prefer it to each other option ("B": 3 to 2, "C": 3 to 2).`,
+		},
+		{
+			name: "cycle decided by strongest paths",
+			ballots: []Ballot{
+				{Ranking: []uint{optA, optB, optC}, Weight: 4},
+				{Ranking: []uint{optB, optC, optA}, Weight: 3},
+				{Ranking: []uint{optC, optA, optB}, Weight: 2},
+			},
+			preferences:    [][]int{{0, 6, 4}, {3, 0, 7}, {5, 2, 0}},
+			strongestPaths: [][]int{{0, 6, 6}, {5, 0, 7}, {5, 5, 0}},
+			totals:         totals(2, 1, 0),
+			winners:        []uint{optA},
+			explanation: `There is no Condorcet winner, as no option beats every other one head to head. ` +
+				`"A" wins under Schulze: its strongest path to every other option is at least as strong as the path back ("B": 6 to 5, "C": 6 to 5).`,
+		},
+		{
+			name: "symmetric cycle ties",
+			ballots: []Ballot{
+				{Ranking: []uint{optA, optB, optC}},
+				{Ranking: []uint{optB, optC, optA}},
+				{Ranking: []uint{optC, optA, optB}},
+			},
+			preferences:    [][]int{{0, 2, 1}, {1, 0, 2}, {2, 1, 0}},
+			strongestPaths: [][]int{{0, 2, 2}, {2, 0, 2}, {2, 2, 0}},
+			totals:         totals(0, 0, 0),
+			winners:        []uint{optA, optB, optC},
+			explanation: `There is no Condorcet winner, as no option beats every other one head to head. ` +
+				`"A", "B" and "C" tie on strongest paths.`,
+		},
+		{
+			name: "unranked options rank last",
+			ballots: []Ballot{
+				{Ranking: []uint{optB}},
+				{Ranking: []uint{optC, optB}},
+			},
+			preferences:    [][]int{{0, 0, 0}, {2, 0, 1}, {1, 1, 0}},
+			strongestPaths: [][]int{{0, 0, 0}, {2, 0, 0}, {1, 0, 0}},
+			totals:         totals(0, 1, 1),
+			winners:        []uint{optB, optC},
+			explanation: `There is no Condorcet winner, as no option beats every other one head to head. ` +
+				`"B" and "C" tie on strongest paths.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Count(Contest{Method: Schulze, Options: abc}, tt.ballots)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if result.Pairwise == nil {
+				t.Fatal("pairwise matrix missing")
+			}
+			if !slices.Equal(result.Pairwise.Options, []uint{optA, optB, optC}) {
+				t.Errorf("pairwise options = %v", result.Pairwise.Options)
+			}
+			if !reflect.DeepEqual(result.Pairwise.Preferences, tt.preferences) {
+				t.Errorf("preferences = %v, want %v", result.Pairwise.Preferences, tt.preferences)
+			}
+			if !reflect.DeepEqual(result.Pairwise.StrongestPaths, tt.strongestPaths) {
+				t.Errorf("strongest paths = %v, want %v", result.Pairwise.StrongestPaths, tt.strongestPaths)
+			}
+			if !slices.Equal(result.Totals, tt.totals) {
+				t.Errorf("totals = %v, want %v", result.Totals, tt.totals)
+			}
+			if !slices.Equal(result.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", result.Winners, tt.winners)
+			}
+			if result.Explanation != tt.explanation {
+				t.Errorf("explanation = %q, want %q", result.Explanation, tt.explanation)
+			}
+		})
+	}
+}
+
+func TestCountSchulzeNoBallots(t *testing.T) {
+	result, err := Count(Contest{Method: Schulze, Options: abc}, nil)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if result.Winners != nil || result.Explanation != "No ballots were cast." {
+		t.Errorf("winners = %v, explanation = %q", result.Winners, result.Explanation)
+	}
+}
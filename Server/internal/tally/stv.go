@@ -0,0 +1,316 @@
+package tally
+
+import (
+	"fmt"
+	"sort"
+)
+
+// stvScale is the precision of STV vote values. Transferred values are
+// truncated to five decimal places, as in the Scottish STV rules, so the
+// count uses exact integer arithmetic and always reproduces.
+const stvScale = 100000
+
+// STVReport describes an STV count round by round.
+type STVReport struct {
+	Seats int
+	// ValidBallots is the number of ballots ranking at least one option and
+	// ValidVotes their total weight.
+	ValidBallots int
+	ValidVotes   int
+	// Quota is the Droop quota: floor(valid votes / (seats + 1)) + 1.
+	Quota  float64
+	Rounds []STVRound
+}
+
+type STVRound struct {
+	Number int
+	// Votes holds the value of the votes held by every option that was still
+	// in the count at the start of the round. Elected options keep the quota.
+	Votes []OptionVotes
+	// Exhausted is the value of the ballots with no continuing preference left.
+	Exhausted float64
+	// Elected and Eliminated are the options elected or eliminated this round.
+	Elected    []uint
+	Eliminated []uint
+	// Transfers describe the votes moved on to later preferences at the end
+	// of the round.
+	Transfers []STVTransfer
+	Note      string
+}
+
+type OptionVotes struct {
+	OptionID uint
+	Votes    float64
+}
+
+// STVTransfer records votes passed on from one option.
+type STVTransfer struct {
+	From   uint
+	Reason string // "surplus" or "elimination"
+	Votes  float64
+	// Factor is what the value of every transferred ballot is multiplied by:
+	// surplus / votes for a surplus, 1 for an elimination.
+	Factor float64
+}
+
+type stvStatus int
+
+const (
+	stvContinuing stvStatus = iota
+	stvElected
+	stvEliminated
+)
+
+type stvBallot struct {
+	preferences []int // option indexes, most preferred first
+	position    int
+	weight      int64
+	exhausted   bool
+}
+
+// current returns the option the ballot counts for, skipping options that are
+// no longer continuing, or -1 once the ballot is exhausted.
+func (b *stvBallot) current(status []stvStatus) int {
+	for b.position < len(b.preferences) && status[b.preferences[b.position]] != stvContinuing {
+		b.position++
+	}
+	if b.position >= len(b.preferences) {
+		return -1
+	}
+	return b.preferences[b.position]
+}
+
+// countSTV elects seats options with the single transferable vote, using the
+// Droop quota and the weighted inclusive Gregory method: when an option is
+// elected with a surplus, every ballot counting for it moves on to its next
+// preference at a value reduced by surplus / votes.
+func countSTV(options []Option, seats int, ballots []Ballot, result *Result) {
+	n := len(options)
+	if seats < 1 {
+		seats = 1
+	}
+	if seats > n {
+		seats = n
+	}
+
+	index := optionIndex(options)
+	var active []*stvBallot
+	var validVotes int
+	result.Totals = make([]OptionTotal, n)
+	for i, option := range options {
+		result.Totals[i].OptionID = option.ID
+	}
+	for _, ballot := range ballots {
+		b := &stvBallot{weight: int64(ballot.weight()) * stvScale}
+		for _, id := range dedupe(ballot.Ranking) {
+			if i, ok := index[id]; ok {
+				b.preferences = append(b.preferences, i)
+			}
+		}
+		if len(b.preferences) == 0 {
+			continue
+		}
+		result.Totals[b.preferences[0]].Total += ballot.weight()
+		validVotes += ballot.weight()
+		active = append(active, b)
+	}
+
+	quota := int64(validVotes/(seats+1)+1) * stvScale
+	report := &STVReport{Seats: seats, ValidBallots: len(active), ValidVotes: validVotes, Quota: toVotes(quota)}
+	result.STV = report
+
+	status := make([]stvStatus, n)
+	kept := make([]int64, n) // votes kept by elected options
+	var elected []int
+	var history [][]int64
+	var exhausted int64
+
+	for number := 1; len(elected) < seats && len(active) > 0; number++ {
+		round := STVRound{Number: number}
+
+		votes := make([]int64, n)
+		holders := make([][]*stvBallot, n)
+		for _, b := range active {
+			if b.exhausted {
+				continue
+			}
+			i := b.current(status)
+			if i < 0 {
+				b.exhausted = true
+				exhausted += b.weight
+				continue
+			}
+			votes[i] += b.weight
+			holders[i] = append(holders[i], b)
+		}
+		for _, i := range elected {
+			votes[i] = kept[i]
+		}
+		history = append(history, votes)
+
+		for i := range options {
+			if status[i] != stvEliminated {
+				round.Votes = append(round.Votes, OptionVotes{OptionID: options[i].ID, Votes: toVotes(votes[i])})
+			}
+		}
+		round.Exhausted = toVotes(exhausted)
+
+		continuing := continuingOptions(status)
+
+		// Elect everyone who reached the quota, largest vote first
+		var reached []int
+		for _, i := range continuing {
+			if votes[i] >= quota {
+				reached = append(reached, i)
+			}
+		}
+		sort.SliceStable(reached, func(a, b int) bool { return votes[reached[a]] > votes[reached[b]] })
+		if len(reached) > seats-len(elected) {
+			reached = reached[:seats-len(elected)]
+		}
+
+		if len(reached) > 0 {
+			for _, i := range reached {
+				status[i] = stvElected
+				elected = append(elected, i)
+				round.Elected = append(round.Elected, options[i].ID)
+
+				surplus := votes[i] - quota
+				kept[i] = votes[i] - surplus
+				for _, b := range holders[i] {
+					// Ballots that elected an option without a surplus are used up
+					b.weight = b.weight * surplus / votes[i]
+				}
+				if surplus > 0 {
+					round.Transfers = append(round.Transfers, STVTransfer{
+						From:   options[i].ID,
+						Reason: "surplus",
+						Votes:  toVotes(surplus),
+						Factor: float64(surplus) / float64(votes[i]),
+					})
+				}
+			}
+			report.Rounds = append(report.Rounds, round)
+			continue
+		}
+
+		// Fill the remaining seats once only that many options are left
+		if len(continuing) <= seats-len(elected) {
+			sort.SliceStable(continuing, func(a, b int) bool { return votes[continuing[a]] > votes[continuing[b]] })
+			for _, i := range continuing {
+				status[i] = stvElected
+				kept[i] = votes[i]
+				elected = append(elected, i)
+				round.Elected = append(round.Elected, options[i].ID)
+			}
+			round.Note = "The remaining options fill the remaining seats."
+			report.Rounds = append(report.Rounds, round)
+			break
+		}
+
+		loser, note := lowestOption(options, continuing, votes, history)
+		status[loser] = stvEliminated
+		round.Eliminated = []uint{options[loser].ID}
+		round.Note = note
+		if votes[loser] > 0 {
+			round.Transfers = []STVTransfer{{
+				From:   options[loser].ID,
+				Reason: "elimination",
+				Votes:  toVotes(votes[loser]),
+				Factor: 1,
+			}}
+		}
+		report.Rounds = append(report.Rounds, round)
+	}
+
+	for _, i := range elected {
+		result.Winners = append(result.Winners, options[i].ID)
+	}
+	result.Explanation = explainSTV(options, report, result.Winners)
+}
+
+// lowestOption picks the continuing option to eliminate. A tie for the fewest
+// votes is broken by the most recent earlier round in which the tied options
+// had different totals, and failing that by eliminating the option listed
+// last on the ballot.
+func lowestOption(options []Option, continuing []int, votes []int64, history [][]int64) (int, string) {
+	var tied []int
+	for _, i := range continuing {
+		switch {
+		case len(tied) == 0 || votes[i] < votes[tied[0]]:
+			tied = []int{i}
+		case votes[i] == votes[tied[0]]:
+			tied = append(tied, i)
+		}
+	}
+	if len(tied) == 1 {
+		return tied[0], ""
+	}
+
+	names := optionNames(options)
+	tiedIDs := make([]uint, len(tied))
+	for k, i := range tied {
+		tiedIDs[k] = options[i].ID
+	}
+
+	for r := len(history) - 2; r >= 0; r-- {
+		lowest := tied[0]
+		unique := true
+		for _, i := range tied[1:] {
+			switch {
+			case history[r][i] < history[r][lowest]:
+				lowest, unique = i, true
+			case history[r][i] == history[r][lowest]:
+				unique = false
+			}
+		}
+		if unique {
+			return lowest, fmt.Sprintf("%s tied for the fewest votes; %s had the fewest in round %d.",
+				joinNames(names, tiedIDs), names[options[lowest].ID], r+1)
+		}
+	}
+
+	loser := tied[len(tied)-1]
+	return loser, fmt.Sprintf("%s tied for the fewest votes in every round; %s is eliminated as the option listed last.",
+		joinNames(names, tiedIDs), names[options[loser].ID])
+}
+
+func continuingOptions(status []stvStatus) []int {
+	var continuing []int
+	for i, s := range status {
+		if s == stvContinuing {
+			continuing = append(continuing, i)
+		}
+	}
+	return continuing
+}
+
+func explainSTV(options []Option, report *STVReport, winners []uint) string {
+	if len(options) == 0 {
+		return "There are no options to choose from."
+	}
+	if len(winners) == 0 {
+		return "No ballots ranked any option."
+	}
+
+	names := optionNames(options)
+	rounds := "round"
+	if len(report.Rounds) != 1 {
+		rounds = "rounds"
+	}
+	ballots := fmt.Sprintf("%d valid ballots", report.ValidBallots)
+	if report.ValidVotes != report.ValidBallots {
+		ballots += fmt.Sprintf(" carrying %d votes", report.ValidVotes)
+	}
+	return fmt.Sprintf("%s elected to %d of %d seats in %d %s with a quota of %s votes from %s.",
+		joinNames(names, winners), len(winners), report.Seats, len(report.Rounds), rounds,
+		formatVotes(report.Quota), ballots)
+}
+
+func toVotes(value int64) float64 {
+	return float64(value) / stvScale
+}
+
+func formatVotes(votes float64) string {
+	return fmt.Sprintf("%g", votes)
+}
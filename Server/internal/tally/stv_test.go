@@ -0,0 +1,162 @@
+package tally
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCountSTV(t *testing.T) {
+	abcd := append(slices.Clone(abc), Option{ID: optD, Name: "D"})
+
+	tests := []struct {
+		name        string
+		options     []Option
+		seats       int
+		ballots     []Ballot
+		quota       float64
+		totals      []OptionTotal
+		winners     []uint
+		elected     [][]uint
+		eliminated  [][]uint
+		notes       []string
+		explanation string
+	}{
+		{
+			name:    "instant runoff",
+			options: abc,
+			seats:   1,
+			ballots: []Ballot{
+				{Ranking: []uint{optA}, Weight: 4},
+				{Ranking: []uint{optB}, Weight: 3},
+				{Ranking: []uint{optC, optB}, Weight: 2},
+			},
+			quota:       5,
+			totals:      totals(4, 3, 2),
+			winners:     []uint{optB},
+			elected:     [][]uint{nil, {optB}},
+			eliminated:  [][]uint{{optC}, nil},
+			notes:       []string{"", ""},
+			explanation: `"B" elected to 1 of 1 seats in 2 rounds with a quota of 5 votes from 3 valid ballots carrying 9 votes.`,
+		},
+		{
+			name:    "surplus transfer and tie broken by earlier round",
+			options: abcd,
+			seats:   2,
+			ballots: []Ballot{
+				{Ranking: []uint{optA, optB}, Weight: 6},
+				{Ranking: []uint{optC}, Weight: 3},
+				{Ranking: []uint{optD}, Weight: 2},
+			},
+			quota:      4,
+			totals:     totals(6, 0, 3, 2),
+			winners:    []uint{optA, optC},
+			elected:    [][]uint{{optA}, nil, nil, {optC}},
+			eliminated: [][]uint{nil, {optB}, {optD}, nil},
+			notes: []string{
+				"",
+				`"B" and "D" tied for the fewest votes; "B" had the fewest in round 1.`,
+				"",
+				"The remaining options fill the remaining seats.",
+			},
+			explanation: `"A" and "C" elected to 2 of 2 seats in 4 rounds with a quota of 4 votes from 3 valid ballots carrying 11 votes.`,
+		},
+		{
+			name:       "tie in every round eliminates the option listed last",
+			options:    abc[:2],
+			seats:      1,
+			ballots:    []Ballot{{Ranking: []uint{optA}}, {Ranking: []uint{optB}}},
+			quota:      2,
+			totals:     totals(1, 1),
+			winners:    []uint{optA},
+			elected:    [][]uint{nil, {optA}},
+			eliminated: [][]uint{{optB}, nil},
+			notes: []string{
+				`"A" and "B" tied for the fewest votes in every round; "B" is eliminated as the option listed last.`,
+				"The remaining options fill the remaining seats.",
+			},
+			explanation: `"A" elected to 1 of 1 seats in 2 rounds with a quota of 2 votes from 2 valid ballots.`,
+		},
+		{
+			name:        "ballots without known options",
+			options:     abc,
+			seats:       1,
+			ballots:     []Ballot{{Ranking: []uint{99}}},
+			quota:       1,
+			totals:      totals(0, 0, 0),
+			explanation: "No ballots ranked any option.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Count(Contest{Method: STV, Options: tt.options, Seats: tt.seats}, tt.ballots)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			report := result.STV
+			if report == nil {
+				t.Fatal("STV report missing")
+			}
+			if report.Quota != tt.quota {
+				t.Errorf("quota = %v, want %v", report.Quota, tt.quota)
+			}
+			if !slices.Equal(result.Totals, tt.totals) {
+				t.Errorf("totals = %v, want %v", result.Totals, tt.totals)
+			}
+			if !slices.Equal(result.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", result.Winners, tt.winners)
+			}
+			if len(report.Rounds) != len(tt.elected) {
+				t.Fatalf("rounds = %d, want %d", len(report.Rounds), len(tt.elected))
+			}
+			for i, round := range report.Rounds {
+				if !slices.Equal(round.Elected, tt.elected[i]) || !slices.Equal(round.Eliminated, tt.eliminated[i]) {
+					t.Errorf("round %d elected %v eliminated %v, want %v and %v",
+						round.Number, round.Elected, round.Eliminated, tt.elected[i], tt.eliminated[i])
+				}
+				if round.Note != tt.notes[i] {
+					t.Errorf("round %d note = %q, want %q", round.Number, round.Note, tt.notes[i])
+				}
+			}
+			if result.Explanation != tt.explanation {
+				t.Errorf("explanation = %q, want %q", result.Explanation, tt.explanation)
+			}
+		})
+	}
+}
+
+func TestCountSTVSurplusTransfer(t *testing.T) {
+	// A is elected with 6 votes against a quota of 4, so the ballot moves on
+	// to B at a value of 6 * 2/6 = 2
+	ballots := []Ballot{
+		{Ranking: []uint{optA, optB}, Weight: 6},
+		{Ranking: []uint{optC}, Weight: 3},
+		{Ranking: []uint{optD}, Weight: 2},
+	}
+	options := append(slices.Clone(abc), Option{ID: optD, Name: "D"})
+	result, err := Count(Contest{Method: STV, Options: options, Seats: 2}, ballots)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+
+	first := result.STV.Rounds[0]
+	if len(first.Transfers) != 1 {
+		t.Fatalf("transfers = %v, want one surplus", first.Transfers)
+	}
+	transfer := first.Transfers[0]
+	if transfer.From != optA || transfer.Reason != "surplus" || transfer.Votes != 2 || transfer.Factor != 2.0/6 {
+		t.Errorf("transfer = %+v", transfer)
+	}
+
+	second := result.STV.Rounds[1]
+	want := []OptionVotes{{optA, 4}, {optB, 2}, {optC, 3}, {optD, 2}}
+	if !slices.Equal(second.Votes, want) {
+		t.Errorf("round 2 votes = %v, want %v", second.Votes, want)
+	}
+
+	// B is eliminated with no later preference, so A's surplus exhausts
+	third := result.STV.Rounds[2]
+	if third.Exhausted != 2 {
+		t.Errorf("round 3 exhausted = %v, want 2", third.Exhausted)
+	}
+}
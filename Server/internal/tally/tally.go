@@ -0,0 +1,258 @@
+// Package tally counts ballots with the supported voting methods. It does no
+// I/O, so the same ballots always produce the same result and results can be
+// reproduced from exported ballots.
+package tally
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Method string
+
+const (
+	Plurality Method = "plurality"
+	Approval  Method = "approval"
+	Score     Method = "score"
+	Borda     Method = "borda"
+	Schulze   Method = "schulze"
+	STV       Method = "stv"
+)
+
+var ErrUnknownMethod = errors.New("unknown tally method")
+
+// Valid reports whether m is a supported method.
+func (m Method) Valid() bool {
+	switch m {
+	case Plurality, Approval, Score, Borda, Schulze, STV:
+		return true
+	}
+	return false
+}
+
+// Ranked reports whether m counts ranked ballots.
+func (m Method) Ranked() bool {
+	return m == Borda || m == Schulze || m == STV
+}
+
+// Option is a candidate on the ballot. Name is only used in explanations.
+type Option struct {
+	ID   uint
+	Name string
+}
+
+// Contest describes what is being counted.
+type Contest struct {
+	Method  Method
+	Options []Option
+	// Seats is the number of winners to elect with STV. Other methods elect
+	// a single winner and ignore it.
+	Seats int
+}
+
+// Ballot is one voter's answer to a question. Which field is read depends on
+// the method; ballots are expected to have been validated beforehand, and
+// options that are not on the ballot are ignored.
+type Ballot struct {
+	// Choices are the selected options for plurality and approval.
+	Choices []uint
+	// Ranking lists options from most to least preferred for ranked methods.
+	// Options left out rank below every listed option and tie with each other.
+	Ranking []uint
+	// Scores holds the score given to each option for score voting.
+	Scores map[uint]int
+	// Weight is how many votes the ballot counts for. Zero counts as one.
+	Weight int
+}
+
+func (b Ballot) weight() int {
+	if b.Weight < 1 {
+		return 1
+	}
+	return b.Weight
+}
+
+type OptionTotal struct {
+	OptionID uint
+	Total    int
+}
+
+type Result struct {
+	Method Method
+	// Ballots is the number of ballots counted and Weight their total weight,
+	// which equals Ballots unless ballots are weighted.
+	Ballots int
+	Weight  int
+	// Totals has one entry per option, in the order the options were given:
+	// votes for plurality and approval, summed scores for score voting, points
+	// for Borda, the number of options each one beats for Schulze and first
+	// preferences for STV. Votes, scores, points and preferences are
+	// multiplied by the ballot weights.
+	Totals []OptionTotal
+	// Winners holds every option tied for first place, or for STV the
+	// elected options in the order they were elected. It is empty when no
+	// ballots were cast.
+	Winners     []uint
+	Explanation string
+	// Pairwise is only set for Schulze.
+	Pairwise *Pairwise
+	// STV is only set for STV.
+	STV *STVReport
+}
+
+// Count tallies ballots for the contest.
+func Count(contest Contest, ballots []Ballot) (*Result, error) {
+	method, options := contest.Method, contest.Options
+	result := &Result{Method: method, Ballots: len(ballots)}
+	for _, ballot := range ballots {
+		result.Weight += ballot.weight()
+	}
+
+	switch method {
+	case Plurality, Approval, Score, Borda:
+		result.Totals = countTotals(method, options, ballots)
+		result.Winners = topOptions(result.Totals)
+		result.Explanation = explainTotals(method, options, result)
+	case Schulze:
+		countSchulze(options, ballots, result)
+	case STV:
+		countSTV(options, contest.Seats, ballots, result)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMethod, method)
+	}
+
+	if len(ballots) == 0 {
+		result.Winners = nil
+		result.Explanation = "No ballots were cast."
+	}
+	return result, nil
+}
+
+func countTotals(method Method, options []Option, ballots []Ballot) []OptionTotal {
+	index := optionIndex(options)
+	totals := make([]OptionTotal, len(options))
+	for i, option := range options {
+		totals[i].OptionID = option.ID
+	}
+
+	for _, ballot := range ballots {
+		weight := ballot.weight()
+		switch method {
+		case Plurality:
+			// Spoiled ballots with more than one choice do not count
+			if len(ballot.Choices) != 1 {
+				continue
+			}
+			if i, ok := index[ballot.Choices[0]]; ok {
+				totals[i].Total += weight
+			}
+		case Approval:
+			for _, id := range dedupe(ballot.Choices) {
+				if i, ok := index[id]; ok {
+					totals[i].Total += weight
+				}
+			}
+		case Score:
+			for id, score := range ballot.Scores {
+				if i, ok := index[id]; ok && score > 0 {
+					totals[i].Total += score * weight
+				}
+			}
+		case Borda:
+			// The first choice earns one point per other option, the next one
+			// point less and so on. Unranked options earn nothing.
+			position := 0
+			for _, id := range dedupe(ballot.Ranking) {
+				if i, ok := index[id]; ok {
+					totals[i].Total += (len(options) - 1 - position) * weight
+					position++
+				}
+			}
+		}
+	}
+
+	return totals
+}
+
+// topOptions returns the options with the highest total.
+func topOptions(totals []OptionTotal) []uint {
+	var winners []uint
+	best := 0
+	for _, total := range totals {
+		switch {
+		case winners == nil || total.Total > best:
+			winners = []uint{total.OptionID}
+			best = total.Total
+		case total.Total == best:
+			winners = append(winners, total.OptionID)
+		}
+	}
+	return winners
+}
+
+func explainTotals(method Method, options []Option, result *Result) string {
+	unit := map[Method]string{
+		Plurality: "votes",
+		Approval:  "approvals",
+		Score:     "points",
+		Borda:     "Borda points",
+	}[method]
+
+	if len(result.Winners) == 0 {
+		return "There are no options to choose from."
+	}
+
+	var best int
+	for _, total := range result.Totals {
+		if total.OptionID == result.Winners[0] {
+			best = total.Total
+		}
+	}
+
+	names := optionNames(options)
+	if len(result.Winners) > 1 {
+		return fmt.Sprintf("%s tie with %d %s each.", joinNames(names, result.Winners), best, unit)
+	}
+	return fmt.Sprintf("%s wins with %d %s.", names[result.Winners[0]], best, unit)
+}
+
+func optionIndex(options []Option) map[uint]int {
+	index := make(map[uint]int, len(options))
+	for i, option := range options {
+		index[option.ID] = i
+	}
+	return index
+}
+
+func optionNames(options []Option) map[uint]string {
+	names := make(map[uint]string, len(options))
+	for _, option := range options {
+		names[option.ID] = fmt.Sprintf("%q", option.Name)
+	}
+	return names
+}
+
+func joinNames(names map[uint]string, ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = names[id]
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// dedupe keeps the first occurrence of every ID.
+func dedupe(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
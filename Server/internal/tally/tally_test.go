@@ -0,0 +1,192 @@
+package tally
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const (
+	optA uint = iota + 1
+	optB
+	optC
+	optD
+)
+
+var abc = []Option{{ID: optA, Name: "A"}, {ID: optB, Name: "B"}, {ID: optC, Name: "C"}}
+
+func totals(values ...int) []OptionTotal {
+	totals := make([]OptionTotal, len(values))
+	for i, value := range values {
+		totals[i] = OptionTotal{OptionID: uint(i + 1), Total: value}
+	}
+	return totals
+}
+
+func TestCountTotals(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      Method
+		ballots     []Ballot
+		weight      int
+		totals      []OptionTotal
+		winners     []uint
+		explanation string
+	}{
+		{
+			name:        "plurality winner",
+			method:      Plurality,
+			ballots:     []Ballot{{Choices: []uint{optA}}, {Choices: []uint{optA}}, {Choices: []uint{optB}}},
+			weight:      3,
+			totals:      totals(2, 1, 0),
+			winners:     []uint{optA},
+			explanation: `"A" wins with 2 votes.`,
+		},
+		{
+			name:        "plurality tie",
+			method:      Plurality,
+			ballots:     []Ballot{{Choices: []uint{optA}}, {Choices: []uint{optB}}},
+			weight:      2,
+			totals:      totals(1, 1, 0),
+			winners:     []uint{optA, optB},
+			explanation: `"A" and "B" tie with 1 votes each.`,
+		},
+		{
+			name:        "plurality ignores spoiled ballots and unknown options",
+			method:      Plurality,
+			ballots:     []Ballot{{Choices: []uint{optA, optB}}, {Choices: []uint{99}}, {Choices: []uint{optC}}},
+			weight:      3,
+			totals:      totals(0, 0, 1),
+			winners:     []uint{optC},
+			explanation: `"C" wins with 1 votes.`,
+		},
+		{
+			name:        "plurality weights",
+			method:      Plurality,
+			ballots:     []Ballot{{Choices: []uint{optA}, Weight: 3}, {Choices: []uint{optB}}, {Choices: []uint{optB}}},
+			weight:      5,
+			totals:      totals(3, 2, 0),
+			winners:     []uint{optA},
+			explanation: `"A" wins with 3 votes.`,
+		},
+		{
+			name:        "approval",
+			method:      Approval,
+			ballots:     []Ballot{{Choices: []uint{optA, optB}}, {Choices: []uint{optB, optC}}, {Choices: []uint{optB, optB}}},
+			weight:      3,
+			totals:      totals(1, 3, 1),
+			winners:     []uint{optB},
+			explanation: `"B" wins with 3 approvals.`,
+		},
+		{
+			name:        "approval three-way tie",
+			method:      Approval,
+			ballots:     []Ballot{{Choices: []uint{optA, optB, optC}}},
+			weight:      1,
+			totals:      totals(1, 1, 1),
+			winners:     []uint{optA, optB, optC},
+			explanation: `"A", "B" and "C" tie with 1 approvals each.`,
+		},
+		{
+			name:   "score",
+			method: Score,
+			ballots: []Ballot{
+				{Scores: map[uint]int{optA: 5, optB: 3}},
+				{Scores: map[uint]int{optA: 1, optB: 4, optC: -2}},
+			},
+			weight:      2,
+			totals:      totals(6, 7, 0),
+			winners:     []uint{optB},
+			explanation: `"B" wins with 7 points.`,
+		},
+		{
+			name:        "score weights",
+			method:      Score,
+			ballots:     []Ballot{{Scores: map[uint]int{optA: 2}, Weight: 3}, {Scores: map[uint]int{optB: 5}}},
+			weight:      4,
+			totals:      totals(6, 5, 0),
+			winners:     []uint{optA},
+			explanation: `"A" wins with 6 points.`,
+		},
+		{
+			name:   "borda",
+			method: Borda,
+			ballots: []Ballot{
+				{Ranking: []uint{optA, optB, optC}},
+				{Ranking: []uint{optB, optA, optC}},
+				{Ranking: []uint{optB}},
+			},
+			weight:      3,
+			totals:      totals(3, 5, 0),
+			winners:     []uint{optB},
+			explanation: `"B" wins with 5 Borda points.`,
+		},
+		{
+			name:        "borda tie",
+			method:      Borda,
+			ballots:     []Ballot{{Ranking: []uint{optA, optB}}, {Ranking: []uint{optB, optA}}},
+			weight:      2,
+			totals:      totals(3, 3, 0),
+			winners:     []uint{optA, optB},
+			explanation: `"A" and "B" tie with 3 Borda points each.`,
+		},
+		{
+			name:        "no ballots",
+			method:      Approval,
+			totals:      totals(0, 0, 0),
+			explanation: "No ballots were cast.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Count(Contest{Method: tt.method, Options: abc}, tt.ballots)
+			if err != nil {
+				t.Fatalf("Count() error = %v", err)
+			}
+			if result.Ballots != len(tt.ballots) || result.Weight != tt.weight {
+				t.Errorf("ballots = %d weight %d, want %d weight %d", result.Ballots, result.Weight, len(tt.ballots), tt.weight)
+			}
+			if !slices.Equal(result.Totals, tt.totals) {
+				t.Errorf("totals = %v, want %v", result.Totals, tt.totals)
+			}
+			if !slices.Equal(result.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", result.Winners, tt.winners)
+			}
+			if result.Explanation != tt.explanation {
+				t.Errorf("explanation = %q, want %q", result.Explanation, tt.explanation)
+			}
+		})
+	}
+}
+
+func TestCountUnknownMethod(t *testing.T) {
+	_, err := Count(Contest{Method: "condorcet", Options: abc}, nil)
+	if !errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("Count() error = %v, want ErrUnknownMethod", err)
+	}
+}
+
+func TestMethod(t *testing.T) {
+	tests := []struct {
+		method Method
+		valid  bool
+		ranked bool
+	}{
+		{Plurality, true, false},
+		{Approval, true, false},
+		{Score, true, false},
+		{Borda, true, true},
+		{Schulze, true, true},
+		{STV, true, true},
+		{"condorcet", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.method.Valid(); got != tt.valid {
+			t.Errorf("%q.Valid() = %v, want %v", tt.method, got, tt.valid)
+		}
+		if got := tt.method.Ranked(); got != tt.ranked {
+			t.Errorf("%q.Ranked() = %v, want %v", tt.method, got, tt.ranked)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package tally
+
+import "fmt"
+
+// Threshold is the share of the votes an option needs for a motion to pass.
+type Threshold string
+
+const (
+	Majority      Threshold = "majority"
+	TwoThirds     Threshold = "two_thirds"
+	ThreeQuarters Threshold = "three_quarters"
+	Unanimous     Threshold = "unanimous"
+)
+
+// Met reports whether votes out of total meet the threshold. Comparisons are
+// done in integers so that, for example, 2 of 3 votes is exactly two thirds.
+// Nothing passes without votes.
+func (t Threshold) Met(votes, total int) bool {
+	if total <= 0 {
+		return false
+	}
+	switch t {
+	case Majority:
+		return 2*votes > total
+	case TwoThirds:
+		return 3*votes >= 2*total
+	case ThreeQuarters:
+		return 4*votes >= 3*total
+	case Unanimous:
+		return votes == total
+	}
+	return false
+}
+
+// Describe names the threshold for explanations, as in "a two-thirds majority".
+func (t Threshold) Describe() string {
+	switch t {
+	case Majority:
+		return "a simple majority"
+	case TwoThirds:
+		return "a two-thirds majority"
+	case ThreeQuarters:
+		return "a three-quarters majority"
+	case Unanimous:
+		return "unanimity"
+	}
+	return fmt.Sprintf("threshold %q", string(t))
+}
@@ -0,0 +1,49 @@
+package tally
+
+import "testing"
+
+func TestThresholdMet(t *testing.T) {
+	tests := []struct {
+		threshold Threshold
+		votes     int
+		total     int
+		met       bool
+	}{
+		{Majority, 2, 3, true},
+		{Majority, 1, 2, false},
+		{Majority, 51, 100, true},
+		{TwoThirds, 2, 3, true},
+		{TwoThirds, 3, 5, false},
+		{TwoThirds, 66, 100, false},
+		{ThreeQuarters, 3, 4, true},
+		{ThreeQuarters, 2, 3, false},
+		{Unanimous, 3, 3, true},
+		{Unanimous, 2, 3, false},
+		{Majority, 0, 0, false},
+		{Unanimous, 0, 0, false},
+		{"plurality", 3, 3, false},
+	}
+	for _, tt := range tests {
+		if got := tt.threshold.Met(tt.votes, tt.total); got != tt.met {
+			t.Errorf("%q.Met(%d, %d) = %v, want %v", tt.threshold, tt.votes, tt.total, got, tt.met)
+		}
+	}
+}
+
+func TestThresholdDescribe(t *testing.T) {
+	tests := []struct {
+		threshold Threshold
+		want      string
+	}{
+		{Majority, "a simple majority"},
+		{TwoThirds, "a two-thirds majority"},
+		{ThreeQuarters, "a three-quarters majority"},
+		{Unanimous, "unanimity"},
+		{"plurality", `threshold "plurality"`},
+	}
+	for _, tt := range tests {
+		if got := tt.threshold.Describe(); got != tt.want {
+			t.Errorf("%q.Describe() = %q, want %q", tt.threshold, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tally
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// TieBreak is the rule that picks one winner when several options tie for
+// first place.
+type TieBreak string
+
+const (
+	// TieBreakOwner leaves the choice to the form owner.
+	TieBreakOwner TieBreak = "owner"
+	// TieBreakEarliest picks the tied option that reached its final total
+	// first, counting ballots in the order they were received.
+	TieBreakEarliest TieBreak = "earliest"
+	// TieBreakRandom draws an option with a seed committed before voting.
+	TieBreakRandom TieBreak = "random"
+)
+
+// ByArrival breaks a tie between options of a totals-based contest (plurality,
+// approval, score or Borda). Ballots must be in the order they were received.
+// The option whose running total first reached the tied total wins. It
+// reports false when tied options reached it with the same ballot.
+func ByArrival(contest Contest, ballots []Ballot, tied []uint) (uint, bool) {
+	if len(tied) == 0 {
+		return 0, false
+	}
+
+	final := make(map[uint]int, len(tied))
+	for _, total := range countTotals(contest.Method, contest.Options, ballots) {
+		final[total.OptionID] = total.Total
+	}
+
+	running := make(map[uint]int, len(tied))
+	for _, ballot := range ballots {
+		var reached []uint
+		for _, total := range countTotals(contest.Method, contest.Options, []Ballot{ballot}) {
+			if !slices.Contains(tied, total.OptionID) || total.Total == 0 {
+				continue
+			}
+			running[total.OptionID] += total.Total
+			if running[total.OptionID] == final[total.OptionID] {
+				reached = append(reached, total.OptionID)
+			}
+		}
+		switch len(reached) {
+		case 0:
+		case 1:
+			return reached[0], true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// Draw picks one of the tied options of a question with the seed. Every
+// option gets the SHA-256 digest of "<seed>:<question ID>:<option ID>" in hex
+// and the smallest digest wins, so anyone who knows the seed can repeat the
+// draw with a standard sha256 tool.
+func Draw(seed string, questionID uint, tied []uint) uint {
+	var winner uint
+	var best string
+	for _, id := range tied {
+		digest := DrawDigest(seed, questionID, id)
+		if best == "" || digest < best {
+			winner, best = id, digest
+		}
+	}
+	return winner
+}
+
+// DrawDigest is the digest Draw gives an option.
+func DrawDigest(seed string, questionID uint, optionID uint) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", seed, questionID, optionID)))
+	return hex.EncodeToString(sum[:])
+}
+
+// SeedHash is the commitment published for a seed before voting opens: its
+// SHA-256 digest in hex.
+func SeedHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
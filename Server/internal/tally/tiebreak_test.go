@@ -0,0 +1,61 @@
+package tally
+
+import "testing"
+
+func TestByArrival(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  Method
+		ballots []Ballot
+		tied    []uint
+		winner  uint
+		ok      bool
+	}{
+		{
+			name:    "first to reach the tied total wins",
+			method:  Plurality,
+			ballots: []Ballot{{Choices: []uint{optA}}, {Choices: []uint{optB}}, {Choices: []uint{optB}}, {Choices: []uint{optA}}},
+			tied:    []uint{optA, optB},
+			winner:  optB,
+			ok:      true,
+		},
+		{
+			name:    "reached with the same ballot",
+			method:  Approval,
+			ballots: []Ballot{{Choices: []uint{optA, optB}}},
+			tied:    []uint{optA, optB},
+		},
+		{
+			name:   "no tied options",
+			method: Plurality,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, ok := ByArrival(Contest{Method: tt.method, Options: abc}, tt.ballots, tt.tied)
+			if winner != tt.winner || ok != tt.ok {
+				t.Errorf("ByArrival() = %d, %v, want %d, %v", winner, ok, tt.winner, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDraw(t *testing.T) {
+	tied := []uint{optA, optB, optC}
+	winner := Draw("seed", 7, tied)
+	for _, id := range tied {
+		if DrawDigest("seed", 7, id) < DrawDigest("seed", 7, winner) {
+			t.Errorf("Draw() = %d, but option %d has a smaller digest", winner, id)
+		}
+	}
+	if again := Draw("seed", 7, []uint{optC, optB, optA}); again != winner {
+		t.Errorf("Draw() depends on the order of the tied options: %d and %d", winner, again)
+	}
+}
+
+func TestSeedHash(t *testing.T) {
+	const want = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := SeedHash("abc"); got != want {
+		t.Errorf("SeedHash() = %s, want %s", got, want)
+	}
+}
@@ -1,49 +1,45 @@
 package validation
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/luneto10/voting-system/internal/apperr"
 )
 
-type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
-}
-
-func FormatErrors(err error) []ValidationError {
-	var errors []ValidationError
-
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, e := range validationErrors {
-			field := strings.ToLower(e.Field())
-			var message string
-
-			switch e.Tag() {
-			case "required":
-				message = fmt.Sprintf("%s is required", field)
-			case "min":
-				message = fmt.Sprintf("%s must be at least %s characters long", field, e.Param())
-			case "max":
-				message = fmt.Sprintf("%s must not exceed %s characters", field, e.Param())
-			case "gtfield":
-				message = fmt.Sprintf("%s must be after %s", field, e.Param())
-			default:
-				message = fmt.Sprintf("%s is invalid: %s validation failed", field, e.Tag())
-			}
+// FromBindingError converts a request binding failure into a validation
+// error with one entry per invalid field.
+func FromBindingError(err error) *apperr.Error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return apperr.ErrBadRequest.WithMessage("malformed request body: " + err.Error()).Wrap(err)
+	}
 
-			errors = append(errors, ValidationError{
-				Field:   field,
-				Message: message,
-			})
+	fields := make([]apperr.FieldError, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		field := strings.ToLower(e.Field())
+		var message string
+
+		switch e.Tag() {
+		case "required":
+			message = fmt.Sprintf("%s is required", field)
+		case "min":
+			message = fmt.Sprintf("%s must be at least %s characters long", field, e.Param())
+		case "max":
+			message = fmt.Sprintf("%s must not exceed %s characters", field, e.Param())
+		case "gtfield":
+			message = fmt.Sprintf("%s must be after %s", field, e.Param())
+		default:
+			message = fmt.Sprintf("%s is invalid: %s validation failed", field, e.Tag())
 		}
-	} else {
-		errors = append(errors, ValidationError{
-			Field:   "general",
-			Message: err.Error(),
+
+		fields = append(fields, apperr.FieldError{
+			Field:   field,
+			Message: message,
 		})
 	}
 
-	return errors
+	return apperr.ErrValidation.WithFields(fields...).Wrap(err)
 }
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/luneto10/voting-system/config"
+	"github.com/luneto10/voting-system/internal/apperr"
+)
+
+// ErrWeakPassword is returned for passwords that break the password policy.
+var ErrWeakPassword = apperr.New(http.StatusUnprocessableEntity, "weak_password", "password does not meet the password policy")
+
+// PasswordPolicy checks new passwords against the configured rules and an
+// optional list of known breached passwords.
+type PasswordPolicy struct {
+	cfg      config.PasswordPolicyConfig
+	breached map[string]struct{}
+}
+
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		cfg:      cfg,
+		breached: make(map[string]struct{}),
+	}
+
+	if cfg.BreachedHashesFile != "" {
+		if err := policy.loadBreachedHashes(cfg.BreachedHashesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// Validate returns ErrWeakPassword with a field error for every rule the
+// password breaks, or nil if it is acceptable.
+func (p *PasswordPolicy) Validate(password, email string) error {
+	var fields []apperr.FieldError
+	addError := func(message string) {
+		fields = append(fields, apperr.FieldError{Field: "password", Message: message})
+	}
+
+	if len([]rune(password)) < p.cfg.MinLength {
+		addError(fmt.Sprintf("password must be at least %d characters long", p.cfg.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.RequireUpper && !hasUpper {
+		addError("password must contain an uppercase letter")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		addError("password must contain a lowercase letter")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		addError("password must contain a digit")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		addError("password must contain a symbol")
+	}
+
+	if containsEmail(password, email) {
+		addError("password must not contain your email address")
+	}
+
+	if p.isBreached(password) {
+		addError("password has appeared in a data breach, please choose another one")
+	}
+
+	if len(fields) > 0 {
+		return ErrWeakPassword.WithFields(fields...)
+	}
+	return nil
+}
+
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if len(p.breached) == 0 {
+		return false
+	}
+	sum := sha1.Sum([]byte(password))
+	_, found := p.breached[strings.ToUpper(hex.EncodeToString(sum[:]))]
+	return found
+}
+
+func (p *PasswordPolicy) loadBreachedHashes(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening breached password list: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Accept the "HASH:count" format used by published breach corpora
+		hash, _, _ := strings.Cut(line, ":")
+		p.breached[strings.ToUpper(hash)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading breached password list: %v", err)
+	}
+	return nil
+}
+
+// containsEmail reports whether the password contains the email address or
+// its local part, ignoring case. Very short local parts are ignored to avoid
+// rejecting unrelated passwords.
+func containsEmail(password, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	password = strings.ToLower(password)
+	email = strings.ToLower(strings.TrimSpace(email))
+	if strings.Contains(password, email) {
+		return true
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	return len(localPart) >= 4 && strings.Contains(password, localPart)
+}
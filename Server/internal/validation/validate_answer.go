@@ -2,28 +2,195 @@ package validation
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/luneto10/voting-system/api/dto"
 	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
 )
 
-func ValidateAnswer(question *model.Question, answer dto.AnswerSubmission) error {
+// ErrInvalidAnswer is returned for answers that do not fit their question.
+// Field details name the offending question as "questions.<id>".
+var ErrInvalidAnswer = apperr.New(http.StatusUnprocessableEntity, "invalid_answer", "invalid answer")
+
+// ErrInvalidQuestion is returned for questions whose settings do not fit
+// together, such as a ranked tally method on a single choice question.
+var ErrInvalidQuestion = apperr.New(http.StatusUnprocessableEntity, "invalid_question", "invalid question settings")
+
+// ValidateQuestion checks that the tally method of the index-th question of a
+// form suits its type. Single choice questions are always counted by
+// plurality; multiple choice questions can use any other method. STV questions
+// fill between one seat and as many seats as they have options. Tie-break
+// policies need a single winner method, and breaking ties by the earliest
+// votes needs one that adds up votes. Only choice questions take nominations.
+func ValidateQuestion(index int, question *model.Question) error {
+	field := apperr.FieldError{Field: fmt.Sprintf("questions.%d.tally_method", index)}
+
 	switch question.Type {
+	case model.QuestionTypeText:
+		if question.TallyMethod != "" {
+			field.Message = "text questions cannot have a tally method"
+			return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+		}
 	case model.QuestionTypeSingleChoice:
-		if len(answer.OptionIDs) != 1 {
-			return fmt.Errorf("single choice question requires exactly one option")
+		if question.TallyMethod != model.TallyMethodPlurality {
+			field.Message = "single choice questions are counted by plurality"
+			return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
 		}
 	case model.QuestionTypeMultipleChoice:
-		if len(answer.OptionIDs) == 0 {
-			return fmt.Errorf("multiple choice question requires at least one option")
+		switch question.TallyMethod {
+		case model.TallyMethodApproval, model.TallyMethodBorda, model.TallyMethodSchulze:
+		case model.TallyMethodScore:
+			if question.MaxScore < 1 {
+				field.Message = "score questions need a maximum score of at least 1"
+				return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+			}
+		case model.TallyMethodSTV:
+			field.Field = fmt.Sprintf("questions.%d.seats", index)
+			if question.Seats < 1 {
+				field.Message = "STV questions need at least one seat"
+				return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+			}
+			// Nominations can still add options
+			if !question.AcceptNominations && len(question.Options) > 0 && question.Seats > len(question.Options) {
+				field.Message = "STV questions cannot have more seats than options"
+				return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+			}
+		default:
+			field.Message = fmt.Sprintf("tally method %q cannot be used for multiple choice questions", question.TallyMethod)
+			return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
 		}
-	case model.QuestionTypeText:
+	}
+
+	// A share of the votes only means something when every ballot counts
+	// once for each option it chooses
+	if question.PassThreshold != "" &&
+		question.TallyMethod != model.TallyMethodPlurality && question.TallyMethod != model.TallyMethodApproval {
+		field = apperr.FieldError{
+			Field:   fmt.Sprintf("questions.%d.pass_threshold", index),
+			Message: "pass thresholds can only be used with plurality and approval questions",
+		}
+		return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+	}
+
+	if question.TieBreak != "" {
+		field = apperr.FieldError{Field: fmt.Sprintf("questions.%d.tie_break", index)}
+		switch {
+		case question.TallyMethod == "":
+			field.Message = "text questions cannot have a tie-break policy"
+		case question.TallyMethod == model.TallyMethodSTV:
+			field.Message = "STV questions break ties as part of the count"
+		case question.TieBreak == model.TieBreakEarliest && question.TallyMethod == model.TallyMethodSchulze:
+			field.Message = "Schulze questions cannot break ties by the earliest votes"
+		}
+		if field.Message != "" {
+			return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+		}
+	}
+
+	field = apperr.FieldError{Field: fmt.Sprintf("questions.%d.accept_nominations", index)}
+	switch {
+	case question.AcceptNominations && question.Type == model.QuestionTypeText:
+		field.Message = "text questions cannot accept nominations"
+	case !question.AcceptNominations && (question.NominationSupport > 0 || question.ModerateNominations):
+		field.Message = "nomination rules need a question that accepts nominations"
+	}
+	if field.Message != "" {
+		return ErrInvalidQuestion.WithMessage(field.Message).WithFields(field)
+	}
+	return nil
+}
+
+// ValidateAnswer checks that the answer has the ballot shape of the question's
+// tally method and only refers to the question's own options. Each method
+// takes exactly one field: text questions take Text, ranked questions
+// Ranking, score questions Scores and every other choice question OptionIDs.
+func ValidateAnswer(question *model.Question, answer dto.AnswerSubmission) error {
+	if answer.Abstain {
+		if question.Type == model.QuestionTypeText {
+			return InvalidAnswer(question.ID, "only choice questions can be abstained on")
+		}
+		if len(answer.OptionIDs) > 0 || len(answer.Ranking) > 0 || len(answer.Scores) > 0 || answer.Text != "" {
+			return InvalidAnswer(question.ID, "an abstention cannot choose any option")
+		}
+		return nil
+	}
+
+	if question.Type == model.QuestionTypeText {
 		if answer.Text == "" {
-			return fmt.Errorf("text question requires a text answer")
+			return InvalidAnswer(question.ID, "text question requires a text answer")
+		}
+		if len(answer.OptionIDs) > 0 || len(answer.Ranking) > 0 || len(answer.Scores) > 0 {
+			return InvalidAnswer(question.ID, "text question should not have options")
+		}
+		return nil
+	}
+	if answer.Text != "" {
+		return InvalidAnswer(question.ID, "choice question does not take a text answer")
+	}
+
+	switch question.TallyMethod {
+	case model.TallyMethodBorda, model.TallyMethodSchulze, model.TallyMethodSTV:
+		if len(answer.OptionIDs) > 0 || len(answer.Scores) > 0 {
+			return InvalidAnswer(question.ID, "ranked question only takes a ranking")
+		}
+		if len(answer.Ranking) == 0 {
+			return InvalidAnswer(question.ID, "ranked question requires a ranking of at least one option")
+		}
+		return checkOptions(question, answer.Ranking)
+	case model.TallyMethodScore:
+		if len(answer.OptionIDs) > 0 || len(answer.Ranking) > 0 {
+			return InvalidAnswer(question.ID, "score question only takes scores")
 		}
-		if len(answer.OptionIDs) > 0 {
-			return fmt.Errorf("text question should not have options")
+		if len(answer.Scores) == 0 {
+			return InvalidAnswer(question.ID, "score question requires a score for at least one option")
 		}
+		ids := make([]uint, len(answer.Scores))
+		for i, score := range answer.Scores {
+			if score.Score < 0 || score.Score > question.MaxScore {
+				return InvalidAnswer(question.ID, fmt.Sprintf("scores must be between 0 and %d", question.MaxScore))
+			}
+			ids[i] = score.OptionID
+		}
+		return checkOptions(question, ids)
+	}
+
+	if len(answer.Ranking) > 0 || len(answer.Scores) > 0 {
+		return InvalidAnswer(question.ID, "question only takes option_ids")
+	}
+	if question.Type == model.QuestionTypeSingleChoice && len(answer.OptionIDs) != 1 {
+		return InvalidAnswer(question.ID, "single choice question requires exactly one option")
+	}
+	if len(answer.OptionIDs) == 0 {
+		return InvalidAnswer(question.ID, "multiple choice question requires at least one option")
+	}
+	return checkOptions(question, answer.OptionIDs)
+}
+
+// checkOptions rejects options that are repeated or belong to another question.
+func checkOptions(question *model.Question, ids []uint) error {
+	valid := make(map[uint]bool, len(question.Options))
+	for _, option := range question.Options {
+		valid[option.ID] = true
+	}
+
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !valid[id] {
+			return InvalidAnswer(question.ID, fmt.Sprintf("option %d does not belong to this question", id))
+		}
+		if seen[id] {
+			return InvalidAnswer(question.ID, fmt.Sprintf("option %d is listed more than once", id))
+		}
+		seen[id] = true
 	}
 	return nil
 }
+
+// InvalidAnswer reports a problem with the answer to the given question.
+func InvalidAnswer(questionID uint, message string) error {
+	return ErrInvalidAnswer.WithMessage(message).WithFields(apperr.FieldError{
+		Field:   fmt.Sprintf("questions.%d", questionID),
+		Message: message,
+	})
+}
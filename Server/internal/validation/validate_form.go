@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/luneto10/voting-system/api/model"
+	"github.com/luneto10/voting-system/internal/apperr"
+)
+
+// ErrInvalidQuorum is returned for quorum settings that cannot be met.
+var ErrInvalidQuorum = apperr.New(http.StatusUnprocessableEntity, "invalid_quorum", "invalid quorum")
+
+// ErrInvalidNominationPhase is returned for nomination phases that do not fit
+// the form's voting window or questions.
+var ErrInvalidNominationPhase = apperr.New(http.StatusUnprocessableEntity, "invalid_nomination_phase", "invalid nomination phase")
+
+// ValidateQuorum checks the quorum settings of a form.
+func ValidateQuorum(form *model.Form) error {
+	field := apperr.FieldError{Field: "quorum"}
+
+	switch form.QuorumType {
+	case "":
+		if form.Quorum != 0 {
+			field.Message = "a quorum needs a quorum type"
+		}
+	case model.QuorumAbsolute:
+		if form.Quorum < 1 {
+			field.Message = "an absolute quorum must be at least 1"
+		}
+	case model.QuorumPercentage:
+		if form.Quorum < 1 || form.Quorum > 100 {
+			field.Message = "a percentage quorum must be between 1 and 100"
+		}
+	default:
+		field.Field = "quorum_type"
+		field.Message = fmt.Sprintf("unknown quorum type %q", form.QuorumType)
+	}
+
+	if field.Message != "" {
+		return ErrInvalidQuorum.WithMessage(field.Message).WithFields(field)
+	}
+	return nil
+}
+
+// ValidateNominationPhase checks that a form's nomination phase has a start
+// and an end and ends before voting starts, and that forms with questions
+// accepting nominations have one.
+func ValidateNominationPhase(form *model.Form) error {
+	field := apperr.FieldError{Field: "nomination_end_at"}
+
+	accepting := false
+	for _, question := range form.Questions {
+		accepting = accepting || question.AcceptNominations
+	}
+
+	switch {
+	case (form.NominationStartAt == nil) != (form.NominationEndAt == nil):
+		field.Message = "a nomination phase needs both a start and an end"
+	case !form.HasNominationPhase():
+		if accepting {
+			field.Message = "questions that accept nominations need a nomination phase"
+		}
+	case !form.NominationEndAt.After(*form.NominationStartAt):
+		field.Message = "the nomination phase must end after it starts"
+	case !form.StartAt.IsZero() && form.StartAt.Before(*form.NominationEndAt):
+		field.Message = "the nomination phase must end before voting starts"
+	}
+
+	if field.Message != "" {
+		return ErrInvalidNominationPhase.WithMessage(field.Message).WithFields(field)
+	}
+	return nil
+}
@@ -1,34 +1,211 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/luneto10/voting-system/api/router"
 	"github.com/luneto10/voting-system/config"
 	"github.com/luneto10/voting-system/internal/db"
+	"github.com/luneto10/voting-system/internal/health"
+	"github.com/luneto10/voting-system/internal/helper/auth"
 	applog "github.com/luneto10/voting-system/internal/log"
+	"github.com/luneto10/voting-system/internal/pubsub"
+	"github.com/luneto10/voting-system/internal/service"
+	"github.com/luneto10/voting-system/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	// run returns before exiting so its deferred cleanup always happens
+	if err := run(); err != nil {
+		slog.Error("Server exited with an error", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run serves the API until a termination signal arrives and the server has
+// shut down. It returns an error when the server cannot start, fails while
+// serving or does not shut down cleanly.
+func run() error {
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	// Tokens signed with an empty key could be forged by anyone
+	if err := auth.SetSecretKey(cfg.JWT.SecretKey); err != nil {
+		return fmt.Errorf("loading signing keys: %w, set JWT_SECRET_KEY", err)
 	}
 
 	// Initialize logger
 	logger := applog.NewLogger(cfg.Log)
-	logger.Info("Logger initialized")
+	slog.SetDefault(logger)
+	logger.Info("Logger initialized", "level", cfg.Log.Level, "format", cfg.Log.Format)
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("initializing tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	// Initialize database
-	database, err := db.InitializePostgres(cfg.DB)
+	gormDB, err := db.InitializePostgres(cfg.DB)
 	if err != nil {
-		logger.Errorf("Failed to initialize database: %v", err)
-		return
+		return fmt.Errorf("initializing database: %w", err)
 	}
+	database := db.NewPostgresDB(gormDB)
+	defer func() {
+		if err := database.Close(); err != nil {
+			logger.Error("Failed to close database", "error", err)
+			return
+		}
+		logger.Info("Database connection closed")
+	}()
 	logger.Info("Database initialized")
 
+	// Schema changes are applied explicitly with `migrate up`, never on boot
+	migrator, err := db.NewMigrator(gormDB)
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return fmt.Errorf("checking migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database has %d pending migrations, run `go run . migrate up` first", len(pending))
+	}
+
+	checker := health.NewChecker(gormDB, migrator)
+
+	// Live results are announced within this process
+	broker := pubsub.NewMemoryBroker()
+
 	// Initialize router
-	router.Initialize(database)
+	handler, services := router.Initialize(gormDB, cfg, logger, checker, broker)
+	server := &http.Server{
+		Addr:              cfg.Server.Address,
+		Handler:           handler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	// Results streams never finish on their own, so end them when shutdown
+	// starts instead of waiting out the shutdown timeout
+	server.RegisterOnShutdown(func() {
+		broker.Close()
+	})
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Outcomes are stored as voting closes, not when they are first requested
+	if cfg.Outcome.FreezeInterval > 0 {
+		go freezeOutcomes(ctx, services.OutcomeService, cfg.Outcome.FreezeInterval, logger)
+	}
+
+	serverErr := make(chan error, 2)
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = newMetricsServer(cfg)
+		go func() {
+			logger.Info("Serving metrics", "address", cfg.Metrics.Address, "path", cfg.Metrics.Path)
+			serverErr <- metricsServer.ListenAndServe()
+		}()
+	}
+	go func() {
+		logger.Info("Listening", "address", cfg.Server.Address, "tls", cfg.Server.TLSEnabled())
+		if cfg.Server.TLSEnabled() {
+			serverErr <- server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			serverErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("serving: %w", err)
+	case <-ctx.Done():
+		stop()
+	}
+
+	// Fail readiness first so load balancers stop routing new requests here
+	checker.SetShuttingDown()
+	if cfg.Server.ShutdownDelay > 0 {
+		logger.Info("Readiness disabled, waiting before shutdown", "delay", cfg.Server.ShutdownDelay)
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	// Stop accepting connections and let in-flight requests finish
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("graceful shutdown did not complete: %w", err)
+	}
+	// Metrics stay up while requests drain, so the shutdown can be observed
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			metricsServer.Close()
+			return fmt.Errorf("metrics server shutdown did not complete: %w", err)
+		}
+	}
+	logger.Info("Server stopped")
+	return nil
+}
+
+// newMetricsServer serves the Prometheus endpoint on its own listener, so it
+// can be kept off the network the API is exposed on.
+func newMetricsServer(cfg *config.Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, promhttp.Handler())
+	return &http.Server{
+		Addr:              cfg.Metrics.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+	}
+}
+
+// freezeOutcomes stores the outcomes of forms whose voting has closed, every
+// interval until ctx is done.
+func freezeOutcomes(ctx context.Context, outcomes service.OutcomeService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := outcomes.FreezeOutcomes(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Failed to store form outcomes", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }